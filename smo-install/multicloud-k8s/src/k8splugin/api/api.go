@@ -17,9 +17,13 @@ limitations under the License.
 package api
 
 import (
+	"net/http"
+
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/app"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/connection"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/healthcheck"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/metrics"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/rb"
 
 	"github.com/gorilla/mux"
@@ -37,6 +41,13 @@ func NewRouter(defClient rb.DefinitionManager,
 
 	router := mux.NewRouter()
 
+	// Reject mutations while the read-only mode is enabled
+	roHandler := &readOnlyHandler{}
+	roHandler.set(config.GetConfiguration().ReadOnly)
+	router.Use(roHandler.middleware)
+	router.HandleFunc(readOnlyPath, roHandler.getHandler).Methods("GET")
+	router.HandleFunc(readOnlyPath, roHandler.updateHandler).Methods("PUT")
+
 	// Setup Instance handler routes
 	if instClient == nil {
 		instClient = app.NewInstanceClient()
@@ -45,6 +56,7 @@ func NewRouter(defClient rb.DefinitionManager,
 	instRouter := router.PathPrefix("/v1").Subrouter()
 	instRouter.HandleFunc("/instance", instHandler.createHandler).Methods("POST")
 	instRouter.HandleFunc("/instance", instHandler.listHandler).Methods("GET")
+	instRouter.HandleFunc("/instance/status", instHandler.statusSummariesHandler).Methods("POST")
 	// Match rb-names, versions or profiles
 	instRouter.HandleFunc("/instance", instHandler.listHandler).
 		Queries("rb-name", "{rb-name}",
@@ -53,6 +65,11 @@ func NewRouter(defClient rb.DefinitionManager,
 	//Want to get full Data -> add query param: /install/{instID}?full=true
 	instRouter.HandleFunc("/instance/{instID}", instHandler.getHandler).Methods("GET")
 	instRouter.HandleFunc("/instance/{instID}/status", instHandler.statusHandler).Methods("GET")
+	instRouter.HandleFunc("/instance/{instID}/events", instHandler.eventsHandler).Methods("GET")
+	instRouter.HandleFunc("/instance/{instID}/export", instHandler.exportHandler).Methods("GET")
+	instRouter.HandleFunc("/instance/{instID}/resources", instHandler.resourcesHandler).Methods("GET")
+	instRouter.HandleFunc("/instance/{instID}/drift", instHandler.driftHandler).Methods("GET")
+	instRouter.HandleFunc("/instance/{instID}/health", instHandler.healthHandler).Methods("GET")
 	instRouter.HandleFunc("/instance/{instID}/query", instHandler.queryHandler).Methods("GET")
 	instRouter.HandleFunc("/instance/{instID}/query", instHandler.queryHandler).
 		Queries("ApiVersion", "{ApiVersion}",
@@ -75,6 +92,7 @@ func NewRouter(defClient rb.DefinitionManager,
 			"Kind", "{Kind}",
 			"Name", "{Name}",
 			"Labels", "{Labels}").Methods("GET")
+	queryRouter.HandleFunc("/connectivity-info/{connname}/whoami", queryHandler.whoamiHandler).Methods("GET")
 
 	//Setup the broker handler here
 	//Use the base router without any path prefixes
@@ -101,9 +119,13 @@ func NewRouter(defClient rb.DefinitionManager,
 	resRouter := router.PathPrefix("/v1/rb").Subrouter()
 	resRouter.HandleFunc("/definition", defHandler.createHandler).Methods("POST")
 	resRouter.HandleFunc("/definition/{rbname}/{rbversion}/content", defHandler.uploadHandler).Methods("POST")
-	resRouter.HandleFunc("/definition/{rbname}", defHandler.listVersionsHandler).Methods("GET")
-	resRouter.HandleFunc("/definition", defHandler.listAllHandler).Methods("GET")
+	resRouter.HandleFunc("/definition/{rbname}/{rbversion}/content", defHandler.downloadHandler).Methods("GET")
+	resRouter.HandleFunc("/definition/{rbname}/{rbversion}/clone", defHandler.cloneHandler).Methods("POST")
+	resRouter.Handle("/definition/{rbname}", gzipResponse(http.HandlerFunc(defHandler.listVersionsHandler))).Methods("GET")
+	resRouter.HandleFunc("/definition", defHandler.watchHandler).Queries("watch", "true").Methods("GET")
+	resRouter.Handle("/definition", gzipResponse(http.HandlerFunc(defHandler.listAllHandler))).Methods("GET")
 	resRouter.HandleFunc("/definition/{rbname}/{rbversion}", defHandler.getHandler).Methods("GET")
+	resRouter.HandleFunc("/definition/{rbname}/{rbversion}", defHandler.existsHandler).Methods("HEAD")
 	resRouter.HandleFunc("/definition/{rbname}/{rbversion}", defHandler.updateHandler).Methods("PUT")
 	resRouter.HandleFunc("/definition/{rbname}/{rbversion}", defHandler.deleteHandler).Methods("DELETE")
 
@@ -158,5 +180,12 @@ func NewRouter(defClient rb.DefinitionManager,
 	// Add healthcheck path
 	instRouter.HandleFunc("/healthcheck", healthCheckHandler).Methods("GET")
 
+	// Liveness and readiness probes
+	router.HandleFunc("/healthz", livenessHandler).Methods("GET")
+	router.HandleFunc("/readyz", readinessHandler{client: defClient}.readyHandler).Methods("GET")
+
+	// Expose the Prometheus metrics, e.g. the plugin operations
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
 	return router
 }
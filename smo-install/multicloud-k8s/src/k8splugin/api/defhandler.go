@@ -17,14 +17,28 @@
 package api
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
+	"mime"
 	"net/http"
+	"strings"
 
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/rb"
 
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// contentSHA256Header holds the SHA-256 digest of an uploaded or downloaded
+// bundle, expectedSHA256Header the digest a client expects for its upload
+const (
+	contentSHA256Header  = "X-Content-SHA256"
+	expectedSHA256Header = "X-Expected-SHA256"
 )
 
 // Used to store backend implementations objects
@@ -39,7 +53,7 @@ type rbDefinitionHandler struct {
 func (h rbDefinitionHandler) createHandler(w http.ResponseWriter, r *http.Request) {
 	var v rb.Definition
 
-	err := json.NewDecoder(r.Body).Decode(&v)
+	err := decodeNamed(r.Body, &v, jsonNaming(r.Header.Get("Content-Type")))
 	switch {
 	case err == io.EOF:
 		http.Error(w, "Empty body", http.StatusBadRequest)
@@ -48,7 +62,7 @@ func (h rbDefinitionHandler) createHandler(w http.ResponseWriter, r *http.Reques
 		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
-	h.createOrUpdateHandler(v, w, false)
+	h.createOrUpdateHandler(v, w, r, false)
 }
 
 // createOrUpdateHandler handles creation of the definition entry in the database
@@ -59,7 +73,7 @@ func (h rbDefinitionHandler) updateHandler(w http.ResponseWriter, r *http.Reques
 
 	var v rb.Definition
 
-	err := json.NewDecoder(r.Body).Decode(&v)
+	err := decodeNamed(r.Body, &v, jsonNaming(r.Header.Get("Content-Type")))
 	switch {
 	case err == io.EOF:
 		http.Error(w, "Empty body", http.StatusBadRequest)
@@ -82,11 +96,11 @@ func (h rbDefinitionHandler) updateHandler(w http.ResponseWriter, r *http.Reques
 	v.RBVersion = version
 	v.RBName = name
 
-	h.createOrUpdateHandler(v, w, true)
+	h.createOrUpdateHandler(v, w, r, true)
 }
 
 // createOrUpdateHandler handles creation of the definition entry in the database
-func (h rbDefinitionHandler) createOrUpdateHandler(v rb.Definition, w http.ResponseWriter, update bool) {
+func (h rbDefinitionHandler) createOrUpdateHandler(v rb.Definition, w http.ResponseWriter, r *http.Request, update bool) {
 	// Name is required.
 	if v.RBName == "" {
 		http.Error(w, "Missing name in request", http.StatusBadRequest)
@@ -99,13 +113,26 @@ func (h rbDefinitionHandler) createOrUpdateHandler(v rb.Definition, w http.Respo
 		return
 	}
 
+	// Both end up in storage keys and tarball paths
+	for _, field := range []struct{ name, value string }{{"name", v.RBName}, {"version", v.RBVersion}} {
+		if msg := validateDefinitionField(field.name, field.value); msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+	}
+
 	var ret rb.Definition
 	var err error
 	if update {
-		ret, err = h.client.Update(v)
+		// Rejected if the definition changed since the caller read it
+		ret, err = h.client.Update(v, r.Header.Get("If-Match"))
 	} else {
 		ret, err = h.client.Create(v)
 	}
+	if errors.Is(err, rb.ErrPreconditionFailed) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -113,21 +140,43 @@ func (h rbDefinitionHandler) createOrUpdateHandler(v rb.Definition, w http.Respo
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(ret)
+	err = encodeNamed(w, ret, jsonNaming(r.Header.Get("Accept")))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
+// validateDefinitionField checks that the name or version of a definition is
+// a DNS label and returns a message describing the problem otherwise
+func validateDefinitionField(field, value string) string {
+	if strings.TrimSpace(value) != value {
+		return fmt.Sprintf("Invalid %s %q: leading or trailing whitespace", field, value)
+	}
+	if msgs := validation.IsDNS1123Label(value); len(msgs) > 0 {
+		return fmt.Sprintf("Invalid %s %q: %s", field, value, strings.Join(msgs, ";"))
+	}
+	return ""
+}
+
 // uploadHandler handles upload of the bundle tar file into the database
 func (h rbDefinitionHandler) uploadHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	name := vars["rbname"]
 	version := vars["rbversion"]
 
-	inpBytes, err := ioutil.ReadAll(r.Body)
+	body := r.Body
+	maxSize := config.GetConfiguration().MaxUploadSize
+	if maxSize > 0 {
+		body = http.MaxBytesReader(w, body, maxSize)
+	}
+	inpBytes, err := ioutil.ReadAll(body)
 	if err != nil {
+		// The reader stops with an error once the limit is reached
+		if maxSize > 0 && int64(len(inpBytes)) >= maxSize {
+			http.Error(w, fmt.Sprintf("Body larger than %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "Unable to read body", http.StatusBadRequest)
 		return
 	}
@@ -137,13 +186,106 @@ func (h rbDefinitionHandler) uploadHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Reject a bundle altered on its way when the client sent its digest
+	digest := rb.ContentDigest(inpBytes)
+	if expected := r.Header.Get(expectedSHA256Header); expected != "" && !strings.EqualFold(expected, digest) {
+		http.Error(w, fmt.Sprintf("Content SHA-256 %s does not match the expected %s", digest, expected),
+			http.StatusBadRequest)
+		return
+	}
+
+	// Reject corrupt bundles now rather than when they are instantiated
+	if err := rb.ValidateTarGz(inpBytes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	err = h.client.Upload(name, version, inpBytes)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set(contentSHA256Header, digest)
+	w.WriteHeader(http.StatusOK)
+}
+
+// downloadHandler streams the bundle tar file uploaded for a definition. The
+// digest header is the one of the definition read along with the content.
+func (h rbDefinitionHandler) downloadHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["rbname"]
+	version := vars["rbversion"]
+
+	def, content, err := h.client.OpenContent(name, version)
+	if err != nil {
+		http.Error(w, err.Error(), definitionErrorStatus(err))
+		return
+	}
+	defer content.Close()
+
+	// Bundles uploaded before the digests were stored have none
+	if def.ContentSHA256 != "" {
+		w.Header().Set(contentSHA256Header, def.ContentSHA256)
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": name + "-" + version + ".tar.gz"}))
 	w.WriteHeader(http.StatusOK)
+	// The status is already sent, a failure can only be logged
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Error streaming content of definition %s/%s: %s", name, version, err)
+	}
+}
+
+// cloneHandler creates a new version of a bundle definition from an existing
+// one. The body holds the target version, e.g. {"rb-version": "v2"}.
+func (h rbDefinitionHandler) cloneHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["rbname"]
+	version := vars["rbversion"]
+
+	var v rb.Definition
+
+	err := decodeNamed(r.Body, &v, jsonNaming(r.Header.Get("Content-Type")))
+	switch {
+	case err == io.EOF:
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if v.RBName != "" && v.RBName != name {
+		http.Error(w, "RB name mismatch", http.StatusBadRequest)
+		return
+	}
+
+	if v.RBVersion == "" {
+		http.Error(w, "Missing version in request", http.StatusBadRequest)
+		return
+	}
+
+	if msg := validateDefinitionField("version", v.RBVersion); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.client.Clone(name, version, v.RBVersion)
+	if err != nil {
+		http.Error(w, err.Error(), definitionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = encodeNamed(w, ret, jsonNaming(r.Header.Get("Accept")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 }
 
 // listVersionsHandler handles GET (list) operations on the endpoint
@@ -158,16 +300,17 @@ func (h rbDefinitionHandler) listVersionsHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	mediaType := responseMediaType(r)
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(ret)
+	err = encodeMediaType(w, ret, mediaType, jsonNaming(r.Header.Get("Accept")))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-// listVersionsHandler handles GET (list) operations on the endpoint
+// listAllHandler handles GET (list) operations on the endpoint
 // Returns a list of rb.Definitions
 func (h rbDefinitionHandler) listAllHandler(w http.ResponseWriter, r *http.Request) {
 
@@ -177,15 +320,53 @@ func (h rbDefinitionHandler) listAllHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	mediaType := responseMediaType(r)
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(ret)
+	err = encodeMediaType(w, ret, mediaType, jsonNaming(r.Header.Get("Accept")))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
+// watchHandler streams the Definition changes as server-sent events
+// until the client disconnects
+func (h rbDefinitionHandler) watchHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, stop := h.client.Watch()
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	naming := jsonNaming(r.Header.Get("Accept"))
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			var data bytes.Buffer
+			err := encodeNamed(&data, event.Definition, naming)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, bytes.TrimSpace(data.Bytes()))
+			flusher.Flush()
+		}
+	}
+}
+
 // getHandler handles GET operations on a particular ids
 // Returns a rb.Definition
 func (h rbDefinitionHandler) getHandler(w http.ResponseWriter, r *http.Request) {
@@ -195,30 +376,60 @@ func (h rbDefinitionHandler) getHandler(w http.ResponseWriter, r *http.Request)
 
 	ret, err := h.client.Get(name, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), definitionErrorStatus(err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	mediaType := responseMediaType(r)
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("ETag", ret.ETag())
 	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(ret)
+	err = encodeMediaType(w, ret, mediaType, jsonNaming(r.Header.Get("Accept")))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
+// existsHandler handles HEAD operations on a particular bundle definition id,
+// answering whether it exists without returning it
+func (h rbDefinitionHandler) existsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["rbname"]
+	version := vars["rbversion"]
+
+	_, err := h.client.Get(name, version)
+	if err != nil {
+		w.WriteHeader(definitionErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 // deleteHandler handles DELETE operations on a particular bundle definition id
+// A definition still used by instances is only deleted with force=true
 func (h rbDefinitionHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	name := vars["rbname"]
 	version := vars["rbversion"]
 
-	err := h.client.Delete(name, version)
+	err := h.client.Delete(name, version, r.URL.Query().Get("force") == "true")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), definitionErrorStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// definitionErrorStatus returns the HTTP status code reporting an error of
+// the DefinitionManager
+func definitionErrorStatus(err error) int {
+	if errors.Is(err, rb.ErrDefinitionNotFound) || errors.Is(err, rb.ErrDefinitionContentNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, rb.ErrDefinitionExists) || errors.Is(err, rb.ErrDefinitionInUse) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -17,29 +17,57 @@
 package api
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/db"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/rb"
 
+	"github.com/ghodss/yaml"
 	pkgerrors "github.com/pkg/errors"
 )
 
-//Creating an embedded interface via anonymous variable
-//This allows us to make mockDB satisfy the DatabaseConnection
-//interface even if we are not implementing all the methods in it
+// Creating an embedded interface via anonymous variable
+// This allows us to make mockDB satisfy the DatabaseConnection
+// interface even if we are not implementing all the methods in it
 type mockRBDefinition struct {
 	rb.DefinitionManager
 	// Items and err will be used to customize each test
 	// via a localized instantiation of mockRBDefinition
 	Items []rb.Definition
 	Err   error
+	// Content is the last uploaded bundle
+	Content []byte
+	// Instances is the number of instances using the definition
+	Instances int
+	// ContentOpen reports whether a reader of the content is not closed
+	ContentOpen bool
+}
+
+// mockContentReader records when the content read from a mockRBDefinition
+// is closed
+type mockContentReader struct {
+	io.Reader
+	m *mockRBDefinition
+}
+
+func (r mockContentReader) Close() error {
+	r.m.ContentOpen = false
+	return nil
 }
 
 func (m *mockRBDefinition) Create(inp rb.Definition) (rb.Definition, error) {
@@ -66,21 +94,79 @@ func (m *mockRBDefinition) Get(name, version string) (rb.Definition, error) {
 	return m.Items[0], nil
 }
 
-func (m *mockRBDefinition) Delete(name, version string) error {
-	return m.Err
+func (m *mockRBDefinition) Update(inp rb.Definition, ifMatch string) (rb.Definition, error) {
+	if m.Err != nil {
+		return rb.Definition{}, m.Err
+	}
+	if ifMatch != "" && !rb.ETagMatches(ifMatch, m.Items[0].ETag()) {
+		return rb.Definition{}, rb.ErrPreconditionFailed
+	}
+
+	return inp, nil
+}
+
+func (m *mockRBDefinition) Delete(name, version string, force bool) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	if !force && m.Instances > 0 {
+		return pkgerrors.Wrapf(rb.ErrDefinitionInUse, "Definition %s/%s is used by %d instances", name, version, m.Instances)
+	}
+	return nil
 }
 
 func (m *mockRBDefinition) Upload(name, version string, inp []byte) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	m.Content = inp
+	return nil
+}
+
+func (m *mockRBDefinition) OpenContent(name, version string) (rb.Definition, io.ReadCloser, error) {
+	if m.Err != nil {
+		return rb.Definition{}, nil, m.Err
+	}
+	if m.Content == nil {
+		return rb.Definition{}, nil, rb.ErrDefinitionContentNotFound
+	}
+
+	def := rb.Definition{RBName: name, RBVersion: version}
+	if len(m.Items) > 0 {
+		def = m.Items[0]
+	}
+	m.ContentOpen = true
+	return def, mockContentReader{Reader: bytes.NewReader(m.Content), m: m}, nil
+}
+
+func (m *mockRBDefinition) Clone(name, version, targetVersion string) (rb.Definition, error) {
+	if m.Err != nil {
+		return rb.Definition{}, m.Err
+	}
+
+	clone := m.Items[0]
+	clone.RBVersion = targetVersion
+	m.Items = append(m.Items, clone)
+	return clone, nil
+}
+
+func (m *mockRBDefinition) Ping() error {
 	return m.Err
 }
 
+func (m *mockRBDefinition) CountInstances(name, version string) (int, error) {
+	return m.Instances, nil
+}
+
 func TestRBDefCreateHandler(t *testing.T) {
 	testCases := []struct {
-		label        string
-		reader       io.Reader
-		expected     rb.Definition
-		expectedCode int
-		rbDefClient  *mockRBDefinition
+		label         string
+		reader        io.Reader
+		expected      rb.Definition
+		expectedCode  int
+		expectedError string
+		rbDefClient   *mockRBDefinition
 	}{
 		{
 			label:        "Missing Body Failure",
@@ -124,6 +210,28 @@ func TestRBDefCreateHandler(t *testing.T) {
 			expectedCode: http.StatusBadRequest,
 			rbDefClient:  &mockRBDefinition{},
 		},
+		{
+			label: "Invalid Name in Request Body",
+			reader: bytes.NewBuffer([]byte(`{
+				"rb-name":"test/resourcebundle",
+				"rb-version":"v1",
+				"chart-name":"testchart"
+				}`)),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Invalid name",
+			rbDefClient:   &mockRBDefinition{},
+		},
+		{
+			label: "Version with Whitespace in Request Body",
+			reader: bytes.NewBuffer([]byte(`{
+				"rb-name":"testresourcebundle",
+				"rb-version":" v1",
+				"chart-name":"testchart"
+				}`)),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Invalid version",
+			rbDefClient:   &mockRBDefinition{},
+		},
 		{
 			label: "Missing Version in Request Body",
 			reader: bytes.NewBuffer([]byte(`{
@@ -146,6 +254,13 @@ func TestRBDefCreateHandler(t *testing.T) {
 				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
 			}
 
+			if testCase.expectedError != "" {
+				body, _ := ioutil.ReadAll(resp.Body)
+				if !strings.Contains(string(body), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q; Got: %s", testCase.expectedError, body)
+				}
+			}
+
 			//Check returned body only if statusCreated
 			if resp.StatusCode == http.StatusCreated {
 				got := rb.Definition{}
@@ -160,6 +275,62 @@ func TestRBDefCreateHandler(t *testing.T) {
 	}
 }
 
+func TestRBDefUpdateHandlerIfMatch(t *testing.T) {
+	stored := rb.Definition{
+		RBName:      "testresourcebundle",
+		RBVersion:   "v1",
+		ChartName:   "testchart",
+		Description: "test description",
+	}
+	modified := stored
+	modified.Description = "modified by another client"
+
+	testCases := []struct {
+		label        string
+		ifMatch      string
+		expectedCode int
+	}{
+		{
+			label:        "Update Without If-Match",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			label:        "Update With Matching If-Match",
+			ifMatch:      stored.ETag(),
+			expectedCode: http.StatusCreated,
+		},
+		{
+			label:        "Update With Stale If-Match",
+			ifMatch:      modified.ETag(),
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			rbDefClient := &mockRBDefinition{Items: []rb.Definition{stored}}
+			router := NewRouter(rbDefClient, nil, nil, nil, nil, nil, nil, nil)
+
+			// The ETag of the definition is the one returned by GET
+			request := httptest.NewRequest("GET", "/v1/rb/definition/testresourcebundle/v1", nil)
+			resp := executeRequest(request, router)
+			if etag := resp.Header.Get("ETag"); etag != stored.ETag() {
+				t.Fatalf("Expected ETag %s; Got: %s", stored.ETag(), etag)
+			}
+
+			request = httptest.NewRequest("PUT", "/v1/rb/definition/testresourcebundle/v1",
+				bytes.NewBufferString(`{"chart-name":"testchart","description":"new description"}`))
+			if testCase.ifMatch != "" {
+				request.Header.Set("If-Match", testCase.ifMatch)
+			}
+			resp = executeRequest(request, router)
+			if resp.StatusCode != testCase.expectedCode {
+				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
 func TestRBDefListVersionsHandler(t *testing.T) {
 
 	testCases := []struct {
@@ -354,11 +525,21 @@ func TestRBDefGetHandler(t *testing.T) {
 		},
 		{
 			label:        "Get Non-Exiting Bundle Definition",
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusNotFound,
 			name:         "nonexistingbundle",
 			version:      "v1",
 			rbDefClient: &mockRBDefinition{
 				// list of definitions that will be returned by the mockclient
+				Items: []rb.Definition{},
+				Err:   pkgerrors.Wrap(rb.ErrDefinitionNotFound, "Error getting Resource Bundle Definition"),
+			},
+		},
+		{
+			label:        "Get Bundle Definition Backend Failure",
+			expectedCode: http.StatusInternalServerError,
+			name:         "testresourcebundle",
+			version:      "v1",
+			rbDefClient: &mockRBDefinition{
 				Items: []rb.Definition{},
 				Err:   pkgerrors.New("Internal Error"),
 			},
@@ -389,14 +570,69 @@ func TestRBDefGetHandler(t *testing.T) {
 	}
 }
 
-func TestRBDefDeleteHandler(t *testing.T) {
+func TestRBDefExistsHandler(t *testing.T) {
 
 	testCases := []struct {
 		label        string
-		name         string
-		version      string
 		expectedCode int
 		rbDefClient  *mockRBDefinition
+	}{
+		{
+			label:        "Existing Bundle Definition",
+			expectedCode: http.StatusOK,
+			rbDefClient: &mockRBDefinition{
+				Items: []rb.Definition{
+					{
+						RBName:    "testresourcebundle",
+						RBVersion: "v1",
+					},
+				},
+			},
+		},
+		{
+			label:        "Non-Existing Bundle Definition",
+			expectedCode: http.StatusNotFound,
+			rbDefClient: &mockRBDefinition{
+				Err: pkgerrors.Wrap(rb.ErrDefinitionNotFound, "Error getting Resource Bundle Definition"),
+			},
+		},
+		{
+			label:        "Backend Failure",
+			expectedCode: http.StatusInternalServerError,
+			rbDefClient: &mockRBDefinition{
+				Err: pkgerrors.New("Internal Error"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			request := httptest.NewRequest("HEAD", "/v1/rb/definition/testresourcebundle/v1", nil)
+			resp := executeRequest(request, NewRouter(testCase.rbDefClient, nil, nil, nil, nil, nil, nil, nil))
+
+			//Check returned code
+			if resp.StatusCode != testCase.expectedCode {
+				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
+			}
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			if len(body) != 0 {
+				t.Fatalf("Expected an empty body; Got: %s", body)
+			}
+		})
+	}
+}
+
+func TestRBDefDeleteHandler(t *testing.T) {
+
+	testCases := []struct {
+		label         string
+		name          string
+		version       string
+		query         string
+		expectedCode  int
+		expectedError string
+		rbDefClient   *mockRBDefinition
 	}{
 		{
 			label:        "Delete Bundle Definition",
@@ -405,8 +641,33 @@ func TestRBDefDeleteHandler(t *testing.T) {
 			version:      "v1",
 			rbDefClient:  &mockRBDefinition{},
 		},
+		{
+			label:         "Delete Bundle Definition Used By Instances",
+			expectedCode:  http.StatusConflict,
+			expectedError: "used by 2 instances",
+			name:          "test-rbdef",
+			version:       "v1",
+			rbDefClient:   &mockRBDefinition{Instances: 2},
+		},
+		{
+			label:        "Force Delete Bundle Definition Used By Instances",
+			expectedCode: http.StatusNoContent,
+			name:         "test-rbdef",
+			version:      "v1",
+			query:        "?force=true",
+			rbDefClient:  &mockRBDefinition{Instances: 2},
+		},
 		{
 			label:        "Delete Non-Exiting Bundle Definition",
+			expectedCode: http.StatusNotFound,
+			name:         "test-rbdef",
+			version:      "v2",
+			rbDefClient: &mockRBDefinition{
+				Err: pkgerrors.Wrap(rb.ErrDefinitionNotFound, "Delete Resource Bundle Definition"),
+			},
+		},
+		{
+			label:        "Delete Bundle Definition Backend Failure",
 			expectedCode: http.StatusInternalServerError,
 			name:         "test-rbdef",
 			version:      "v2",
@@ -418,51 +679,103 @@ func TestRBDefDeleteHandler(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.label, func(t *testing.T) {
-			request := httptest.NewRequest("DELETE", "/v1/rb/definition/"+testCase.name+"/"+testCase.version, nil)
+			request := httptest.NewRequest("DELETE", "/v1/rb/definition/"+testCase.name+"/"+testCase.version+testCase.query, nil)
 			resp := executeRequest(request, NewRouter(testCase.rbDefClient, nil, nil, nil, nil, nil, nil, nil))
 
 			//Check returned code
 			if resp.StatusCode != testCase.expectedCode {
 				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
 			}
+			if testCase.expectedError != "" {
+				body, _ := ioutil.ReadAll(resp.Body)
+				if !strings.Contains(string(body), testCase.expectedError) {
+					t.Fatalf("Expected an error containing '%s'; Got: %s", testCase.expectedError, body)
+				}
+			}
 		})
 	}
 }
 
+// tarGz returns a gzip compressed tar archive of empty files with the given names
+func tarGz(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, name := range names {
+		err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644})
+		if err != nil {
+			t.Fatalf("Unable to write tar header (%s)", err)
+		}
+	}
+	tw.Close()
+	gzw.Close()
+	return buf.Bytes()
+}
+
 func TestRBDefUploadHandler(t *testing.T) {
+	bundle := tarGz(t, "testchart/Chart.yaml")
 
 	testCases := []struct {
-		label        string
-		name         string
-		version      string
-		body         io.Reader
-		expectedCode int
-		rbDefClient  *mockRBDefinition
+		label          string
+		name           string
+		version        string
+		body           io.Reader
+		expectedSHA256 string
+		expectedCode   int
+		rbDefClient    *mockRBDefinition
 	}{
+		{
+			label:          "Upload Content Matching The Expected Digest",
+			expectedCode:   http.StatusOK,
+			name:           "test-rbdef",
+			version:        "v2",
+			body:           bytes.NewBuffer(bundle),
+			expectedSHA256: strings.ToUpper(rb.ContentDigest(bundle)),
+			rbDefClient:    &mockRBDefinition{},
+		},
+		{
+			label:          "Upload Content Not Matching The Expected Digest",
+			expectedCode:   http.StatusBadRequest,
+			name:           "test-rbdef",
+			version:        "v2",
+			body:           bytes.NewBuffer(bundle),
+			expectedSHA256: rb.ContentDigest([]byte("another bundle")),
+			rbDefClient:    &mockRBDefinition{},
+		},
 		{
 			label:        "Upload Bundle Definition Content",
 			expectedCode: http.StatusOK,
 			name:         "test-rbdef",
 			version:      "v2",
-			body: bytes.NewBuffer([]byte{
-				0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0xff, 0xf2, 0x48, 0xcd,
-			}),
-			rbDefClient: &mockRBDefinition{},
+			body:         bytes.NewBuffer(tarGz(t, "testchart/Chart.yaml")),
+			rbDefClient:  &mockRBDefinition{},
 		},
 		{
 			label:        "Upload Invalid Bundle Definition Content",
 			expectedCode: http.StatusInternalServerError,
 			name:         "test-rbdef",
 			version:      "v2",
-			body: bytes.NewBuffer([]byte{
-				0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0xff, 0xf2, 0x48, 0xcd,
-			}),
+			body:         bytes.NewBuffer(tarGz(t, "testchart/Chart.yaml")),
 			rbDefClient: &mockRBDefinition{
 				Err: pkgerrors.New("Internal Error"),
 			},
 		},
+		{
+			label:        "Upload Plain Text Content",
+			expectedCode: http.StatusBadRequest,
+			name:         "test-rbdef",
+			version:      "v2",
+			body:         bytes.NewBufferString("this is not a tarball"),
+			rbDefClient:  &mockRBDefinition{},
+		},
+		{
+			label:        "Upload Content With Path Traversal",
+			expectedCode: http.StatusBadRequest,
+			name:         "test-rbdef",
+			version:      "v2",
+			body:         bytes.NewBuffer(tarGz(t, "testchart/Chart.yaml", "../evil.yaml")),
+			rbDefClient:  &mockRBDefinition{},
+		},
 		{
 			label:        "Upload Empty Body Content",
 			expectedCode: http.StatusBadRequest,
@@ -470,18 +783,459 @@ func TestRBDefUploadHandler(t *testing.T) {
 			version:      "v2",
 			rbDefClient:  &mockRBDefinition{},
 		},
+		{
+			label:        "Upload Body Over The Size Limit",
+			expectedCode: http.StatusRequestEntityTooLarge,
+			name:         "test-rbdef",
+			version:      "v2",
+			body:         bytes.NewBuffer(make([]byte, 2048)),
+			rbDefClient:  &mockRBDefinition{},
+		},
 	}
 
+	oldMaxUploadSize := config.GetConfiguration().MaxUploadSize
+	config.GetConfiguration().MaxUploadSize = 1024
+	defer func() {
+		config.GetConfiguration().MaxUploadSize = oldMaxUploadSize
+	}()
+
 	for _, testCase := range testCases {
 		t.Run(testCase.label, func(t *testing.T) {
 			request := httptest.NewRequest("POST",
 				"/v1/rb/definition/"+testCase.name+"/"+testCase.version+"/content", testCase.body)
+			if testCase.expectedSHA256 != "" {
+				request.Header.Set("X-Expected-SHA256", testCase.expectedSHA256)
+			}
 			resp := executeRequest(request, NewRouter(testCase.rbDefClient, nil, nil, nil, nil, nil, nil, nil))
 
 			//Check returned code
 			if resp.StatusCode != testCase.expectedCode {
 				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
 			}
+
+			if resp.StatusCode != http.StatusOK {
+				if testCase.rbDefClient.Content != nil {
+					t.Fatalf("Expected the rejected content not to be stored")
+				}
+				return
+			}
+			expected := rb.ContentDigest(testCase.rbDefClient.Content)
+			if digest := resp.Header.Get("X-Content-SHA256"); digest != expected {
+				t.Fatalf("Expected X-Content-SHA256 %s; Got: %s", expected, digest)
+			}
+		})
+	}
+}
+
+func TestRBDefDownloadHandler(t *testing.T) {
+	content := tarGz(t, "testchart/Chart.yaml", "testchart/values.yaml")
+	client := &mockRBDefinition{
+		Items: []rb.Definition{
+			{
+				RBName:        "test-rbdef",
+				RBVersion:     "v1",
+				ContentSHA256: rb.ContentDigest(content),
+			},
+		},
+	}
+	router := NewRouter(client, nil, nil, nil, nil, nil, nil, nil)
+	url := "/v1/rb/definition/test-rbdef/v1/content"
+
+	// Nothing was uploaded yet
+	resp := executeRequest(httptest.NewRequest("GET", url, nil), router)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected %d; Got: %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp = executeRequest(httptest.NewRequest("POST", url, bytes.NewBuffer(content)), router)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Upload expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = executeRequest(httptest.NewRequest("GET", url, nil), router)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/gzip" {
+		t.Fatalf("Expected Content-Type application/gzip; Got: %s", contentType)
+	}
+	expectedDisposition := `attachment; filename=test-rbdef-v1.tar.gz`
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != expectedDisposition {
+		t.Fatalf("Expected Content-Disposition %s; Got: %s", expectedDisposition, disposition)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Unable to read the response body (%s)", err)
+	}
+	if !bytes.Equal(body, content) {
+		t.Fatalf("Expected the uploaded bundle to be downloaded, got %d bytes instead of %d", len(body), len(content))
+	}
+	if digest := resp.Header.Get("X-Content-SHA256"); digest != rb.ContentDigest(content) {
+		t.Fatalf("Expected X-Content-SHA256 %s; Got: %s", rb.ContentDigest(content), digest)
+	}
+	if client.ContentOpen {
+		t.Fatalf("Expected the content to be closed once it is sent")
+	}
+}
+
+func TestRBDefCloneHandler(t *testing.T) {
+	testCases := []struct {
+		label        string
+		body         io.Reader
+		expectedCode int
+		rbDefClient  *mockRBDefinition
+	}{
+		{
+			label:        "Clone Bundle Definition",
+			body:         bytes.NewBufferString(`{"rb-version":"v2"}`),
+			expectedCode: http.StatusCreated,
+			rbDefClient: &mockRBDefinition{
+				Items: []rb.Definition{{RBName: "test-rbdef", RBVersion: "v1", ChartName: "testchart"}},
+			},
+		},
+		{
+			label:        "Clone To An Existing Version",
+			body:         bytes.NewBufferString(`{"rb-version":"v2"}`),
+			expectedCode: http.StatusConflict,
+			rbDefClient: &mockRBDefinition{
+				Err: pkgerrors.Wrap(rb.ErrDefinitionExists, "Clone Resource Bundle Definition"),
+			},
+		},
+		{
+			label:        "Clone A Missing Definition",
+			body:         bytes.NewBufferString(`{"rb-version":"v2"}`),
+			expectedCode: http.StatusNotFound,
+			rbDefClient: &mockRBDefinition{
+				Err: pkgerrors.Wrap(rb.ErrDefinitionNotFound, "Clone Resource Bundle Definition"),
+			},
+		},
+		{
+			label:        "Clone Without Target Version",
+			body:         bytes.NewBufferString(`{}`),
+			expectedCode: http.StatusBadRequest,
+			rbDefClient:  &mockRBDefinition{},
+		},
+		{
+			label:        "Clone To An Invalid Version",
+			body:         bytes.NewBufferString(`{"rb-version":"V 2"}`),
+			expectedCode: http.StatusBadRequest,
+			rbDefClient:  &mockRBDefinition{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/v1/rb/definition/test-rbdef/v1/clone", testCase.body)
+			resp := executeRequest(request, NewRouter(testCase.rbDefClient, nil, nil, nil, nil, nil, nil, nil))
+
+			if resp.StatusCode != testCase.expectedCode {
+				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
+			}
+			if resp.StatusCode != http.StatusCreated {
+				return
+			}
+
+			got := rb.Definition{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			expected := rb.Definition{RBName: "test-rbdef", RBVersion: "v2", ChartName: "testchart"}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("cloneHandler returned unexpected body: got %v; expected %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestRBDefJSONNaming(t *testing.T) {
+	testCases := []struct {
+		naming       string
+		body         string
+		expectedKeys []string
+	}{
+		{
+			naming:       "kebab-case",
+			body:         `{"rb-name":"testresourcebundle","rb-version":"v1"}`,
+			expectedKeys: []string{"chart-name", "description", "labels", "rb-name", "rb-version", "schema-version"},
+		},
+		{
+			naming:       "camelCase",
+			body:         `{"rbName":"testresourcebundle","rbVersion":"v1"}`,
+			expectedKeys: []string{"chartName", "description", "labels", "rbName", "rbVersion", "schemaVersion"},
+		},
+		{
+			naming:       "snake_case",
+			body:         `{"rb_name":"testresourcebundle","rb_version":"v1"}`,
+			expectedKeys: []string{"chart_name", "description", "labels", "rb_name", "rb_version", "schema_version"},
+		},
+	}
+
+	rbDefClient := &mockRBDefinition{
+		Items: []rb.Definition{
+			{
+				RBName:      "testresourcebundle",
+				RBVersion:   "v1",
+				ChartName:   "testchart",
+				Description: "test description",
+				Labels:      map[string]string{"app-name": "test"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.naming, func(t *testing.T) {
+			mediaType := "application/json; naming=" + testCase.naming
+
+			// Name and version are only found if the body is decoded
+			// with the requested naming
+			request := httptest.NewRequest("POST", "/v1/rb/definition", bytes.NewBufferString(testCase.body))
+			request.Header.Set("Content-Type", mediaType)
+			resp := executeRequest(request, NewRouter(rbDefClient, nil, nil, nil, nil, nil, nil, nil))
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("Expected %d; Got: %d", http.StatusCreated, resp.StatusCode)
+			}
+
+			request = httptest.NewRequest("GET", "/v1/rb/definition/testresourcebundle/v1", nil)
+			request.Header.Set("Accept", mediaType)
+			resp = executeRequest(request, NewRouter(rbDefClient, nil, nil, nil, nil, nil, nil, nil))
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+			}
+
+			got := map[string]interface{}{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			var keys []string
+			for key := range got {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, testCase.expectedKeys) {
+				t.Fatalf("getHandler returned keys %v, expected %v", keys, testCase.expectedKeys)
+			}
+
+			labels, _ := got["labels"].(map[string]interface{})
+			if labels["app-name"] != "test" {
+				t.Fatalf("getHandler modified the label keys: %v", got["labels"])
+			}
 		})
 	}
 }
+
+func TestRBDefListGzipCompression(t *testing.T) {
+	items := make([]rb.Definition, 0, 100)
+	for i := 0; i < 100; i++ {
+		items = append(items, rb.Definition{
+			RBName:      fmt.Sprintf("testresourcebundle%d", i),
+			RBVersion:   "v1",
+			ChartName:   "testchart",
+			Description: "test description",
+		})
+	}
+
+	testCases := []struct {
+		label          string
+		items          []rb.Definition
+		acceptEncoding string
+		compressed     bool
+	}{
+		{
+			label:          "Compress a large list",
+			items:          items,
+			acceptEncoding: "deflate, gzip;q=0.8",
+			compressed:     true,
+		},
+		{
+			label:          "Leave a small list uncompressed",
+			items:          items[:1],
+			acceptEncoding: "gzip",
+		},
+		{
+			label:          "Leave the list uncompressed without gzip support",
+			items:          items,
+			acceptEncoding: "gzip;q=0, deflate",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			router := NewRouter(&mockRBDefinition{Items: testCase.items}, nil, nil, nil, nil, nil, nil, nil)
+
+			resp := executeRequest(httptest.NewRequest("GET", "/v1/rb/definition", nil), router)
+			expected, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Unable to read the uncompressed body (%s)", err)
+			}
+
+			request := httptest.NewRequest("GET", "/v1/rb/definition", nil)
+			request.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+			resp = executeRequest(request, router)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+			}
+			if resp.Header.Get("Content-Type") != "application/json" {
+				t.Fatalf("Expected Content-Type application/json; Got: %s", resp.Header.Get("Content-Type"))
+			}
+
+			body := resp.Body
+			if testCase.compressed {
+				if resp.Header.Get("Content-Encoding") != "gzip" {
+					t.Fatalf("Expected Content-Encoding gzip; Got: %q", resp.Header.Get("Content-Encoding"))
+				}
+				body, err = gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("Unable to read the compressed body (%s)", err)
+				}
+			} else if resp.Header.Get("Content-Encoding") != "" {
+				t.Fatalf("Expected no Content-Encoding; Got: %s", resp.Header.Get("Content-Encoding"))
+			}
+			got, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatalf("Unable to read the body (%s)", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Fatalf("Expected the body %s; Got: %s", expected, got)
+			}
+		})
+	}
+}
+
+func TestRBDefYAMLRepresentation(t *testing.T) {
+	item := rb.Definition{
+		RBName:      "testresourcebundle",
+		RBVersion:   "v1",
+		ChartName:   "testchart",
+		Description: "test description",
+		Labels: map[string]string{
+			"vnf_customization_uuid": "uuid",
+		},
+	}
+	router := NewRouter(&mockRBDefinition{Items: []rb.Definition{item}}, nil, nil, nil, nil, nil, nil, nil)
+
+	testCases := []struct {
+		label string
+		url   string
+		list  bool
+	}{
+		{
+			label: "Get Bundle Definition",
+			url:   "/v1/rb/definition/testresourcebundle/v1",
+		},
+		{
+			label: "List Bundle Definition Versions",
+			url:   "/v1/rb/definition/testresourcebundle",
+			list:  true,
+		},
+		{
+			label: "List All Bundle Definitions",
+			url:   "/v1/rb/definition",
+			list:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			// decode reads the body of a response in the given representation
+			decode := func(resp *http.Response, expectedType string, unmarshal func([]byte, interface{}) error) []rb.Definition {
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+				}
+				if contentType := resp.Header.Get("Content-Type"); contentType != expectedType {
+					t.Fatalf("Expected Content-Type %s; Got: %s", expectedType, contentType)
+				}
+				body, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("Unable to read the response body (%s)", err)
+				}
+				if !testCase.list {
+					got := rb.Definition{}
+					if err = unmarshal(body, &got); err != nil {
+						t.Fatalf("Unable to decode %s body (%s): %s", expectedType, err, body)
+					}
+					return []rb.Definition{got}
+				}
+				got := []rb.Definition{}
+				if err = unmarshal(body, &got); err != nil {
+					t.Fatalf("Unable to decode %s body (%s): %s", expectedType, err, body)
+				}
+				return got
+			}
+
+			resp := executeRequest(httptest.NewRequest("GET", testCase.url, nil), router)
+			fromJSON := decode(resp, "application/json", json.Unmarshal)
+
+			request := httptest.NewRequest("GET", testCase.url, nil)
+			request.Header.Set("Accept", "application/yaml")
+			resp = executeRequest(request, router)
+			fromYAML := decode(resp, "application/yaml", yaml.Unmarshal)
+
+			resp = executeRequest(httptest.NewRequest("GET", testCase.url+"?format=yaml", nil), router)
+			fromQuery := decode(resp, "application/yaml", yaml.Unmarshal)
+
+			expected := []rb.Definition{item}
+			if !reflect.DeepEqual(fromJSON, expected) {
+				t.Errorf("JSON representation decoded to %v; expected %v", fromJSON, expected)
+			}
+			if !reflect.DeepEqual(fromYAML, expected) {
+				t.Errorf("YAML representation decoded to %v; expected %v", fromYAML, expected)
+			}
+			if !reflect.DeepEqual(fromQuery, expected) {
+				t.Errorf("format=yaml representation decoded to %v; expected %v", fromQuery, expected)
+			}
+		})
+	}
+}
+
+func TestRBDefWatchHandler(t *testing.T) {
+	db.DBconn = &db.MockDB{}
+	server := httptest.NewServer(NewRouter(rb.NewDefinitionClient(), nil, nil, nil, nil, nil, nil, nil))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1/rb/definition?watch=true")
+	if err != nil {
+		t.Fatalf("Unable to open event stream (%s)", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %s", resp.Header.Get("Content-Type"))
+	}
+
+	body := bytes.NewBufferString(`{"rb-name":"testresourcebundle","rb-version":"v1"}`)
+	created, err := http.Post(server.URL+"/v1/rb/definition", "application/json", body)
+	if err != nil {
+		t.Fatalf("Unable to create definition (%s)", err)
+	}
+	created.Body.Close()
+	if created.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected %d; Got: %d", http.StatusCreated, created.StatusCode)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	var event []string
+	timeout := time.After(5 * time.Second)
+	for len(event) < 2 {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("Event stream closed, got %v", event)
+			}
+			event = append(event, line)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for the create event, got %v", event)
+		}
+	}
+
+	if event[0] != "event: create" {
+		t.Fatalf("Expected a create event, got %s", event[0])
+	}
+	got := rb.Definition{}
+	json.Unmarshal([]byte(strings.TrimPrefix(event[1], "data: ")), &got)
+	if got.RBName != "testresourcebundle" || got.RBVersion != "v1" {
+		t.Fatalf("Unexpected event data %s", event[1])
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/db"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/rb"
 )
 
 // healthCheckHandler executes a db read to return health of k8splugin
@@ -33,3 +34,25 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// livenessHandler reports that the k8splugin API server is running, it
+// backs the liveness probe
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// readinessHandler reports whether k8splugin can serve requests, i.e. if
+// its backing store is reachable, it backs the readiness probe
+type readinessHandler struct {
+	client rb.DefinitionManager
+}
+
+func (h readinessHandler) readyHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.client.Ping()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
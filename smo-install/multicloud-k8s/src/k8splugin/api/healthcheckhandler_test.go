@@ -56,3 +56,45 @@ func TestHealthCheckHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestLivenessHandler(t *testing.T) {
+	request := httptest.NewRequest("GET", "/healthz", nil)
+	resp := executeRequest(request, NewRouter(&mockRBDefinition{Err: pkgerrors.New("Runtime Error in DB")},
+		nil, nil, nil, nil, nil, nil, nil))
+
+	// The liveness doesn't depend on the store
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	testCases := []struct {
+		label        string
+		rbDefClient  *mockRBDefinition
+		expectedCode int
+	}{
+		{
+			label:        "Store reachable",
+			rbDefClient:  &mockRBDefinition{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			label:        "Store unavailable",
+			rbDefClient:  &mockRBDefinition{Err: pkgerrors.New("Runtime Error in DB")},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/readyz", nil)
+			resp := executeRequest(request, NewRouter(testCase.rbDefClient, nil, nil, nil, nil, nil, nil, nil))
+
+			//Check returned code
+			if resp.StatusCode != testCase.expectedCode {
+				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
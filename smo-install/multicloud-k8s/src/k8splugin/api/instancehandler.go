@@ -21,6 +21,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/app"
 	log "github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
@@ -180,6 +181,192 @@ func (i instanceHandler) statusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// statusSummariesHandler returns the readiness of the instances listed in
+// the body, e.g. {"ids": ["id1", "id2"]}
+func (i instanceHandler) statusSummariesHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	switch {
+	case err == io.EOF:
+		log.Error("Body Empty", log.Fields{
+			"error": io.EOF,
+		})
+		http.Error(w, "Body empty", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Error("Error unmarshaling Body", log.Fields{
+			"error": err,
+		})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if len(req.IDs) == 0 {
+		http.Error(w, "Missing instance ids", http.StatusBadRequest)
+		return
+	}
+
+	resp := i.client.StatusSummaries(req.IDs)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error("Error Marshaling Response", log.Fields{
+			"error":    err,
+			"response": resp,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// eventsHandler returns the Kubernetes Events of an instance's resources
+func (i instanceHandler) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["instID"]
+
+	resp, err := i.client.Events(id)
+	if err != nil {
+		log.Error("Error getting Events", log.Fields{
+			"error": err,
+			"id":    id,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error("Error Marshaling Response", log.Fields{
+			"error":    err,
+			"response": resp,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// exportHandler returns the resources of an instance as a YAML manifest
+func (i instanceHandler) exportHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["instID"]
+
+	resp, err := i.client.Export(id)
+	if err != nil {
+		log.Error("Error exporting Instance", log.Fields{
+			"error": err,
+			"id":    id,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp)
+}
+
+// resourcesHandler returns a page of the resources of an instance
+func (i instanceHandler) resourcesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["instID"]
+	cursor := r.FormValue("continue")
+	limit := 0
+	if l := r.FormValue("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := i.client.ListResources(id, cursor, limit)
+	if err != nil {
+		log.Error("Error listing Resources", log.Fields{
+			"error":    err,
+			"id":       id,
+			"continue": cursor,
+			"limit":    limit,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error("Error Marshaling Response", log.Fields{
+			"error":    err,
+			"response": resp,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// driftHandler reports how the resources of an instance drifted in the cluster
+func (i instanceHandler) driftHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["instID"]
+
+	resp, err := i.client.Drift(id)
+	if err != nil {
+		log.Error("Error detecting Drift", log.Fields{
+			"error": err,
+			"id":    id,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error("Error Marshaling Response", log.Fields{
+			"error":    err,
+			"response": resp,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// healthHandler compares the readiness cached for the instance with the
+// live state of its pods
+func (i instanceHandler) healthHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["instID"]
+
+	resp, err := i.client.Health(id)
+	if err != nil {
+		log.Error("Error getting Health", log.Fields{
+			"error": err,
+			"id":    id,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error("Error Marshaling Response", log.Fields{
+			"error":    err,
+			"response": resp,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // queryHandler retrieves information about specified resources for instance
 func (i instanceHandler) queryHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
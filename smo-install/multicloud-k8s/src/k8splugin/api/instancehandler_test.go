@@ -80,6 +80,18 @@ func (m *mockInstanceClient) Status(id string) (app.InstanceStatus, error) {
 	return m.statusItem, nil
 }
 
+func (m *mockInstanceClient) StatusSummaries(ids []string) []app.InstanceStatusSummary {
+	summaries := make([]app.InstanceStatusSummary, 0, len(ids))
+	for _, id := range ids {
+		summaries = append(summaries, app.InstanceStatusSummary{
+			ID:            id,
+			Ready:         m.statusItem.Ready,
+			ResourceCount: m.statusItem.ResourceCount,
+		})
+	}
+	return summaries
+}
+
 func (m *mockInstanceClient) List(rbname, rbversion, profilename string) ([]app.InstanceMiniResponse, error) {
 	if m.err != nil {
 		return []app.InstanceMiniResponse{}, m.err
@@ -755,3 +767,48 @@ func TestInstanceQueryHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestInstanceStatusSummariesHandler(t *testing.T) {
+	testCases := []struct {
+		label            string
+		input            string
+		expectedCode     int
+		expectedResponse []app.InstanceStatusSummary
+	}{
+		{
+			label:        "Fail without instance ids",
+			input:        `{"ids": []}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			label:        "Succesfully get status summaries",
+			input:        `{"ids": ["HaKpys8e", "bmFtZSAr"]}`,
+			expectedCode: http.StatusOK,
+			expectedResponse: []app.InstanceStatusSummary{
+				{ID: "HaKpys8e", Ready: true, ResourceCount: 1},
+				{ID: "bmFtZSAr", Ready: true, ResourceCount: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			instClient := &mockInstanceClient{
+				statusItem: app.InstanceStatus{Ready: true, ResourceCount: 1},
+			}
+			request := httptest.NewRequest("POST", "/v1/instance/status", bytes.NewBufferString(testCase.input))
+			resp := executeRequest(request, NewRouter(nil, nil, instClient, nil, nil, nil, nil, nil))
+
+			if testCase.expectedCode != resp.StatusCode {
+				t.Fatalf("Request method returned: %v and it was expected: %v", resp.StatusCode, testCase.expectedCode)
+			}
+			if resp.StatusCode == http.StatusOK {
+				var response []app.InstanceStatusSummary
+				json.NewDecoder(resp.Body).Decode(&response)
+				if !reflect.DeepEqual(testCase.expectedResponse, response) {
+					t.Fatalf("Request method returned: \n%v\n and it was expected: \n%v", response, testCase.expectedResponse)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+)
+
+// Key styles supported for the JSON representation of Definitions.
+// kebab-case is the style of the json tags of the Go types.
+const (
+	namingKebabCase = "kebab-case"
+	namingCamelCase = "camelCase"
+	namingSnakeCase = "snake_case"
+)
+
+// jsonNaming returns the key style requested with the naming parameter of
+// an Accept or Content-Type header, e.g. "application/json; naming=camelCase".
+// The configured default is used when the parameter is missing or unknown.
+func jsonNaming(header string) string {
+	for _, mediaType := range strings.Split(header, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaType))
+		if err != nil {
+			continue
+		}
+		switch naming := params["naming"]; naming {
+		case namingKebabCase, namingCamelCase, namingSnakeCase:
+			return naming
+		}
+	}
+
+	switch naming := config.GetConfiguration().JSONNaming; naming {
+	case namingCamelCase, namingSnakeCase:
+		return naming
+	}
+	return namingKebabCase
+}
+
+// encodeNamed writes v as JSON with the top level keys of its objects
+// converted to the given style. Nested keys such as labels are user data
+// and are left untouched.
+func encodeNamed(w io.Writer, v interface{}, naming string) error {
+	if naming == namingKebabCase {
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(raw, &generic); err != nil {
+		return err
+	}
+
+	convert := kebabToCamel
+	if naming == namingSnakeCase {
+		convert = kebabToSnake
+	}
+	return json.NewEncoder(w).Encode(renameKeys(generic, convert))
+}
+
+// Media types a Definition can be returned in
+const (
+	mediaTypeJSON = "application/json"
+	mediaTypeYAML = "application/yaml"
+)
+
+// responseMediaType returns YAML when it is requested with the Accept header
+// or the format=yaml query parameter, JSON otherwise
+func responseMediaType(r *http.Request) string {
+	if r.URL.Query().Get("format") == "yaml" {
+		return mediaTypeYAML
+	}
+	for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaType))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case mediaTypeYAML, "application/x-yaml", "text/yaml":
+			return mediaTypeYAML
+		case mediaTypeJSON:
+			return mediaTypeJSON
+		}
+	}
+	return mediaTypeJSON
+}
+
+// encodeMediaType writes v in the given media type. YAML keeps the keys
+// of the JSON representation, including their naming style.
+func encodeMediaType(w io.Writer, v interface{}, mediaType, naming string) error {
+	if mediaType != mediaTypeYAML {
+		return encodeNamed(w, v, naming)
+	}
+
+	var raw bytes.Buffer
+	if err := encodeNamed(&raw, v, naming); err != nil {
+		return err
+	}
+	out, err := yaml.JSONToYAML(raw.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// decodeNamed reads JSON written in the given style into v.
+// It returns io.EOF for an empty body like json.Decoder does.
+func decodeNamed(r io.Reader, v interface{}, naming string) error {
+	if naming == namingKebabCase {
+		return json.NewDecoder(r).Decode(v)
+	}
+
+	var generic interface{}
+	if err := json.NewDecoder(r).Decode(&generic); err != nil {
+		return err
+	}
+
+	convert := camelToKebab
+	if naming == namingSnakeCase {
+		convert = snakeToKebab
+	}
+	raw, err := json.Marshal(renameKeys(generic, convert))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// renameKeys converts the keys of an object, or of each object of a list
+func renameKeys(v interface{}, convert func(string) string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			renamed[convert(key)] = value
+		}
+		return renamed
+	case []interface{}:
+		for i := range t {
+			t[i] = renameKeys(t[i], convert)
+		}
+	}
+	return v
+}
+
+func kebabToCamel(key string) string {
+	parts := strings.Split(key, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func kebabToSnake(key string) string {
+	return strings.Replace(key, "-", "_", -1)
+}
+
+func camelToKebab(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func snakeToKebab(key string) string {
+	return strings.Replace(key, "_", "-", -1)
+}
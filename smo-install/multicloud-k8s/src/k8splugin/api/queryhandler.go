@@ -22,6 +22,8 @@ import (
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/app"
 	log "github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
+
+	"github.com/gorilla/mux"
 )
 
 // Used to store the backend implementation objects
@@ -77,3 +79,32 @@ func (i queryHandler) queryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// whoamiHandler returns the identity the plugin is authenticated as in a
+// cloud region
+func (i queryHandler) whoamiHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cloudRegion := vars["connname"]
+
+	resp, err := i.client.WhoAmI(cloudRegion)
+	if err != nil {
+		log.Error("Error getting identity", log.Fields{
+			"error":       err,
+			"cloudRegion": cloudRegion,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error("Error Marshaling Response", log.Fields{
+			"error":    err,
+			"response": resp,
+		})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
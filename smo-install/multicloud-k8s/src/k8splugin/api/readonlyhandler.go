@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// readOnlyPath is the admin endpoint used to toggle the read-only mode.
+// It is never blocked by the read-only middleware.
+const readOnlyPath = "/v1/admin/read-only"
+
+// readOnlySafePaths are the paths of the non GET requests which don't
+// mutate anything, so they are allowed in read-only mode
+var readOnlySafePaths = map[string]bool{
+	readOnlyPath: true,
+	// Status summaries of several instances are POSTed for the body only
+	"/v1/instance/status": true,
+}
+
+// ReadOnlyMode is the body accepted and returned by the read-only endpoint
+type ReadOnlyMode struct {
+	ReadOnly bool `json:"read-only"`
+}
+
+// readOnlyHandler rejects mutating requests while the API is in
+// read-only mode, which is used during migrations
+type readOnlyHandler struct {
+	enabled int32
+}
+
+func (h *readOnlyHandler) isEnabled() bool {
+	return atomic.LoadInt32(&h.enabled) == 1
+}
+
+func (h *readOnlyHandler) set(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&h.enabled, v)
+}
+
+// middleware returns 503 for any request that is not a read, or one of the
+// readOnlySafePaths, while read-only mode is enabled
+func (h *readOnlyHandler) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if h.isEnabled() && !readOnlySafePaths[r.URL.Path] {
+				http.Error(w, "API is in read-only mode, mutations are not allowed",
+					http.StatusServiceUnavailable)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getHandler returns the current read-only mode
+func (h *readOnlyHandler) getHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(ReadOnlyMode{ReadOnly: h.isEnabled()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateHandler enables or disables the read-only mode
+func (h *readOnlyHandler) updateHandler(w http.ResponseWriter, r *http.Request) {
+	var v ReadOnlyMode
+
+	err := json.NewDecoder(r.Body).Decode(&v)
+	switch {
+	case err == io.EOF:
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.set(v.ReadOnly)
+	h.getHandler(w, r)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/app"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/rb"
+)
+
+func TestReadOnlyMode(t *testing.T) {
+	rbDefClient := &mockRBDefinition{
+		Items: []rb.Definition{
+			{
+				RBName:      "testresourcebundle",
+				RBVersion:   "v1",
+				ChartName:   "testchart",
+				Description: "test description",
+			},
+		},
+	}
+	instClient := &mockInstanceClient{
+		statusItem: app.InstanceStatus{Ready: true},
+	}
+	router := NewRouter(rbDefClient, nil, instClient, nil, nil, nil, nil, nil)
+
+	request := httptest.NewRequest("PUT", "/v1/admin/read-only",
+		bytes.NewBuffer([]byte(`{"read-only":true}`)))
+	resp := executeRequest(request, router)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	got := ReadOnlyMode{}
+	json.NewDecoder(resp.Body).Decode(&got)
+	if !got.ReadOnly {
+		t.Fatal("Expected read-only mode to be enabled")
+	}
+
+	testCases := []struct {
+		label        string
+		method       string
+		url          string
+		body         []byte
+		expectedCode int
+	}{
+		{
+			label:  "Create Definition Is Blocked",
+			method: "POST",
+			url:    "/v1/rb/definition",
+			body: []byte(`{
+				"rb-name":"testresourcebundle",
+				"rb-version":"v1"
+				}`),
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			label:        "Update Definition Is Blocked",
+			method:       "PUT",
+			url:          "/v1/rb/definition/testresourcebundle/v1",
+			body:         []byte(`{"description":"new description"}`),
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			label:        "Upload Definition Content Is Blocked",
+			method:       "POST",
+			url:          "/v1/rb/definition/testresourcebundle/v1/content",
+			body:         []byte{0x1f, 0x8b, 0x08},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			label:        "Delete Definition Is Blocked",
+			method:       "DELETE",
+			url:          "/v1/rb/definition/testresourcebundle/v1",
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			label:        "Get Definition Succeeds",
+			method:       "GET",
+			url:          "/v1/rb/definition/testresourcebundle/v1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			label:        "List Definitions Succeeds",
+			method:       "GET",
+			url:          "/v1/rb/definition",
+			expectedCode: http.StatusOK,
+		},
+		{
+			label:        "Instance Status Summaries Succeed",
+			method:       "POST",
+			url:          "/v1/instance/status",
+			body:         []byte(`{"ids":["HaKpys8e"]}`),
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.url, bytes.NewBuffer(testCase.body))
+			resp := executeRequest(request, router)
+
+			//Check returned code
+			if resp.StatusCode != testCase.expectedCode {
+				t.Fatalf("Expected %d; Got: %d", testCase.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	request = httptest.NewRequest("PUT", "/v1/admin/read-only",
+		bytes.NewBuffer([]byte(`{"read-only":false}`)))
+	resp = executeRequest(request, router)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected %d; Got: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	request = httptest.NewRequest("DELETE", "/v1/rb/definition/testresourcebundle/v1", nil)
+	resp = executeRequest(request, router)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected %d after disabling read-only mode; Got: %d", http.StatusNoContent, resp.StatusCode)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/onap/multicloud-k8s/src/k8splugin/api"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/auth"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
 
 	"github.com/gorilla/handlers"
 )
@@ -38,6 +39,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	err = logutils.SetLevel(config.GetConfiguration().LogLevel)
+	if err != nil {
+		log.Println("Invalid log level, using info: " + err.Error())
+	}
+
 	rand.Seed(time.Now().UnixNano())
 
 	httpRouter := api.NewRouter(nil, nil, nil, nil, nil, nil, nil, nil)
@@ -33,7 +33,9 @@ require (
 	github.com/mitchellh/reflectwalk v1.0.1 // indirect
 	github.com/pierrec/lz4 v2.0.5+incompatible // indirect
 	github.com/pkg/errors v0.9.1
-	github.com/sirupsen/logrus v1.7.0
+	github.com/prometheus/client_golang v1.7.1
+	github.com/prometheus/common v0.10.0
+	github.com/sirupsen/logrus v1.8.1
 	github.com/xdg/scram v0.0.0-20180814205039-7eeb5667e42c // indirect
 	github.com/xdg/stringprep v1.0.0 // indirect
 	github.com/yvasiyarov/go-metrics v0.0.0-20150112132944-c25f46c4b940 // indirect
@@ -41,13 +43,16 @@ require (
 	github.com/yvasiyarov/newrelic_platform_go v0.0.0-20160601141957-9c099fbc30e9 // indirect
 	go.etcd.io/etcd v3.3.12+incompatible
 	go.mongodb.org/mongo-driver v1.1.2
-	golang.org/x/net v0.0.0-20200707034311-ab3426394381
-	gopkg.in/yaml.v2 v2.3.0
-	helm.sh/helm/v3 v3.5.0
-	k8s.io/api v0.20.1
-	k8s.io/apiextensions-apiserver v0.20.1
-	k8s.io/apimachinery v0.20.1
-	k8s.io/cli-runtime v0.20.1
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
+	golang.org/x/net v0.0.0-20210224082022-3d97a244fca7
+	gopkg.in/yaml.v2 v2.4.0
+	helm.sh/helm/v3 v3.6.3
+	k8s.io/api v0.21.1
+	k8s.io/apiextensions-apiserver v0.21.1
+	k8s.io/apimachinery v0.21.1
+	k8s.io/cli-runtime v0.21.1
 	k8s.io/client-go v11.0.1-0.20190409021438-1a26190bd76a+incompatible
 	rsc.io/letsencrypt v0.0.3 // indirect
 )
@@ -55,28 +60,28 @@ require (
 replace (
 	github.com/containerd/containerd => github.com/containerd/containerd v1.4.3
 	github.com/hashicorp/consul => github.com/hashicorp/consul v1.5.1
-	k8s.io/api => k8s.io/api v0.19.4
-	k8s.io/apiextensions-apiserver => k8s.io/apiextensions-apiserver v0.19.4
-	k8s.io/apimachinery => k8s.io/apimachinery v0.19.4
-	k8s.io/apiserver => k8s.io/apiserver v0.19.4
-	k8s.io/cli-runtime => k8s.io/cli-runtime v0.19.4
-	k8s.io/client-go => k8s.io/client-go v0.19.4
-	k8s.io/cloud-provider => k8s.io/cloud-provider v0.19.4
-	k8s.io/cluster-bootstrap => k8s.io/cluster-bootstrap v0.19.4
-	k8s.io/code-generator => k8s.io/code-generator v0.19.4
-	k8s.io/component-base => k8s.io/component-base v0.19.4
-	k8s.io/cri-api => k8s.io/cri-api v0.19.4
-	k8s.io/csi-translation-lib => k8s.io/csi-translation-lib v0.19.4
-	k8s.io/kube-aggregator => k8s.io/kube-aggregator v0.19.4
-	k8s.io/kube-controller-manager => k8s.io/kube-controller-manager v0.19.4
-	k8s.io/kube-proxy => k8s.io/kube-proxy v0.19.4
-	k8s.io/kube-scheduler => k8s.io/kube-scheduler v0.19.4
-	k8s.io/kubectl => k8s.io/kubectl v0.19.4
-	k8s.io/kubelet => k8s.io/kubelet v0.19.4
+	k8s.io/api => k8s.io/api v0.21.1
+	k8s.io/apiextensions-apiserver => k8s.io/apiextensions-apiserver v0.21.1
+	k8s.io/apimachinery => k8s.io/apimachinery v0.21.1
+	k8s.io/apiserver => k8s.io/apiserver v0.21.1
+	k8s.io/cli-runtime => k8s.io/cli-runtime v0.21.1
+	k8s.io/client-go => k8s.io/client-go v0.21.1
+	k8s.io/cloud-provider => k8s.io/cloud-provider v0.21.1
+	k8s.io/cluster-bootstrap => k8s.io/cluster-bootstrap v0.21.1
+	k8s.io/code-generator => k8s.io/code-generator v0.21.1
+	k8s.io/component-base => k8s.io/component-base v0.21.1
+	k8s.io/cri-api => k8s.io/cri-api v0.21.1
+	k8s.io/csi-translation-lib => k8s.io/csi-translation-lib v0.21.1
+	k8s.io/kube-aggregator => k8s.io/kube-aggregator v0.21.1
+	k8s.io/kube-controller-manager => k8s.io/kube-controller-manager v0.21.1
+	k8s.io/kube-proxy => k8s.io/kube-proxy v0.21.1
+	k8s.io/kube-scheduler => k8s.io/kube-scheduler v0.21.1
+	k8s.io/kubectl => k8s.io/kubectl v0.21.1
+	k8s.io/kubelet => k8s.io/kubelet v0.21.1
 	k8s.io/kubernetes => github.com/kubernetes/kubernetes v1.19.4
-	k8s.io/legacy-cloud-providers => k8s.io/legacy-cloud-providers v0.19.4
-	k8s.io/metrics => k8s.io/metrics v0.19.4
-	k8s.io/sample-apiserver => k8s.io/sample-apiserver v0.19.4
+	k8s.io/legacy-cloud-providers => k8s.io/legacy-cloud-providers v0.21.1
+	k8s.io/metrics => k8s.io/metrics v0.21.1
+	k8s.io/sample-apiserver => k8s.io/sample-apiserver v0.21.1
 )
 
 go 1.13
@@ -18,10 +18,15 @@ limitations under the License.
 package app
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 
 	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 
 	//appsv1beta1 "k8s.io/api/apps/v1beta1"
 	//appsv1beta2 "k8s.io/api/apps/v1beta2"
@@ -32,6 +37,7 @@ import (
 	//apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	//apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -44,9 +50,14 @@ import (
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
 	log "github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
 
+	"github.com/ghodss/yaml"
 	pkgerrors "github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -68,8 +79,15 @@ type KubernetesClient struct {
 	discoverClient *disk.CachedDiscoveryClient
 	restMapper     meta.RESTMapper
 	instanceID     string
+	cloudRegion    string
+	clusterLabels  map[string]string
+	owner          *metav1.OwnerReference
+	ownerNamespace string
 }
 
+// Compile time check to see if KubernetesClient passes its owner to the plugins
+var _ plugin.OwnerConnector = &KubernetesClient{}
+
 // ResourceStatus holds Resource Runtime Data
 type ResourceStatus struct {
 	Name   string                    `json:"name"`
@@ -179,6 +197,21 @@ func (k *KubernetesClient) getRestApi(apiVersion string) (rest.Interface, error)
 }
 
 func (k *KubernetesClient) WatchHookUntilReady(timeout time.Duration, ns string, res helm.KubernetesResource) error {
+	//Plugins able to report progress, e.g. while a LoadBalancer is provisioned, are used directly
+	if kindPlugin, err := plugin.GetPluginByKind(res.GVK.Kind); err == nil {
+		if watcher, ok := plugin.AsProgressWatcher(kindPlugin); ok {
+			return watcher.WatchUntilReadyWithProgress(context.TODO(), timeout, ns, res, k.clientSet,
+				func(reason, message string) {
+					log.Info("Waiting for resource", log.Fields{
+						"kind":    res.GVK.Kind,
+						"name":    res.Name,
+						"reason":  reason,
+						"message": message,
+					})
+				})
+		}
+	}
+
 	//for now, only generic plugin has dedicated WatchUntilReady implemented. Later, we can implement this function
 	//for each plugin separately.
 	pluginImpl, err := plugin.GetPluginByKind("generic")
@@ -205,7 +238,34 @@ func (k *KubernetesClient) WatchHookUntilReady(timeout time.Duration, ns string,
 		return pkgerrors.Wrap(err, "Get rest client")
 	}
 
-	return pluginImpl.WatchUntilReady(timeout, ns, res, mapper, restClient, objType, k.clientSet)
+	return pluginImpl.WatchUntilReady(context.TODO(), timeout, ns, res, mapper, restClient, objType, k.clientSet)
+}
+
+// readyTimeout returns the seconds to wait for the resource to become ready.
+// A given timeout > 0 is used as is. Otherwise the timeout configured for the
+// kind and type of the resource, e.g. "Service/LoadBalancer", or else for its
+// kind is used, and waiting stays disabled when none is configured.
+func readyTimeout(resTempl helm.KubernetesResourceTemplate, timeout int64) int64 {
+	timeouts := config.GetConfiguration().ReadyTimeouts
+	if timeout > 0 || len(timeouts) == 0 {
+		return timeout
+	}
+
+	kind := resTempl.GVK.Kind
+	var obj unstructured.Unstructured
+	if _, err := utils.DecodeYAML(resTempl.FilePath, &obj); err == nil {
+		resType, found, _ := unstructured.NestedString(obj.Object, "spec", "type")
+		if found && resType != "" {
+			if t, ok := timeouts[kind+"/"+resType]; ok {
+				return t
+			}
+		}
+	}
+	if t, ok := timeouts[kind]; ok {
+		return t
+	}
+
+	return timeout
 }
 
 // getPodsByLabel yields status of all pods under given instance ID
@@ -242,6 +302,66 @@ func (k *KubernetesClient) getPodsByLabel(namespace string) ([]ResourceStatus, e
 	return resp, nil
 }
 
+// getEventsByLabel gathers the Events referencing the pods and services
+// labeled with the instance ID and returns them sorted by time. The Events
+// are listed per kind with a field selector on their involvedObject.
+func (k *KubernetesClient) getEventsByLabel(namespace string) ([]corev1.Event, error) {
+	coreClient := k.GetStandardClient().CoreV1()
+	listOpts := metav1.ListOptions{
+		LabelSelector: config.GetConfiguration().KubernetesLabelName + "=" + k.instanceID,
+	}
+
+	//Index the names of the labeled resources by kind
+	labeled := map[string]map[string]bool{"Pod": {}, "Service": {}}
+	podList, err := coreClient.Pods(namespace).List(context.TODO(), listOpts)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Retrieving PodList from cluster")
+	}
+	for _, pod := range podList.Items {
+		labeled["Pod"][pod.Name] = true
+	}
+	serviceList, err := coreClient.Services(namespace).List(context.TODO(), listOpts)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Retrieving ServiceList from cluster")
+	}
+	for _, svc := range serviceList.Items {
+		labeled["Service"][svc.Name] = true
+	}
+
+	events := make([]corev1.Event, 0)
+	for _, kind := range []string{"Pod", "Service"} {
+		if len(labeled[kind]) == 0 {
+			continue
+		}
+		selector := fields.OneTermEqualSelector("involvedObject.kind", kind).String()
+		eventList, err := coreClient.Events(namespace).List(context.TODO(), metav1.ListOptions{FieldSelector: selector})
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Retrieving EventList of %s from cluster", kind)
+		}
+		for _, event := range eventList.Items {
+			if event.InvolvedObject.Kind == kind && labeled[kind][event.InvolvedObject.Name] {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	return events, nil
+}
+
+// eventTime returns the most relevant timestamp of an Event
+func eventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.FirstTimestamp.Time
+}
+
 func (k *KubernetesClient) queryResources(apiVersion, kind, labelSelector, namespace string) ([]ResourceStatus, error) {
 	dynClient := k.GetDynamicClient()
 	mapper := k.GetMapper()
@@ -278,6 +398,316 @@ func (k *KubernetesClient) queryResources(apiVersion, kind, labelSelector, names
 	return resp, nil
 }
 
+// ResourcePage is a page of resources listed across several kinds
+type ResourcePage struct {
+	Resources []ResourceStatus `json:"resources"`
+	// Continue is the cursor to pass to get the next page, empty on the
+	// last page
+	Continue string `json:"continue,omitempty"`
+}
+
+// resourceCursor is the position after which the next page starts
+type resourceCursor struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// listResourcePage lists the resources of the given kinds matching the label
+// selector, ordered by kind then name, and returns at most limit of them
+// starting after cursor. The cursor records the last returned position, so
+// it stays valid when resources are added or removed between calls. Kinds
+// entirely before the cursor are not queried.
+func (k *KubernetesClient) listResourcePage(gvks []schema.GroupVersionKind,
+	labelSelector, namespace, cursor string, limit int) (ResourcePage, error) {
+
+	if limit <= 0 {
+		limit = utils.ResourcesListLimit
+	}
+
+	var after resourceCursor
+	if cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return ResourcePage{}, pkgerrors.Wrap(err, "Decoding cursor")
+		}
+		if err = json.Unmarshal(raw, &after); err != nil {
+			return ResourcePage{}, pkgerrors.Wrap(err, "Decoding cursor")
+		}
+	}
+
+	kinds := make([]schema.GroupVersionKind, len(gvks))
+	copy(kinds, gvks)
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+
+	page := ResourcePage{Resources: make([]ResourceStatus, 0, limit)}
+	for _, gvk := range kinds {
+		if gvk.String() < after.Kind {
+			continue
+		}
+		resources, err := k.queryResources(gvk.GroupVersion().String(), gvk.Kind, labelSelector, namespace)
+		if err != nil {
+			return ResourcePage{}, pkgerrors.Wrapf(err, "Listing %s", gvk.String())
+		}
+		sort.Slice(resources, func(i, j int) bool {
+			return resources[i].Name < resources[j].Name
+		})
+
+		for _, res := range resources {
+			if gvk.String() == after.Kind && res.Name <= after.Name {
+				continue
+			}
+			if len(page.Resources) == limit {
+				last := page.Resources[limit-1]
+				raw, err := json.Marshal(resourceCursor{Kind: last.GVK.String(), Name: last.Name})
+				if err != nil {
+					return ResourcePage{}, pkgerrors.Wrap(err, "Encoding cursor")
+				}
+				page.Continue = base64.RawURLEncoding.EncodeToString(raw)
+				return page, nil
+			}
+			page.Resources = append(page.Resources, res)
+		}
+	}
+
+	return page, nil
+}
+
+// ResourceDrift lists the differences between the resources created by an
+// instance and the resources found in the cluster
+type ResourceDrift struct {
+	Drifted bool `json:"drifted"`
+	// Missing resources were created by the instance but no longer exist
+	Missing []helm.KubernetesResource `json:"missing"`
+	// Extra resources carry the instance label but were not created by it
+	Extra []helm.KubernetesResource `json:"extra"`
+}
+
+// detectDrift compares the expected resources against the resources of the
+// same kinds matching the label selector. Resources owned by a controller,
+// such as the pods of a deployment, are not reported as extra.
+func (k *KubernetesClient) detectDrift(expected []helm.KubernetesResource,
+	labelSelector, namespace string) (ResourceDrift, error) {
+
+	drift := ResourceDrift{
+		Missing: make([]helm.KubernetesResource, 0),
+		Extra:   make([]helm.KubernetesResource, 0),
+	}
+
+	gvks := make([]schema.GroupVersionKind, 0)
+	seen := map[schema.GroupVersionKind]bool{}
+	wanted := map[helm.KubernetesResource]bool{}
+	for _, res := range expected {
+		if !seen[res.GVK] {
+			seen[res.GVK] = true
+			gvks = append(gvks, res.GVK)
+		}
+		wanted[res] = true
+	}
+
+	present := map[helm.KubernetesResource]bool{}
+	for _, gvk := range gvks {
+		resources, err := k.queryResources(gvk.GroupVersion().String(), gvk.Kind, labelSelector, namespace)
+		if err != nil {
+			return ResourceDrift{}, pkgerrors.Wrapf(err, "Listing %s", gvk.String())
+		}
+		for _, rs := range resources {
+			res := helm.KubernetesResource{GVK: gvk, Name: rs.Name}
+			present[res] = true
+			if !wanted[res] && metav1.GetControllerOf(&rs.Status) == nil {
+				drift.Extra = append(drift.Extra, res)
+			}
+		}
+	}
+
+	for _, res := range expected {
+		if present[res] {
+			continue
+		}
+		// Resources without the label, e.g. created by an older version,
+		// are looked up by name before being reported
+		if _, err := k.GetResourceStatus(res, namespace); err != nil {
+			if !k8serrors.IsNotFound(pkgerrors.Cause(err)) {
+				return ResourceDrift{}, pkgerrors.Wrapf(err, "Getting %s %s", res.GVK.Kind, res.Name)
+			}
+			drift.Missing = append(drift.Missing, res)
+		}
+	}
+
+	drift.Drifted = len(drift.Missing) > 0 || len(drift.Extra) > 0
+	return drift, nil
+}
+
+// resourceBundleStateGVR is the ResourceBundleState CR in which the monitor
+// caches the readiness of the resources of an instance
+var resourceBundleStateGVR = schema.GroupVersionResource{
+	Group:    "k8splugin.io",
+	Version:  "v1alpha1",
+	Resource: "resourcebundlestates",
+}
+
+// resourceBundleStateGK identifies the ResourceBundleState templates
+var resourceBundleStateGK = schema.GroupKind{Group: "k8splugin.io", Kind: "ResourceBundleState"}
+
+// resourceBundleStatus is the part of the ResourceBundleState status
+// compared against the live pods
+type resourceBundleStatus struct {
+	Ready       bool         `json:"ready"`
+	PodStatuses []corev1.Pod `json:"podStatuses"`
+}
+
+// InstanceHealth merges the readiness cached by the monitor in the
+// ResourceBundleState of an instance with the live state of its pods.
+// Discrepancies lists where both disagree, e.g. when the monitor missed
+// an update.
+type InstanceHealth struct {
+	// StateFound is false when no ResourceBundleState selects the instance
+	StateFound    bool        `json:"stateFound"`
+	StateReady    bool        `json:"stateReady"`
+	LiveReady     bool        `json:"liveReady"`
+	Pods          []PodHealth `json:"pods"`
+	Discrepancies []string    `json:"discrepancies"`
+}
+
+// PodHealth is the live phase of a pod of an instance along with the phase
+// recorded in the ResourceBundleState
+type PodHealth struct {
+	Name       string          `json:"name"`
+	Phase      corev1.PodPhase `json:"phase"`
+	Ready      bool            `json:"ready"`
+	StatePhase corev1.PodPhase `json:"statePhase,omitempty"`
+}
+
+// getResourceBundleStatus returns the status of the ResourceBundleState
+// whose selector matches the instance label, or nil if there is none
+func (k *KubernetesClient) getResourceBundleStatus(namespace string) (*resourceBundleStatus, error) {
+	list, err := k.GetDynamicClient().Resource(resourceBundleStateGVR).Namespace(namespace).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		// The monitor and its CRD are not installed in the cluster
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, pkgerrors.Wrap(err, "Listing ResourceBundleStates")
+	}
+
+	labelName := config.GetConfiguration().KubernetesLabelName
+	for _, item := range list.Items {
+		value, _, _ := unstructured.NestedString(item.Object, "spec", "selector", "matchLabels", labelName)
+		if value != k.instanceID {
+			continue
+		}
+		status := &resourceBundleStatus{}
+		content, _, _ := unstructured.NestedMap(item.Object, "status")
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(content, status)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Decoding ResourceBundleState %s", item.GetName())
+		}
+		return status, nil
+	}
+	return nil, nil
+}
+
+// getInstanceHealth compares the ResourceBundleState of the instance with
+// its pods listed from the cluster
+func (k *KubernetesClient) getInstanceHealth(namespace string) (InstanceHealth, error) {
+	podList, err := k.GetStandardClient().CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: config.GetConfiguration().KubernetesLabelName + "=" + k.instanceID,
+	})
+	if err != nil {
+		return InstanceHealth{}, pkgerrors.Wrap(err, "Retrieving PodList from cluster")
+	}
+	state, err := k.getResourceBundleStatus(namespace)
+	if err != nil {
+		return InstanceHealth{}, err
+	}
+
+	return mergeInstanceHealth(state, podList.Items), nil
+}
+
+// mergeInstanceHealth reports the live pods and their differences with the
+// state, which can be nil
+func mergeInstanceHealth(state *resourceBundleStatus, pods []corev1.Pod) InstanceHealth {
+	health := InstanceHealth{
+		LiveReady:     true,
+		Pods:          make([]PodHealth, 0, len(pods)),
+		Discrepancies: make([]string, 0),
+	}
+
+	statePhases := map[string]corev1.PodPhase{}
+	if state != nil {
+		health.StateFound = true
+		health.StateReady = state.Ready
+		for _, pod := range state.PodStatuses {
+			statePhases[pod.Name] = pod.Status.Phase
+		}
+	}
+
+	live := map[string]bool{}
+	for _, pod := range pods {
+		live[pod.Name] = true
+		ph := PodHealth{
+			Name:       pod.Name,
+			Phase:      pod.Status.Phase,
+			Ready:      isPodReady(pod),
+			StatePhase: statePhases[pod.Name],
+		}
+		health.Pods = append(health.Pods, ph)
+		if !ph.Ready {
+			health.LiveReady = false
+		}
+		if state == nil {
+			continue
+		}
+
+		statePhase, ok := statePhases[pod.Name]
+		switch {
+		case !ok:
+			health.Discrepancies = append(health.Discrepancies,
+				fmt.Sprintf("Pod %s is missing from the ResourceBundleState", pod.Name))
+		case statePhase != pod.Status.Phase:
+			health.Discrepancies = append(health.Discrepancies,
+				fmt.Sprintf("Pod %s is %s but the ResourceBundleState records %s",
+					pod.Name, pod.Status.Phase, statePhase))
+		}
+	}
+	if state == nil {
+		return health
+	}
+
+	for _, pod := range state.PodStatuses {
+		if !live[pod.Name] {
+			health.Discrepancies = append(health.Discrepancies,
+				fmt.Sprintf("Pod %s of the ResourceBundleState no longer exists", pod.Name))
+		}
+	}
+	if health.StateReady && !health.LiveReady {
+		health.Discrepancies = append(health.Discrepancies,
+			"ResourceBundleState reports the instance ready but some of its pods are not")
+	}
+	if !health.StateReady && health.LiveReady {
+		health.Discrepancies = append(health.Discrepancies,
+			"ResourceBundleState reports the instance not ready but all its pods are")
+	}
+	return health
+}
+
+// isPodReady tells if a pod completed or is running with its containers ready
+func isPodReady(pod corev1.Pod) bool {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return true
+	case corev1.PodRunning:
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady {
+				return cond.Status == corev1.ConditionTrue
+			}
+		}
+	}
+	return false
+}
+
 // GetResourcesStatus yields status of given generic resource
 func (k *KubernetesClient) GetResourceStatus(res helm.KubernetesResource, namespace string) (ResourceStatus, error) {
 	dynClient := k.GetDynamicClient()
@@ -310,12 +740,112 @@ func (k *KubernetesClient) GetResourceStatus(res helm.KubernetesResource, namesp
 	return ResourceStatus{unstruct.GetName(), res.GVK, *unstruct}, nil
 }
 
-// getKubeConfig uses the connectivity client to get the kubeconfig based on the name
+// exportResources fetches the given resources from the cluster and returns
+// them as a multi-document YAML manifest. Fields managed by the apiserver
+// are stripped so that the manifest can be applied again.
+func (k *KubernetesClient) exportResources(resources []helm.KubernetesResource, namespace string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, res := range resources {
+		status, err := k.GetResourceStatus(res, namespace)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Exporting %s %s", res.GVK.Kind, res.Name)
+		}
+
+		obj := status.Status.DeepCopy()
+		stripServerFields(obj)
+		out, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Marshaling %s %s", res.GVK.Kind, res.Name)
+		}
+		buf.WriteString("---\n")
+		buf.Write(out)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// stripServerFields removes the fields populated by the apiserver
+func stripServerFields(obj *unstructured.Unstructured) {
+	for _, field := range []string{"uid", "resourceVersion", "generation",
+		"creationTimestamp", "selfLink", "managedFields"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
+
+// UserIdentity is the user the plugin is authenticated as on a cluster
+type UserIdentity struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// selfSubjectReview mirrors the SelfSubjectReview of authentication.k8s.io,
+// which is more recent than the client-go in use
+type selfSubjectReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Status          struct {
+		UserInfo authenticationv1.UserInfo `json:"userInfo"`
+	} `json:"status,omitempty"`
+}
+
+// selfSubjectReviewVersions are the versions of SelfSubjectReview tried,
+// from the most to the least stable
+var selfSubjectReviewVersions = []string{"v1", "v1beta1", "v1alpha1"}
+
+// whoAmI returns the identity the client is authenticated as, by creating
+// a SelfSubjectReview in the cluster
+func (k *KubernetesClient) whoAmI() (UserIdentity, error) {
+	for _, version := range selfSubjectReviewVersions {
+		review := selfSubjectReview{}
+		review.APIVersion = authenticationv1.GroupName + "/" + version
+		review.Kind = "SelfSubjectReview"
+		body, err := json.Marshal(review)
+		if err != nil {
+			return UserIdentity{}, pkgerrors.Wrap(err, "Marshaling SelfSubjectReview")
+		}
+
+		raw, err := k.clientSet.AuthenticationV1().RESTClient().Post().
+			AbsPath("/apis", authenticationv1.GroupName, version, "selfsubjectreviews").
+			SetHeader("Content-Type", "application/json").
+			Body(body).
+			Do(context.TODO()).
+			Raw()
+		if k8serrors.IsNotFound(err) {
+			// The version isn't served by this cluster
+			continue
+		}
+		if err != nil {
+			return UserIdentity{}, pkgerrors.Wrap(err, "Creating SelfSubjectReview")
+		}
+
+		if err = json.Unmarshal(raw, &review); err != nil {
+			return UserIdentity{}, pkgerrors.Wrap(err, "Unmarshaling SelfSubjectReview")
+		}
+		info := review.Status.UserInfo
+		identity := UserIdentity{
+			Username: info.Username,
+			UID:      info.UID,
+			Groups:   info.Groups,
+		}
+		if len(info.Extra) > 0 {
+			identity.Extra = make(map[string][]string, len(info.Extra))
+			for key, values := range info.Extra {
+				identity.Extra[key] = values
+			}
+		}
+		return identity, nil
+	}
+
+	return UserIdentity{}, pkgerrors.New("SelfSubjectReview is not supported by the cluster")
+}
+
+// getKubeConfig writes the kubeconfig of the connection of the cloud region
 // of the cloudregion. This is written out to a file.
-func (k *KubernetesClient) getKubeConfig(cloudregion string) (string, error) {
+func (k *KubernetesClient) getKubeConfig(conn connection.Connection) (string, error) {
 
-	conn := connection.NewConnectionClient()
-	kubeConfigPath, err := conn.Download(cloudregion)
+	kubeConfigPath, err := connection.WriteKubeconfig(conn)
 	if err != nil {
 		return "", pkgerrors.Wrap(err, "Downloading kubeconfig")
 	}
@@ -334,8 +864,16 @@ func (k *KubernetesClient) Init(cloudregion string, iid string) error {
 	}
 
 	k.instanceID = iid
+	k.cloudRegion = cloudregion
 
-	configPath, err := k.getKubeConfig(cloudregion)
+	conn, err := connection.NewConnectionClient().Get(cloudregion)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Get connection")
+	}
+	k.clusterLabels = conn.Labels
+
+	// The kubeconfig is written from the connection read for its labels
+	configPath, err := k.getKubeConfig(conn)
 	if err != nil {
 		return pkgerrors.Wrap(err, "Get kubeconfig file")
 	}
@@ -348,6 +886,8 @@ func (k *KubernetesClient) Init(cloudregion string, iid string) error {
 		return pkgerrors.Wrap(err, "setConfig: Build config from flags raised an error")
 	}
 
+	setClientLimits(config)
+
 	k.clientSet, err = kubernetes.NewForConfig(config)
 	if err != nil {
 		return err
@@ -383,6 +923,21 @@ func (k *KubernetesClient) Init(cloudregion string, iid string) error {
 	return nil
 }
 
+// setClientLimits applies the configured rate limits and request timeout
+// to the requests made with restConfig
+func setClientLimits(restConfig *rest.Config) {
+	conf := config.GetConfiguration()
+	if conf.KubernetesQPS > 0 {
+		restConfig.QPS = conf.KubernetesQPS
+	}
+	if conf.KubernetesBurst > 0 {
+		restConfig.Burst = conf.KubernetesBurst
+	}
+	if conf.KubernetesRequestTimeout > 0 {
+		restConfig.Timeout = time.Duration(conf.KubernetesRequestTimeout) * time.Second
+	}
+}
+
 func (k *KubernetesClient) ensureNamespace(namespace string) error {
 
 	pluginImpl, err := plugin.GetPluginByKind("Namespace")
@@ -390,7 +945,7 @@ func (k *KubernetesClient) ensureNamespace(namespace string) error {
 		return pkgerrors.Wrap(err, "Loading Namespace Plugin")
 	}
 
-	ns, err := pluginImpl.Get(helm.KubernetesResource{
+	ns, err := pluginImpl.Get(context.TODO(), helm.KubernetesResource{
 		Name: namespace,
 		GVK: schema.GroupVersionKind{
 			Group:   "",
@@ -414,7 +969,7 @@ func (k *KubernetesClient) ensureNamespace(namespace string) error {
 			"namespace": namespace,
 		})
 
-		_, err = pluginImpl.Create("", namespace, k)
+		_, err = pluginImpl.Create(context.TODO(), "", namespace, k)
 		if err != nil {
 			log.Error("Error Creating Namespace", log.Fields{
 				"error":     err,
@@ -441,7 +996,9 @@ func (k *KubernetesClient) CreateKind(resTempl helm.KubernetesResourceTemplate,
 		return helm.KubernetesResource{}, pkgerrors.Wrap(err, "Error loading plugin")
 	}
 
-	createdResourceName, err := pluginImpl.Create(resTempl.FilePath, namespace, k)
+	release := getApplyLimiter().acquire(k.cloudRegion, namespace)
+	createdResourceName, err := pluginImpl.Create(context.TODO(), resTempl.FilePath, namespace, k)
+	release()
 	if err != nil {
 		log.Error("Error Creating Resource", log.Fields{
 			"error":    err,
@@ -478,7 +1035,9 @@ func (k *KubernetesClient) updateKind(resTempl helm.KubernetesResourceTemplate,
 		return helm.KubernetesResource{}, pkgerrors.Wrap(err, "Error loading plugin")
 	}
 
-	updatedResourceName, err := pluginImpl.Update(resTempl.FilePath, namespace, k)
+	release := getApplyLimiter().acquire(k.cloudRegion, namespace)
+	updatedResourceName, err := pluginImpl.Update(context.TODO(), resTempl.FilePath, namespace, k)
+	release()
 	if err != nil {
 		log.Error("Error Updating Resource", log.Fields{
 			"error":    err,
@@ -499,6 +1058,76 @@ func (k *KubernetesClient) updateKind(resTempl helm.KubernetesResourceTemplate,
 	}, nil
 }
 
+// validateResources verifies that the cluster serves the apiVersion/kind of
+// every template before anything is applied. Kinds belonging to a group
+// defined by one of the bundle's own CRDs are skipped as they are only
+// served once the CRDs are installed.
+func (k *KubernetesClient) validateResources(sortedTemplates []helm.KubernetesResourceTemplate,
+	crdList []helm.KubernetesResourceTemplate) error {
+
+	crdGroups := map[string]bool{}
+	for _, crd := range crdList {
+		unstruct := &unstructured.Unstructured{}
+		_, err := utils.DecodeYAML(crd.FilePath, unstruct)
+		if err != nil {
+			return pkgerrors.Wrap(err, "Decode CRD object error")
+		}
+		group, _, _ := unstructured.NestedString(unstruct.Object, "spec", "group")
+		crdGroups[group] = true
+	}
+
+	var templates []helm.KubernetesResourceTemplate
+	for _, resTempl := range append(crdList, sortedTemplates...) {
+		if !crdGroups[resTempl.GVK.Group] {
+			templates = append(templates, resTempl)
+		}
+	}
+
+	unsupported, err := plugin.ValidateAPIVersions(templates, k)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Validating API versions")
+	}
+
+	if len(unsupported) > 0 {
+		var kinds []string
+		for _, resTempl := range unsupported {
+			apiVersion, kind := resTempl.GVK.ToAPIVersionAndKind()
+			kinds = append(kinds, apiVersion+"/"+kind)
+		}
+		return pkgerrors.New("Resources not supported by the cluster: " + strings.Join(kinds, ", "))
+	}
+
+	return nil
+}
+
+// ClusterSelectorAnnotation holds a label selector, e.g. "env in (prod)",
+// matched against the labels of the cluster connection. Resources whose
+// selector doesn't match are skipped instead of applied.
+const ClusterSelectorAnnotation = "k8splugin.io/cluster-selector"
+
+// skipReason returns why the resource must not be applied to the cluster,
+// or an empty string if it must be
+func (k *KubernetesClient) skipReason(resTempl helm.KubernetesResourceTemplate) (string, error) {
+	var obj unstructured.Unstructured
+	if _, err := utils.DecodeYAML(resTempl.FilePath, &obj); err != nil {
+		return "", pkgerrors.Wrap(err, "Decode resource")
+	}
+
+	expr, ok := obj.GetAnnotations()[ClusterSelectorAnnotation]
+	if !ok {
+		return "", nil
+	}
+	selector, err := labels.Parse(expr)
+	if err != nil {
+		return "", pkgerrors.Wrapf(err, "Parsing %s annotation of %s", ClusterSelectorAnnotation, obj.GetName())
+	}
+	if selector.Matches(labels.Set(k.clusterLabels)) {
+		return "", nil
+	}
+
+	return fmt.Sprintf("cluster labels %v don't match %q", k.clusterLabels, expr), nil
+}
+
 func (k *KubernetesClient) createResources(sortedTemplates []helm.KubernetesResourceTemplate,
 	namespace string) ([]helm.KubernetesResource, error) {
 
@@ -509,12 +1138,28 @@ func (k *KubernetesClient) createResources(sortedTemplates []helm.KubernetesReso
 		return createdResources, pkgerrors.Wrap(err, "Creating Namespace")
 	}
 
-	for _, resTempl := range sortedTemplates {
+	for _, resTempl := range bundleStateFirst(sortedTemplates) {
+		reason, err := k.skipReason(resTempl)
+		if err != nil {
+			return createdResources, pkgerrors.Wrapf(err, "Error checking kind: %+v", resTempl.GVK)
+		}
+		if reason != "" {
+			log.Warn("Skipping Kubernetes Resource", log.Fields{
+				"filepath": resTempl.FilePath,
+				"reason":   reason,
+			})
+			continue
+		}
 		resCreated, err := k.CreateKind(resTempl, namespace)
 		if err != nil {
 			return createdResources, pkgerrors.Wrapf(err, "Error creating kind: %+v", resTempl.GVK)
 		}
 		createdResources = append(createdResources, resCreated)
+		k.ownByBundleState(resCreated, namespace)
+		err = k.waitUntilReady(resTempl, resCreated, namespace)
+		if err != nil {
+			return createdResources, err
+		}
 	}
 
 	return createdResources, nil
@@ -529,17 +1174,92 @@ func (k *KubernetesClient) updateResources(sortedTemplates []helm.KubernetesReso
 	}
 
 	var updatedResources []helm.KubernetesResource
-	for _, resTempl := range sortedTemplates {
+	for _, resTempl := range bundleStateFirst(sortedTemplates) {
+		reason, err := k.skipReason(resTempl)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Error checking kind: %+v", resTempl.GVK)
+		}
+		if reason != "" {
+			log.Warn("Skipping Kubernetes Resource", log.Fields{
+				"filepath": resTempl.FilePath,
+				"reason":   reason,
+			})
+			continue
+		}
 		resUpdated, err := k.updateKind(resTempl, namespace)
 		if err != nil {
 			return nil, pkgerrors.Wrapf(err, "Error updating kind: %+v", resTempl.GVK)
 		}
+		k.ownByBundleState(resUpdated, namespace)
+		err = k.waitUntilReady(resTempl, resUpdated, namespace)
+		if err != nil {
+			return nil, err
+		}
 		updatedResources = append(updatedResources, resUpdated)
 	}
 
 	return updatedResources, nil
 }
 
+// bundleStateFirst moves the ResourceBundleStates ahead of the other
+// templates, the first one applied becomes the owner of the others
+func bundleStateFirst(templates []helm.KubernetesResourceTemplate) []helm.KubernetesResourceTemplate {
+	sorted := make([]helm.KubernetesResourceTemplate, 0, len(templates))
+	for _, resTempl := range templates {
+		if resTempl.GVK.GroupKind() == resourceBundleStateGK {
+			sorted = append(sorted, resTempl)
+		}
+	}
+	for _, resTempl := range templates {
+		if resTempl.GVK.GroupKind() != resourceBundleStateGK {
+			sorted = append(sorted, resTempl)
+		}
+	}
+	return sorted
+}
+
+// ownByBundleState makes the first ResourceBundleState applied by the client
+// the owner of the resources applied after it, so that Kubernetes garbage
+// collects them with it even if the cleanup of the instance is interrupted.
+// The resources are still applied without an owner if it can't be read.
+func (k *KubernetesClient) ownByBundleState(res helm.KubernetesResource, namespace string) {
+	if k.owner != nil || res.GVK.GroupKind() != resourceBundleStateGK {
+		return
+	}
+
+	bundle, err := k.GetDynamicClient().Resource(resourceBundleStateGVR).Namespace(namespace).
+		Get(context.TODO(), res.Name, metav1.GetOptions{})
+	if err != nil {
+		log.Warn("Resources won't be owned by the ResourceBundleState", log.Fields{
+			"resource": res.Name,
+			"error":    err,
+		})
+		return
+	}
+	k.SetOwnerReference(metav1.OwnerReference{
+		APIVersion: bundle.GetAPIVersion(),
+		Kind:       bundle.GetKind(),
+		Name:       bundle.GetName(),
+		UID:        bundle.GetUID(),
+	}, bundle.GetNamespace())
+}
+
+// waitUntilReady waits for an applied resource to become ready when a ready
+// timeout is configured for its kind, the batch apply has no timeout of its own
+func (k *KubernetesClient) waitUntilReady(resTempl helm.KubernetesResourceTemplate,
+	res helm.KubernetesResource, namespace string) error {
+
+	timeout := readyTimeout(resTempl, 0)
+	if timeout <= 0 {
+		return nil
+	}
+	err := k.WatchHookUntilReady(time.Duration(timeout)*time.Second, namespace, res)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "Waiting for %s %s to become ready", res.GVK.Kind, res.Name)
+	}
+	return nil
+}
+
 func (k *KubernetesClient) DeleteKind(resource helm.KubernetesResource, namespace string) error {
 	log.Warn("Deleting Resource", log.Fields{
 		"gvk":      resource.GVK,
@@ -551,7 +1271,7 @@ func (k *KubernetesClient) DeleteKind(resource helm.KubernetesResource, namespac
 		return pkgerrors.Wrap(err, "Error loading plugin")
 	}
 
-	err = pluginImpl.Delete(resource, namespace, k)
+	err = pluginImpl.Delete(context.TODO(), resource, namespace, k)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") == false {
@@ -601,3 +1321,23 @@ func (k *KubernetesClient) GetStandardClient() kubernetes.Interface {
 func (k *KubernetesClient) GetInstanceID() string {
 	return k.instanceID
 }
+
+// SetOwnerReference sets the owner of the resources created by the plugins
+// with this client, e.g. the ResourceBundleState of the instance, and its
+// namespace. The resources are garbage collected by Kubernetes once it is
+// deleted.
+func (k *KubernetesClient) SetOwnerReference(owner metav1.OwnerReference, namespace string) {
+	k.owner = &owner
+	k.ownerNamespace = namespace
+}
+
+// GetOwnerReference returns the owner set with SetOwnerReference, or nil
+func (k *KubernetesClient) GetOwnerReference() *metav1.OwnerReference {
+	return k.owner
+}
+
+// GetOwnerNamespace returns the namespace of the owner set with
+// SetOwnerReference
+func (k *KubernetesClient) GetOwnerNamespace() string {
+	return k.ownerNamespace
+}
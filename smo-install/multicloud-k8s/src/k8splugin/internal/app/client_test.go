@@ -14,12 +14,17 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"encoding/base64"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"plugin"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
 
@@ -28,8 +33,20 @@ import (
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
 
 	pkgerrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 )
 
 func LoadMockPlugins(krdLoadedPlugins map[string]*plugin.Plugin) error {
@@ -84,6 +101,75 @@ func TestInit(t *testing.T) {
 		}
 
 	})
+	t.Run("Apply the configured client limits", func(t *testing.T) {
+		conf := config.GetConfiguration()
+		oldQPS, oldBurst, oldTimeout := conf.KubernetesQPS, conf.KubernetesBurst, conf.KubernetesRequestTimeout
+		defer func() {
+			conf.KubernetesQPS, conf.KubernetesBurst, conf.KubernetesRequestTimeout = oldQPS, oldBurst, oldTimeout
+		}()
+		conf.KubernetesQPS = 50
+		conf.KubernetesBurst = 100
+		conf.KubernetesRequestTimeout = 30
+
+		// Reuses the connection stored in the mock db by the previous subtest
+		kubeClient := KubernetesClient{}
+		err := kubeClient.Init("mock_connection", "abcdefg")
+		if err != nil {
+			t.Fatalf("TestGetKubeClient returned an error (%s)", err)
+		}
+
+		restConfig := kubeClient.restConfig
+		if restConfig.QPS != 50 || restConfig.Burst != 100 || restConfig.Timeout != 30*time.Second {
+			t.Fatalf("Expected QPS 50, burst 100 and timeout 30s, got %v, %d and %s",
+				restConfig.QPS, restConfig.Burst, restConfig.Timeout)
+		}
+	})
+	t.Run("Read the connection once", func(t *testing.T) {
+		fd, err := ioutil.ReadFile("../../mock_files/mock_configs/mock_kube_config")
+		if err != nil {
+			t.Fatal("Unable to read mock_kube_config")
+		}
+
+		oldDB := db.DBconn
+		defer func() {
+			db.DBconn = oldDB
+		}()
+		mockDB := &readCountingDB{MockDB: &db.MockDB{
+			Items: map[string]map[string][]byte{
+				connection.ConnectionKey{CloudRegion: "mock_connection"}.String(): {
+					"metadata": []byte(
+						"{\"cloud-region\":\"mock_connection\"," +
+							"\"labels\":{\"zone\":\"edge\"}," +
+							"\"kubeconfig\": \"" + base64.StdEncoding.EncodeToString(fd) + "\"}"),
+				},
+			},
+		}}
+		db.DBconn = mockDB
+
+		kubeClient := KubernetesClient{}
+		err = kubeClient.Init("mock_connection", "abcdefg")
+		if err != nil {
+			t.Fatalf("TestGetKubeClient returned an error (%s)", err)
+		}
+
+		if mockDB.reads != 1 {
+			t.Fatalf("Expected the connection to be read once, got %d reads", mockDB.reads)
+		}
+		if kubeClient.clusterLabels["zone"] != "edge" {
+			t.Fatalf("Expected the labels of the connection, got %v", kubeClient.clusterLabels)
+		}
+	})
+}
+
+// readCountingDB counts the reads made to the mock db
+type readCountingDB struct {
+	*db.MockDB
+	reads int
+}
+
+func (m *readCountingDB) Read(table string, key db.Key, tag string) ([]byte, error) {
+	m.reads++
+	return m.MockDB.Read(table, key, tag)
 }
 
 func TestCreateResources(t *testing.T) {
@@ -127,6 +213,139 @@ func TestCreateResources(t *testing.T) {
 	})
 }
 
+// mockWatchedTimeouts returns the timeouts recorded by the WatchUntilReady
+// of the mock plugin, after clearing them
+func mockWatchedTimeouts(t *testing.T) map[string]time.Duration {
+	symbol, err := utils.LoadedPlugins["generic"].Lookup("WatchedTimeouts")
+	if err != nil {
+		t.Fatalf("Unable to find the timeouts of the mock plugin (%s)", err)
+	}
+	timeouts := *symbol.(*map[string]time.Duration)
+	for kind := range timeouts {
+		delete(timeouts, kind)
+	}
+	return timeouts
+}
+
+func TestCreateResourcesReadyTimeout(t *testing.T) {
+	oldkrdPluginData := utils.LoadedPlugins
+	conf := config.GetConfiguration()
+	oldTimeouts := conf.ReadyTimeouts
+	defer func() {
+		utils.LoadedPlugins = oldkrdPluginData
+		conf.ReadyTimeouts = oldTimeouts
+	}()
+	conf.ReadyTimeouts = map[string]int64{"Deployment": 120}
+
+	err := LoadMockPlugins(utils.LoadedPlugins)
+	if err != nil {
+		t.Fatalf("LoadMockPlugins returned an error (%s)", err)
+	}
+	watched := mockWatchedTimeouts(t)
+
+	k8 := KubernetesClient{
+		clientSet: &kubernetes.Clientset{},
+	}
+	data := []helm.KubernetesResourceTemplate{
+		{
+			GVK:      schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			FilePath: "../../mock_files/mock_yamls/deployment.yaml",
+		},
+		{
+			GVK:      schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+			FilePath: "../../mock_files/mock_yamls/job.yaml",
+		},
+	}
+
+	_, err = k8.createResources(data, "testnamespace")
+	if err != nil {
+		t.Fatalf("createResources returned an error (%s)", err)
+	}
+	if watched["Deployment"] != 120*time.Second {
+		t.Fatalf("Expected the Deployment to be watched for 120s, got %v", watched["Deployment"])
+	}
+	if _, ok := watched["Job"]; ok {
+		t.Fatalf("Expected no wait for the Job without a configured timeout")
+	}
+}
+
+func TestCreateResourcesOwnedByBundleState(t *testing.T) {
+	oldkrdPluginData := utils.LoadedPlugins
+	defer func() {
+		utils.LoadedPlugins = oldkrdPluginData
+	}()
+
+	err := LoadMockPlugins(utils.LoadedPlugins)
+	if err != nil {
+		t.Fatalf("LoadMockPlugins returned an error (%s)", err)
+	}
+	symbol, err := utils.LoadedPlugins["generic"].Lookup("CreatedOwners")
+	if err != nil {
+		t.Fatalf("Unable to find the owners recorded by the mock plugin (%s)", err)
+	}
+	owners := *symbol.(*map[string][]metav1.OwnerReference)
+
+	f, err := ioutil.TempFile("", "bundle-state-*.yaml")
+	if err != nil {
+		t.Fatalf("Unable to create manifest (%s)", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`apiVersion: k8splugin.io/v1alpha1
+kind: ResourceBundleState
+metadata:
+  name: resource-name
+`)
+	f.Close()
+	if err != nil {
+		t.Fatalf("Unable to write manifest (%s)", err)
+	}
+
+	// The mock plugin names every created resource resource-name
+	bundle := &unstructured.Unstructured{}
+	bundle.SetAPIVersion("k8splugin.io/v1alpha1")
+	bundle.SetKind("ResourceBundleState")
+	bundle.SetNamespace("testnamespace")
+	bundle.SetName("resource-name")
+	bundle.SetUID("bundle-uid")
+	listKinds := map[schema.GroupVersionResource]string{resourceBundleStateGVR: "ResourceBundleStateList"}
+	k8 := KubernetesClient{
+		clientSet:     &kubernetes.Clientset{},
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, bundle),
+	}
+
+	data := []helm.KubernetesResourceTemplate{
+		{
+			GVK:      schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			FilePath: "../../mock_files/mock_yamls/deployment.yaml",
+		},
+		{
+			GVK:      schema.GroupVersionKind{Group: "k8splugin.io", Version: "v1alpha1", Kind: "ResourceBundleState"},
+			FilePath: f.Name(),
+		},
+	}
+
+	created, err := k8.createResources(data, "testnamespace")
+	if err != nil {
+		t.Fatalf("createResources returned an error (%s)", err)
+	}
+	if len(created) != 2 || created[0].GVK.Kind != "ResourceBundleState" {
+		t.Fatalf("Expected the ResourceBundleState to be created first, got %+v", created)
+	}
+	if len(owners[f.Name()]) != 0 {
+		t.Fatalf("Expected the ResourceBundleState to have no owner, got %+v", owners[f.Name()])
+	}
+	expected := []metav1.OwnerReference{{
+		APIVersion: "k8splugin.io/v1alpha1",
+		Kind:       "ResourceBundleState",
+		Name:       "resource-name",
+		UID:        "bundle-uid",
+	}}
+	deploymentOwners := owners["../../mock_files/mock_yamls/deployment.yaml"]
+	if !reflect.DeepEqual(deploymentOwners, expected) {
+		t.Fatalf("Expected the Deployment to be owned by the ResourceBundleState, got %+v", deploymentOwners)
+	}
+}
+
 func TestDeleteResources(t *testing.T) {
 	oldkrdPluginData := utils.LoadedPlugins
 
@@ -181,3 +400,543 @@ func TestDeleteResources(t *testing.T) {
 		}
 	})
 }
+
+func TestGetEventsByLabel(t *testing.T) {
+	label := map[string]string{config.GetConfiguration().KubernetesLabelName: "inst1"}
+	otherLabel := map[string]string{config.GetConfiguration().KubernetesLabelName: "inst2"}
+	now := time.Now()
+
+	newEvent := func(name, kind, objName string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "testnamespace"},
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      kind,
+				Name:      objName,
+				Namespace: "testnamespace",
+			},
+			LastTimestamp: metav1.NewTime(at),
+		}
+	}
+
+	clientSet := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "testnamespace", Labels: label}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "testnamespace", Labels: label}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "testnamespace", Labels: otherLabel}},
+		newEvent("ev-late", "Pod", "pod1", now),
+		newEvent("ev-early", "Service", "svc1", now.Add(-time.Minute)),
+		newEvent("ev-other", "Pod", "pod2", now.Add(-2*time.Minute)),
+		// Named like the labeled service but about another kind
+		newEvent("ev-endpoints", "Endpoints", "svc1", now.Add(-3*time.Minute)),
+	)
+	var selectors []string
+	clientSet.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		selectors = append(selectors, action.(k8stesting.ListAction).GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+	k8 := KubernetesClient{instanceID: "inst1", clientSet: clientSet}
+
+	events, err := k8.getEventsByLabel("testnamespace")
+	if err != nil {
+		t.Fatalf("getEventsByLabel returned an error (%s)", err)
+	}
+
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Name)
+	}
+	expected := []string{"ev-early", "ev-late"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("getEventsByLabel returned %v, expected %v", names, expected)
+	}
+	expectedSelectors := []string{"involvedObject.kind=Pod", "involvedObject.kind=Service"}
+	if !reflect.DeepEqual(selectors, expectedSelectors) {
+		t.Fatalf("Events were listed with the field selectors %v, expected %v", selectors, expectedSelectors)
+	}
+}
+
+func TestExportResources(t *testing.T) {
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "svc1",
+			Namespace:       "testnamespace",
+			UID:             "3b9bd5e2-7d3c-4a4c-8d3e-1f5a8f0b6f9d",
+			ResourceVersion: "42",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cm1",
+			Namespace:       "testnamespace",
+			ResourceVersion: "43",
+		},
+		Data: map[string]string{"key": "value"},
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Service"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	k8 := KubernetesClient{
+		restMapper:    mapper,
+		dynamicClient: dynamicfake.NewSimpleDynamicClient(scheme.Scheme, service, configMap),
+	}
+
+	manifest, err := k8.exportResources([]helm.KubernetesResource{
+		{GVK: corev1.SchemeGroupVersion.WithKind("Service"), Name: "svc1"},
+		{GVK: corev1.SchemeGroupVersion.WithKind("ConfigMap"), Name: "cm1"},
+	}, "testnamespace")
+	if err != nil {
+		t.Fatalf("exportResources returned an error (%s)", err)
+	}
+
+	docs := strings.Split(strings.TrimPrefix(string(manifest), "---\n"), "---\n")
+	if len(docs) != 2 {
+		t.Fatalf("exportResources returned %d documents, expected 2", len(docs))
+	}
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	obj, _, err := decoder.Decode([]byte(docs[0]), nil, nil)
+	if err != nil {
+		t.Fatalf("Unable to decode exported Service (%s)", err)
+	}
+	gotService, ok := obj.(*corev1.Service)
+	if !ok {
+		t.Fatalf("Expected a Service, got %T", obj)
+	}
+	if gotService.Name != service.Name || !reflect.DeepEqual(gotService.Spec, service.Spec) {
+		t.Fatalf("Exported Service %v doesn't match %v", gotService, service)
+	}
+	if gotService.UID != "" || gotService.ResourceVersion != "" ||
+		!reflect.DeepEqual(gotService.Status, corev1.ServiceStatus{}) {
+		t.Fatalf("Exported Service still has server managed fields: %v", gotService)
+	}
+
+	obj, _, err = decoder.Decode([]byte(docs[1]), nil, nil)
+	if err != nil {
+		t.Fatalf("Unable to decode exported ConfigMap (%s)", err)
+	}
+	gotConfigMap, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		t.Fatalf("Expected a ConfigMap, got %T", obj)
+	}
+	if gotConfigMap.Name != configMap.Name || !reflect.DeepEqual(gotConfigMap.Data, configMap.Data) {
+		t.Fatalf("Exported ConfigMap %v doesn't match %v", gotConfigMap, configMap)
+	}
+}
+
+func TestListResourcePage(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	newObject := func(kind, name string, labeled bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace("testnamespace")
+		if labeled {
+			obj.SetLabels(map[string]string{labelName: "inst1"})
+		}
+		return obj
+	}
+
+	serviceGVK := corev1.SchemeGroupVersion.WithKind("Service")
+	configMapGVK := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(serviceGVK, meta.RESTScopeNamespace)
+	mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+	listKinds := map[schema.GroupVersionResource]string{
+		corev1.SchemeGroupVersion.WithResource("services"):   "ServiceList",
+		corev1.SchemeGroupVersion.WithResource("configmaps"): "ConfigMapList",
+	}
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		newObject("ConfigMap", "cm-unlabeled", false),
+		newObject("Service", "svc-b", true),
+		newObject("Service", "svc-a", true),
+		newObject("Service", "svc-c", true),
+		newObject("ConfigMap", "cm-b", true),
+		newObject("ConfigMap", "cm-a", true),
+	)
+	k8 := KubernetesClient{
+		restMapper:    mapper,
+		dynamicClient: dynClient,
+	}
+
+	var names []string
+	cursor := ""
+	pages := 0
+	for {
+		page, err := k8.listResourcePage([]schema.GroupVersionKind{serviceGVK, configMapGVK},
+			labelName+"=inst1", "testnamespace", cursor, 2)
+		if err != nil {
+			t.Fatalf("listResourcePage returned an error (%s)", err)
+		}
+		if len(page.Resources) > 2 {
+			t.Fatalf("listResourcePage returned %d resources, expected at most 2", len(page.Resources))
+		}
+		for _, res := range page.Resources {
+			names = append(names, res.GVK.Kind+"/"+res.Name)
+		}
+		pages++
+
+		if pages == 1 {
+			// Resources added before the cursor must not shift the next pages
+			_, err = dynClient.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).
+				Namespace("testnamespace").Create(context.TODO(), newObject("ConfigMap", "cm-0", true), metav1.CreateOptions{})
+			if err != nil {
+				t.Fatalf("Unable to add ConfigMap (%s)", err)
+			}
+		}
+
+		if page.Continue == "" {
+			break
+		}
+		cursor = page.Continue
+	}
+
+	expected := []string{"ConfigMap/cm-a", "ConfigMap/cm-b", "Service/svc-a", "Service/svc-b", "Service/svc-c"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("listResourcePage returned %v, expected %v", names, expected)
+	}
+	if pages != 3 {
+		t.Fatalf("listResourcePage returned %d pages, expected 3", pages)
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	newObject := func(kind, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace("testnamespace")
+		obj.SetLabels(map[string]string{labelName: "inst1"})
+		return obj
+	}
+
+	serviceGVK := corev1.SchemeGroupVersion.WithKind("Service")
+	configMapGVK := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(serviceGVK, meta.RESTScopeNamespace)
+	mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+	listKinds := map[schema.GroupVersionResource]string{
+		corev1.SchemeGroupVersion.WithResource("services"):   "ServiceList",
+		corev1.SchemeGroupVersion.WithResource("configmaps"): "ConfigMapList",
+	}
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		newObject("Service", "svc-a"),
+		newObject("Service", "svc-b"),
+		newObject("ConfigMap", "cm-a"),
+	)
+	k8 := KubernetesClient{
+		restMapper:    mapper,
+		dynamicClient: dynClient,
+	}
+	expected := []helm.KubernetesResource{
+		{GVK: serviceGVK, Name: "svc-a"},
+		{GVK: serviceGVK, Name: "svc-b"},
+		{GVK: configMapGVK, Name: "cm-a"},
+	}
+
+	drift, err := k8.detectDrift(expected, labelName+"=inst1", "testnamespace")
+	if err != nil {
+		t.Fatalf("detectDrift returned an error (%s)", err)
+	}
+	if drift.Drifted {
+		t.Fatalf("detectDrift reported drift on an unchanged instance: %+v", drift)
+	}
+
+	// Delete a Service out-of-band and label a ConfigMap with the instance
+	err = dynClient.Resource(corev1.SchemeGroupVersion.WithResource("services")).
+		Namespace("testnamespace").Delete(context.TODO(), "svc-b", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("Unable to delete Service (%s)", err)
+	}
+	_, err = dynClient.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).
+		Namespace("testnamespace").Create(context.TODO(), newObject("ConfigMap", "cm-extra"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unable to add ConfigMap (%s)", err)
+	}
+
+	drift, err = k8.detectDrift(expected, labelName+"=inst1", "testnamespace")
+	if err != nil {
+		t.Fatalf("detectDrift returned an error (%s)", err)
+	}
+	if !drift.Drifted {
+		t.Fatalf("detectDrift didn't report the drift")
+	}
+	missing := []helm.KubernetesResource{{GVK: serviceGVK, Name: "svc-b"}}
+	if !reflect.DeepEqual(drift.Missing, missing) {
+		t.Fatalf("detectDrift reported %v missing, expected %v", drift.Missing, missing)
+	}
+	extra := []helm.KubernetesResource{{GVK: configMapGVK, Name: "cm-extra"}}
+	if !reflect.DeepEqual(drift.Extra, extra) {
+		t.Fatalf("detectDrift reported %v extra, expected %v", drift.Extra, extra)
+	}
+}
+
+func TestGetInstanceHealth(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	// The monitor recorded the instance as ready with a running pod
+	state := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "k8splugin.io/v1alpha1",
+		"kind":       "ResourceBundleState",
+		"metadata": map[string]interface{}{
+			"name":      "inst1-state",
+			"namespace": "testnamespace",
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{labelName: "inst1"},
+			},
+		},
+		"status": map[string]interface{}{
+			"ready":         true,
+			"resourceCount": int64(1),
+			"podStatuses": []interface{}{
+				map[string]interface{}{
+					"metadata": map[string]interface{}{"name": "pod-a"},
+					"status":   map[string]interface{}{"phase": "Running"},
+				},
+			},
+		},
+	}}
+	// but the pod has failed since
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-a",
+			Namespace: "testnamespace",
+			Labels:    map[string]string{labelName: "inst1"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{resourceBundleStateGVR: "ResourceBundleStateList"}, state)
+	k8 := KubernetesClient{
+		clientSet:     fake.NewSimpleClientset(pod),
+		dynamicClient: dynClient,
+		instanceID:    "inst1",
+	}
+
+	health, err := k8.getInstanceHealth("testnamespace")
+	if err != nil {
+		t.Fatalf("getInstanceHealth returned an error (%s)", err)
+	}
+	if !health.StateFound || !health.StateReady {
+		t.Fatalf("Expected the ResourceBundleState to be found and ready, got %+v", health)
+	}
+	if health.LiveReady {
+		t.Fatalf("Expected the instance not to be live ready, got %+v", health)
+	}
+	expectedPods := []PodHealth{{Name: "pod-a", Phase: corev1.PodFailed, StatePhase: corev1.PodRunning}}
+	if !reflect.DeepEqual(health.Pods, expectedPods) {
+		t.Fatalf("Expected pods %+v, got %+v", expectedPods, health.Pods)
+	}
+	expected := []string{
+		"Pod pod-a is Failed but the ResourceBundleState records Running",
+		"ResourceBundleState reports the instance ready but some of its pods are not",
+	}
+	if !reflect.DeepEqual(health.Discrepancies, expected) {
+		t.Fatalf("Expected discrepancies %q, got %q", expected, health.Discrepancies)
+	}
+}
+
+func TestReadyTimeout(t *testing.T) {
+	conf := config.GetConfiguration()
+	oldTimeouts := conf.ReadyTimeouts
+	defer func() {
+		conf.ReadyTimeouts = oldTimeouts
+	}()
+	conf.ReadyTimeouts = map[string]int64{
+		"Service/LoadBalancer": 900,
+		"ConfigMap":            5,
+	}
+
+	writeTemplate := func(kind, content string) helm.KubernetesResourceTemplate {
+		f, err := ioutil.TempFile("", "ready-timeout-*.yaml")
+		if err != nil {
+			t.Fatalf("Unable to create manifest (%s)", err)
+		}
+		defer f.Close()
+		if _, err = f.WriteString(content); err != nil {
+			t.Fatalf("Unable to write manifest (%s)", err)
+		}
+		return helm.KubernetesResourceTemplate{
+			GVK:      schema.GroupVersionKind{Version: "v1", Kind: kind},
+			FilePath: f.Name(),
+		}
+	}
+
+	loadBalancer := writeTemplate("Service", `apiVersion: v1
+kind: Service
+metadata:
+  name: lb
+spec:
+  type: LoadBalancer
+`)
+	clusterIP := writeTemplate("Service", `apiVersion: v1
+kind: Service
+metadata:
+  name: cip
+spec:
+  type: ClusterIP
+`)
+	configMap := writeTemplate("ConfigMap", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	for _, tmpl := range []helm.KubernetesResourceTemplate{loadBalancer, clusterIP, configMap} {
+		defer os.Remove(tmpl.FilePath)
+	}
+
+	testCases := []struct {
+		label    string
+		resTempl helm.KubernetesResourceTemplate
+		timeout  int64
+		expected int64
+	}{
+		{"Explicit timeout of a LoadBalancer Service", loadBalancer, 60, 60},
+		{"Explicit timeout of a ConfigMap", configMap, 60, 60},
+		{"LoadBalancer Service", loadBalancer, 0, 900},
+		{"ClusterIP Service", clusterIP, 0, 0},
+		{"ConfigMap", configMap, 0, 5},
+		{"Negative timeout", configMap, -1, 5},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			got := readyTimeout(testCase.resTempl, testCase.timeout)
+			if got != testCase.expected {
+				t.Fatalf("readyTimeout returned %d, expected %d", got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	k8 := KubernetesClient{
+		clusterLabels: map[string]string{"env": "staging", "region": "eu"},
+	}
+
+	testCases := []struct {
+		label         string
+		selector      string
+		expectedSkip  bool
+		expectedError string
+	}{
+		{
+			label: "Apply resource without selector",
+		},
+		{
+			label:    "Apply resource matching the cluster",
+			selector: "env in (staging,prod),region=eu",
+		},
+		{
+			label:        "Skip resource not matching the cluster",
+			selector:     "env=prod",
+			expectedSkip: true,
+		},
+		{
+			label:         "Fail on invalid selector",
+			selector:      "env in prod",
+			expectedError: "Parsing " + ClusterSelectorAnnotation,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n"
+			if testCase.selector != "" {
+				manifest += "  annotations:\n    " + ClusterSelectorAnnotation + ": \"" + testCase.selector + "\"\n"
+			}
+			f, err := ioutil.TempFile("", "skip-reason-*.yaml")
+			if err != nil {
+				t.Fatalf("Unable to create manifest (%s)", err)
+			}
+			defer os.Remove(f.Name())
+			if _, err = f.WriteString(manifest); err != nil {
+				t.Fatalf("Unable to write manifest (%s)", err)
+			}
+			f.Close()
+
+			reason, err := k8.skipReason(helm.KubernetesResourceTemplate{
+				GVK:      schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				FilePath: f.Name(),
+			})
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("skipReason returned an error (%s)", err)
+			}
+			if (reason != "") != testCase.expectedSkip {
+				t.Fatalf("skipReason returned %q, expected skip %t", reason, testCase.expectedSkip)
+			}
+		})
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	// Apiserver serving SelfSubjectReview in v1beta1 only
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost || r.URL.Path != "/apis/authentication.k8s.io/v1beta1/selfsubjectreviews" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{
+  "apiVersion": "authentication.k8s.io/v1beta1",
+  "kind": "SelfSubjectReview",
+  "status": {
+    "userInfo": {
+      "username": "system:serviceaccount:onap:k8splugin",
+      "uid": "0d3cc3ac-2bb1-4b6e-9c4f-1a5b2a6a9f7e",
+      "groups": ["system:serviceaccounts", "system:authenticated"]
+    }
+  }
+}`))
+	}))
+	defer server.Close()
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unable to create client (%s)", err)
+	}
+	k8 := KubernetesClient{clientSet: clientSet}
+
+	identity, err := k8.whoAmI()
+	if err != nil {
+		t.Fatalf("whoAmI returned an error (%s)", err)
+	}
+	expected := UserIdentity{
+		Username: "system:serviceaccount:onap:k8splugin",
+		UID:      "0d3cc3ac-2bb1-4b6e-9c4f-1a5b2a6a9f7e",
+		Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+	}
+	if !reflect.DeepEqual(identity, expected) {
+		t.Fatalf("whoAmI returned %+v, expected %+v", identity, expected)
+	}
+	expectedPaths := []string{
+		"/apis/authentication.k8s.io/v1/selfsubjectreviews",
+		"/apis/authentication.k8s.io/v1beta1/selfsubjectreviews",
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Fatalf("whoAmI requested %v, expected %v", paths, expectedPaths)
+	}
+}
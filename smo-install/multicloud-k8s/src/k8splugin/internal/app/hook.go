@@ -110,9 +110,9 @@ func (hc *HookClient) ExecHook(
 		}
 		if hook != "crd-install" {
 			//timeout <= 0 -> do not wait
-			if timeout > 0 {
+			if hookTimeout := readyTimeout(resTempl, timeout); hookTimeout > 0 {
 				// Watch hook resources until they are completed
-				err = k8sClient.WatchHookUntilReady(time.Duration(timeout)*time.Second, hc.kubeNameSpace, createdHook)
+				err = k8sClient.WatchHookUntilReady(time.Duration(hookTimeout)*time.Second, hc.kubeNameSpace, createdHook)
 				if err != nil {
 					// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
 					// under failed condition. If so, then clear the corresponding resource object in the hook
@@ -16,6 +16,7 @@ package app
 import (
 	"encoding/base64"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/connection"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/db"
@@ -261,4 +262,67 @@ func TestExecHook(t *testing.T) {
 	if err != nil {
 		t.Fatal(err.Error())
 	}
-}
\ No newline at end of file
+}
+func TestExecHookReadyTimeout(t *testing.T) {
+	hookList := generateHookList()
+	hookClient := NewHookClient("test", "test", "rbdef", "instance")
+	err := LoadMockPlugins(utils.LoadedPlugins)
+	if err != nil {
+		t.Fatalf("LoadMockPlugins returned an error (%s)", err)
+	}
+	watched := mockWatchedTimeouts(t)
+
+	conf := config.GetConfiguration()
+	oldTimeouts := conf.ReadyTimeouts
+	defer func() {
+		conf.ReadyTimeouts = oldTimeouts
+	}()
+
+	fd, err := ioutil.ReadFile("../../mock_files/mock_configs/mock_kube_config")
+	if err != nil {
+		t.Fatal("Unable to read mock_kube_config")
+	}
+	db.DBconn = &db.MockDB{
+		Items: map[string]map[string][]byte{
+			connection.ConnectionKey{CloudRegion: "mock_connection"}.String(): {
+				"metadata": []byte(
+					"{\"cloud-region\":\"mock_connection\"," +
+						"\"cloud-owner\":\"mock_owner\"," +
+						"\"kubeconfig\": \"" + base64.StdEncoding.EncodeToString(fd) + "\"}"),
+			},
+		},
+	}
+	k8sClient := KubernetesClient{}
+	err = k8sClient.Init("mock_connection", "test")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	testCases := []struct {
+		label    string
+		timeouts map[string]int64
+		timeout  int64
+		expected float64
+	}{
+		{label: "Explicit timeout", timeouts: map[string]int64{"Job": 30}, timeout: 10, expected: 10},
+		{label: "Configured timeout", timeouts: map[string]int64{"Job": 30}, timeout: 0, expected: 30},
+		{label: "No timeout", timeout: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			conf.ReadyTimeouts = testCase.timeouts
+			for kind := range watched {
+				delete(watched, kind)
+			}
+			err := hookClient.ExecHook(k8sClient, hookList, release.HookPreInstall, testCase.timeout, 0, nil)
+			if err != nil {
+				t.Fatalf("ExecHook returned an error (%s)", err)
+			}
+			got, ok := watched["Job"]
+			if ok != (testCase.expected > 0) || got.Seconds() != testCase.expected {
+				t.Fatalf("Expected the Job hooks to be watched for %vs, got %v (watched %t)", testCase.expected, got, ok)
+			}
+		})
+	}
+}
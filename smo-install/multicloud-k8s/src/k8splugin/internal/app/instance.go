@@ -25,6 +25,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -101,12 +102,31 @@ type InstanceStatus struct {
 	ResourcesStatus []ResourceStatus `json:"resourcesStatus"`
 }
 
+// InstanceStatusSummary is the readiness of an instance returned when the
+// status of several instances is queried at once. Error is set instead when
+// the status of the instance couldn't be read.
+type InstanceStatusSummary struct {
+	ID            string `json:"id"`
+	Ready         bool   `json:"ready"`
+	ResourceCount int32  `json:"resourceCount"`
+	Error         string `json:"error,omitempty"`
+}
+
+// statusSummaryWorkers bounds the instance statuses read in parallel
+const statusSummaryWorkers = 8
+
 // InstanceManager is an interface exposes the instantiation functionality
 type InstanceManager interface {
 	Create(i InstanceRequest) (InstanceResponse, error)
 	Get(id string) (InstanceResponse, error)
 	GetFull(id string) (InstanceDbData, error)
 	Status(id string) (InstanceStatus, error)
+	StatusSummaries(ids []string) []InstanceStatusSummary
+	Events(id string) ([]corev1.Event, error)
+	Export(id string) ([]byte, error)
+	ListResources(id, cursor string, limit int) (ResourcePage, error)
+	Drift(id string) (ResourceDrift, error)
+	Health(id string) (InstanceHealth, error)
 	Query(id, apiVersion, kind, name, labels string) (InstanceStatus, error)
 	List(rbname, rbversion, profilename string) ([]InstanceMiniResponse, error)
 	Find(rbName string, ver string, profile string, labelKeys map[string]string) ([]InstanceMiniResponse, error)
@@ -242,6 +262,12 @@ func (v *InstanceClient) Create(i InstanceRequest) (InstanceResponse, error) {
 		return InstanceResponse{}, pkgerrors.Wrap(err, "Getting CloudRegion Information")
 	}
 
+	err = k8sClient.validateResources(sortedTemplates, crdList)
+	if err != nil {
+		namegenerator.Release(id)
+		return InstanceResponse{}, pkgerrors.Wrap(err, "Validating Resources against Cluster")
+	}
+
 	log.Printf("Main rss info")
 	for _, t := range sortedTemplates {
 		log.Printf("  Path: %s", t.FilePath)
@@ -601,6 +627,166 @@ Main:
 	return resp, nil
 }
 
+// StatusSummaries returns the readiness of each of the given instances, in
+// the same order. Failing to read an instance doesn't fail the others.
+func (v *InstanceClient) StatusSummaries(ids []string) []InstanceStatusSummary {
+	return statusSummaries(ids, statusSummaryWorkers, v.Status)
+}
+
+// statusSummaries reads the status of the instances with at most workers
+// calls to status running at the same time
+func statusSummaries(ids []string, workers int,
+	status func(id string) (InstanceStatus, error)) []InstanceStatusSummary {
+
+	summaries := make([]InstanceStatusSummary, len(ids))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				summaries[i].ID = ids[i]
+				resp, err := status(ids[i])
+				if err != nil {
+					summaries[i].Error = err.Error()
+					continue
+				}
+				summaries[i].Ready = resp.Ready
+				summaries[i].ResourceCount = resp.ResourceCount
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return summaries
+}
+
+// Events returns the Kubernetes Events referencing the resources of the
+// instance, sorted by time
+func (v *InstanceClient) Events(id string) ([]corev1.Event, error) {
+	resResp, err := v.GetFull(id)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Get Instance")
+	}
+
+	k8sClient := KubernetesClient{}
+	err = k8sClient.Init(resResp.Request.CloudRegion, id)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Getting CloudRegion Information")
+	}
+
+	events, err := k8sClient.getEventsByLabel(resResp.Namespace)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Getting Events")
+	}
+
+	return events, nil
+}
+
+// Export returns the resources created by the instance as a multi-document
+// YAML manifest that can be applied again
+func (v *InstanceClient) Export(id string) ([]byte, error) {
+	resResp, err := v.GetFull(id)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Get Instance")
+	}
+
+	k8sClient := KubernetesClient{}
+	err = k8sClient.Init(resResp.Request.CloudRegion, id)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Getting CloudRegion Information")
+	}
+
+	manifest, err := k8sClient.exportResources(resResp.Resources, resResp.Namespace)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Exporting Resources")
+	}
+
+	return manifest, nil
+}
+
+// ListResources returns a page of the resources labeled with the instance
+// ID, across the kinds created by the instance
+func (v *InstanceClient) ListResources(id, cursor string, limit int) (ResourcePage, error) {
+	resResp, err := v.GetFull(id)
+	if err != nil {
+		return ResourcePage{}, pkgerrors.Wrap(err, "Get Instance")
+	}
+
+	k8sClient := KubernetesClient{}
+	err = k8sClient.Init(resResp.Request.CloudRegion, id)
+	if err != nil {
+		return ResourcePage{}, pkgerrors.Wrap(err, "Getting CloudRegion Information")
+	}
+
+	gvks := make([]schema.GroupVersionKind, 0)
+	seen := map[schema.GroupVersionKind]bool{}
+	for _, res := range resResp.Resources {
+		if !seen[res.GVK] {
+			seen[res.GVK] = true
+			gvks = append(gvks, res.GVK)
+		}
+	}
+
+	labelValue := config.GetConfiguration().KubernetesLabelName + "=" + id
+	page, err := k8sClient.listResourcePage(gvks, labelValue, resResp.Namespace, cursor, limit)
+	if err != nil {
+		return ResourcePage{}, pkgerrors.Wrap(err, "Listing Resources")
+	}
+
+	return page, nil
+}
+
+// Drift reports the resources created by the instance that were deleted
+// from the cluster and the labeled resources it didn't create
+func (v *InstanceClient) Drift(id string) (ResourceDrift, error) {
+	resResp, err := v.GetFull(id)
+	if err != nil {
+		return ResourceDrift{}, pkgerrors.Wrap(err, "Get Instance")
+	}
+
+	k8sClient := KubernetesClient{}
+	err = k8sClient.Init(resResp.Request.CloudRegion, id)
+	if err != nil {
+		return ResourceDrift{}, pkgerrors.Wrap(err, "Getting CloudRegion Information")
+	}
+
+	labelValue := config.GetConfiguration().KubernetesLabelName + "=" + id
+	drift, err := k8sClient.detectDrift(resResp.Resources, labelValue, resResp.Namespace)
+	if err != nil {
+		return ResourceDrift{}, pkgerrors.Wrap(err, "Detecting Drift")
+	}
+
+	return drift, nil
+}
+
+// Health returns the readiness recorded in the ResourceBundleState of the
+// instance merged with the live state of its pods
+func (v *InstanceClient) Health(id string) (InstanceHealth, error) {
+	resResp, err := v.GetFull(id)
+	if err != nil {
+		return InstanceHealth{}, pkgerrors.Wrap(err, "Get Instance")
+	}
+
+	k8sClient := KubernetesClient{}
+	err = k8sClient.Init(resResp.Request.CloudRegion, id)
+	if err != nil {
+		return InstanceHealth{}, pkgerrors.Wrap(err, "Getting CloudRegion Information")
+	}
+
+	health, err := k8sClient.getInstanceHealth(resResp.Namespace)
+	if err != nil {
+		return InstanceHealth{}, pkgerrors.Wrap(err, "Getting Instance Health")
+	}
+
+	return health, nil
+}
+
 func (v *InstanceClient) checkRssStatus(rss helm.KubernetesResource, k8sClient KubernetesClient, namespace string, status ResourceStatus) (bool, error) {
 	readyChecker := statuscheck.NewReadyChecker(k8sClient.clientSet, statuscheck.PausedAsReady(true), statuscheck.CheckJobs(true))
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(60)*time.Second)
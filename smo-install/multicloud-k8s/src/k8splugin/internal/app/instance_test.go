@@ -21,6 +21,7 @@ import (
 	"log"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/connection"
@@ -28,6 +29,7 @@ import (
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/rb"
 
+	pkgerrors "github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -891,3 +893,46 @@ func TestInstanceWithHookCreate(t *testing.T) {
 		}
 	})
 }
+
+func TestInstanceStatusSummaries(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	status := func(id string) (InstanceStatus, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		switch id {
+		case "broken":
+			return InstanceStatus{}, pkgerrors.New("Get Instance: not found")
+		case "pending":
+			return InstanceStatus{Ready: false, ResourceCount: 2}, nil
+		}
+		return InstanceStatus{Ready: true, ResourceCount: 3}, nil
+	}
+
+	ids := []string{"ready1", "broken", "pending", "ready2", "ready3"}
+	summaries := statusSummaries(ids, 2, status)
+
+	expected := []InstanceStatusSummary{
+		{ID: "ready1", Ready: true, ResourceCount: 3},
+		{ID: "broken", Error: "Get Instance: not found"},
+		{ID: "pending", Ready: false, ResourceCount: 2},
+		{ID: "ready2", Ready: true, ResourceCount: 3},
+		{ID: "ready3", Ready: true, ResourceCount: 3},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Fatalf("statusSummaries returned %+v, expected %+v", summaries, expected)
+	}
+	if maxRunning > 2 {
+		t.Fatalf("statusSummaries read %d statuses at once, expected at most 2", maxRunning)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"sync"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+)
+
+// applyLimiter bounds the number of resources applied at the same time,
+// globally and per target namespace of a cloud region, so that a busy
+// namespace can't use all the global slots and starve the others
+type applyLimiter struct {
+	global         chan struct{}
+	namespaceLimit int
+
+	sync.Mutex
+	namespaces map[namespaceKey]*namespaceSlots
+}
+
+// namespaceKey identifies a namespace of a cloud region, the same namespace
+// name in two clusters is limited separately
+type namespaceKey struct {
+	cloudRegion string
+	namespace   string
+}
+
+// namespaceSlots holds the slots of a namespace, it is removed once no apply
+// holds or waits for one
+type namespaceSlots struct {
+	slots chan struct{}
+	users int
+}
+
+// newApplyLimiter returns a limiter allowing globalLimit concurrent applies
+// overall and namespaceLimit per namespace. Limits <= 0 mean unlimited.
+func newApplyLimiter(globalLimit, namespaceLimit int) *applyLimiter {
+	l := &applyLimiter{
+		namespaceLimit: namespaceLimit,
+		namespaces:     map[namespaceKey]*namespaceSlots{},
+	}
+	if globalLimit > 0 {
+		l.global = make(chan struct{}, globalLimit)
+	}
+	return l
+}
+
+// acquire blocks until an apply to namespace of cloudRegion is allowed and
+// returns the function releasing the slot
+func (l *applyLimiter) acquire(cloudRegion, namespace string) func() {
+	key := namespaceKey{cloudRegion: cloudRegion, namespace: namespace}
+	var ns *namespaceSlots
+	if l.namespaceLimit > 0 {
+		l.Lock()
+		ns = l.namespaces[key]
+		if ns == nil {
+			ns = &namespaceSlots{slots: make(chan struct{}, l.namespaceLimit)}
+			l.namespaces[key] = ns
+		}
+		ns.users++
+		l.Unlock()
+		// Wait for the namespace slot first so that applies queued on a
+		// busy namespace don't hold global slots
+		ns.slots <- struct{}{}
+	}
+	if l.global != nil {
+		l.global <- struct{}{}
+	}
+
+	return func() {
+		if l.global != nil {
+			<-l.global
+		}
+		if ns != nil {
+			<-ns.slots
+			l.Lock()
+			ns.users--
+			if ns.users == 0 {
+				delete(l.namespaces, key)
+			}
+			l.Unlock()
+		}
+	}
+}
+
+var (
+	applyLimitsOnce sync.Once
+	applyLimits     *applyLimiter
+)
+
+// getApplyLimiter returns the limiter shared by all the Kubernetes clients
+func getApplyLimiter() *applyLimiter {
+	applyLimitsOnce.Do(func() {
+		conf := config.GetConfiguration()
+		applyLimits = newApplyLimiter(conf.MaxConcurrentApplies, conf.MaxConcurrentAppliesPerNamespace)
+	})
+	return applyLimits
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApplyLimiter(t *testing.T) {
+	limiter := newApplyLimiter(4, 2)
+
+	var mu sync.Mutex
+	active := map[string]int{}
+	maxActive := map[string]int{}
+	unblock := make(chan struct{})
+
+	apply := func(namespace string, wg *sync.WaitGroup) {
+		defer wg.Done()
+		release := limiter.acquire("region1", namespace)
+		defer release()
+
+		mu.Lock()
+		active[namespace]++
+		if active[namespace] > maxActive[namespace] {
+			maxActive[namespace] = active[namespace]
+		}
+		mu.Unlock()
+
+		<-unblock
+
+		mu.Lock()
+		active[namespace]--
+		mu.Unlock()
+	}
+
+	// Saturate the busy namespace, its applies stay blocked
+	var busy sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		busy.Add(1)
+		go apply("busy", &busy)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := active["busy"]
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Namespace busy never reached its limit")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Applies to another namespace must still get global slots
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2; i++ {
+			release := limiter.acquire("region1", "quiet")
+			release()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Applies to another namespace were starved by the busy one")
+	}
+
+	close(unblock)
+	busy.Wait()
+
+	if maxActive["busy"] != 2 {
+		t.Fatalf("Expected at most 2 concurrent applies in namespace busy, got %d", maxActive["busy"])
+	}
+	if len(limiter.namespaces) != 0 {
+		t.Fatalf("Expected idle namespaces to be dropped, got %d left", len(limiter.namespaces))
+	}
+}
+
+func TestApplyLimiterPerCloudRegion(t *testing.T) {
+	limiter := newApplyLimiter(0, 1)
+
+	// The namespace of region1 is full, the same namespace of region2 isn't
+	release := limiter.acquire("region1", "default")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		limiter.acquire("region2", "default")()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Applies to region2 were blocked by the same namespace of region1")
+	}
+	release()
+
+	if len(limiter.namespaces) != 0 {
+		t.Fatalf("Expected idle namespaces to be dropped, got %d left", len(limiter.namespaces))
+	}
+}
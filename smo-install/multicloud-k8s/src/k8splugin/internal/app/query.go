@@ -34,6 +34,7 @@ type QueryStatus struct {
 // QueryManager is an interface exposes the instantiation functionality
 type QueryManager interface {
 	Query(namespace, cloudRegion, apiVersion, kind, name, labels string) (QueryStatus, error)
+	WhoAmI(cloudRegion string) (UserIdentity, error)
 }
 
 // QueryClient implements the InstanceManager interface
@@ -106,3 +107,19 @@ func (v *QueryClient) Query(namespace, cloudRegion, apiVersion, kind, name, labe
 	}
 	return resp, nil
 }
+
+// WhoAmI returns the identity the plugin is authenticated as in the cloud region
+func (v *QueryClient) WhoAmI(cloudRegion string) (UserIdentity, error) {
+	k8sClient := KubernetesClient{}
+	err := k8sClient.Init(cloudRegion, "dummy") //we don't care about instance id in this request
+	if err != nil {
+		return UserIdentity{}, pkgerrors.Wrap(err, "Getting CloudRegion Information")
+	}
+
+	identity, err := k8sClient.whoAmI()
+	if err != nil {
+		return UserIdentity{}, pkgerrors.Wrap(err, "Getting identity")
+	}
+
+	return identity, nil
+}
@@ -39,6 +39,80 @@ type Configuration struct {
 	EtcdCAFile          string `json:"etcd-ca-file"`
 	ServicePort         string `json:"service-port"`
 	KubernetesLabelName string `json:"kubernetes-label-name"`
+	ReadOnly            bool   `json:"read-only"`
+	// AnnotationsToLabels maps annotation keys to the label keys they are
+	// promoted to on created resources. An empty label key reuses the annotation key.
+	AnnotationsToLabels map[string]string `json:"annotations-to-labels"`
+	// ExtraLabels are static labels set on created resources alongside the
+	// instance label, e.g. to identify a tenant. They never replace the
+	// instance label.
+	ExtraLabels map[string]string `json:"extra-labels"`
+	// UpdateConflictPolicy selects how Update handles immutable field
+	// conflicts: Fail, SkipImmutable or Recreate
+	UpdateConflictPolicy string `json:"update-conflict-policy"`
+	// NamespaceConflictPolicy selects how a namespace declared in a manifest
+	// that differs from the requested one is handled: Error, PreferManifest
+	// or PreferArgument
+	NamespaceConflictPolicy string `json:"namespace-conflict-policy"`
+	// FinalizerPolicy selects which finalizers declared in a manifest are
+	// kept on created resources: Allow, Strip or Allowlist. Allowlist keeps
+	// only the ones listed in AllowedFinalizers.
+	FinalizerPolicy   string   `json:"finalizer-policy"`
+	AllowedFinalizers []string `json:"allowed-finalizers"`
+	// FieldManager is the manager name recorded on objects written by the
+	// plugins. Defaults to a name derived from the instance ID when empty.
+	FieldManager string `json:"field-manager"`
+	// CleanupOrphanedEndpointSlices removes the EndpointSlices left behind
+	// by a previous incarnation of a Service after it is updated
+	CleanupOrphanedEndpointSlices bool `json:"cleanup-orphaned-endpointslices"`
+	// JSONNaming is the default key style of Definitions in the API:
+	// kebab-case, camelCase or snake_case
+	JSONNaming string `json:"json-naming"`
+	// ReadyTimeouts are the seconds to wait for resources to become ready,
+	// keyed by kind or by kind and type, e.g. "Service/LoadBalancer"
+	ReadyTimeouts map[string]int64 `json:"ready-timeouts"`
+	// ServerSideApply makes the plugins create and update resources with
+	// Server-Side Apply instead of imperative calls
+	ServerSideApply bool `json:"server-side-apply"`
+	// ForceApply takes over the ownership of fields managed by others when
+	// Server-Side Apply reports a conflict
+	ForceApply bool `json:"force-apply"`
+	// MaxConcurrentApplies bounds the resources applied at the same time,
+	// MaxConcurrentAppliesPerNamespace those applied to a same namespace.
+	// Values <= 0 disable the limit.
+	MaxConcurrentApplies             int `json:"max-concurrent-applies"`
+	MaxConcurrentAppliesPerNamespace int `json:"max-concurrent-applies-per-namespace"`
+	// MaxListedResources bounds the resources a plugin collects across the
+	// pages of a list. Values <= 0 disable the limit.
+	MaxListedResources int `json:"max-listed-resources"`
+	// MaxUploadSize bounds the size in bytes of an uploaded resource bundle
+	// tarball. Values <= 0 disable the limit.
+	MaxUploadSize int64 `json:"max-upload-size"`
+	// UpdateRetries is how many times an update failing with a conflict,
+	// because the resource changed since it was read, is retried.
+	// Values <= 0 disable the retries.
+	UpdateRetries int `json:"update-retries"`
+	// LogLevel is the minimum level of the structured logs: error, warn,
+	// info or debug
+	LogLevel string `json:"log-level"`
+	// CreateMissingNamespaces makes the service plugin create the target
+	// namespace, labeled with the instance ID, when it doesn't exist yet
+	CreateMissingNamespaces bool `json:"create-missing-namespaces"`
+	// ServiceReadyEndpoints is the number of ready endpoint addresses a
+	// Service with a selector needs to be considered ready. Values <= 0
+	// only wait for the Service to exist.
+	ServiceReadyEndpoints int `json:"service-ready-endpoints"`
+	// DeletePropagationPolicy is how the dependents of the services deleted
+	// by the service plugin are deleted: Background, Foreground or Orphan
+	DeletePropagationPolicy string `json:"delete-propagation-policy"`
+	// KubernetesQPS and KubernetesBurst are the rate of the requests to the
+	// apiserver of a cluster and the burst allowed above it. Values <= 0
+	// keep the client-go defaults.
+	KubernetesQPS   float32 `json:"kubernetes-qps"`
+	KubernetesBurst int     `json:"kubernetes-burst"`
+	// KubernetesRequestTimeout is the seconds a request to the apiserver,
+	// or a call of a plugin, may take. Values <= 0 disable the timeout.
+	KubernetesRequestTimeout int64 `json:"kubernetes-request-timeout"`
 }
 
 // Config is the structure that stores the configuration
@@ -74,19 +148,35 @@ func defaultConfiguration() *Configuration {
 	}
 
 	return &Configuration{
-		CAFile:              "ca.cert",
-		ServerCert:          "server.cert",
-		ServerKey:           "server.key",
-		Password:            "",
-		DatabaseAddress:     "127.0.0.1",
-		DatabaseType:        "mongo",
-		PluginDir:           cwd,
-		EtcdIP:              "127.0.0.1",
-		EtcdCert:            "",
-		EtcdKey:             "",
-		EtcdCAFile:          "",
-		ServicePort:         "9015",
-		KubernetesLabelName: "k8splugin.io/rb-instance-id",
+		CAFile:               "ca.cert",
+		ServerCert:           "server.cert",
+		ServerKey:            "server.key",
+		Password:             "",
+		DatabaseAddress:      "127.0.0.1",
+		DatabaseType:         "mongo",
+		PluginDir:            cwd,
+		EtcdIP:               "127.0.0.1",
+		EtcdCert:             "",
+		EtcdKey:              "",
+		EtcdCAFile:           "",
+		ServicePort:          "9015",
+		KubernetesLabelName:  "k8splugin.io/rb-instance-id",
+		ReadOnly:             false,
+		AnnotationsToLabels:  map[string]string{},
+		ExtraLabels:          map[string]string{},
+		UpdateConflictPolicy: "Fail",
+		JSONNaming:           "kebab-case",
+		ReadyTimeouts:        map[string]int64{},
+
+		MaxConcurrentApplies:             20,
+		MaxConcurrentAppliesPerNamespace: 5,
+		NamespaceConflictPolicy:          "PreferArgument",
+		FinalizerPolicy:                  "Allow",
+		MaxUploadSize:                    64 << 20,
+		UpdateRetries:                    5,
+		LogLevel:                         "info",
+		ServiceReadyEndpoints:            1,
+		DeletePropagationPolicy:          "Background",
 	}
 }
 
@@ -32,6 +32,8 @@ type Connection struct {
 	CloudOwner            string                 `json:"cloud-owner"`
 	Kubeconfig            string                 `json:"kubeconfig"`
 	OtherConnectivityList ConnectivityRecordList `json:"other-connectivity-list"`
+	// Labels describe the cluster, e.g. its environment or region
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // ConnectivityRecordList covers lists of connectivity records
@@ -177,6 +179,13 @@ func (v *ConnectionClient) Download(name string) (string, error) {
 		return "", pkgerrors.Wrap(err, "Getting Connection info")
 	}
 
+	return WriteKubeconfig(conn)
+}
+
+// WriteKubeconfig writes the kubeconfig of an already read connection onto
+// a temporary file and returns its path
+func WriteKubeconfig(conn Connection) (string, error) {
+
 	//Decode the kubeconfig from base64 to string
 	kubeContent, err := base64.StdEncoding.DecodeString(conn.Kubeconfig)
 	if err != nil {
@@ -17,6 +17,9 @@
 package db
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
 	"log"
 
 	"golang.org/x/net/context"
@@ -27,6 +30,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -45,17 +49,60 @@ type MongoCollection interface {
 		opts ...*options.FindOptions) (*mongo.Cursor, error)
 }
 
+// MongoBucket defines the subset of GridFS operations used to stream data
+// Note: This interface is defined mainly for mock testing
+type MongoBucket interface {
+	UploadFromStream(filename string, source io.Reader) (primitive.ObjectID, error)
+	OpenDownloadStream(fileID primitive.ObjectID) (io.ReadCloser, error)
+	Delete(fileID primitive.ObjectID) error
+}
+
+// gridfsBucket implements MongoBucket with a GridFS bucket
+type gridfsBucket struct {
+	bucket *gridfs.Bucket
+}
+
+func (b gridfsBucket) UploadFromStream(filename string, source io.Reader) (primitive.ObjectID, error) {
+	return b.bucket.UploadFromStream(filename, source)
+}
+
+func (b gridfsBucket) OpenDownloadStream(fileID primitive.ObjectID) (io.ReadCloser, error) {
+	stream, err := b.bucket.OpenDownloadStream(fileID)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (b gridfsBucket) Delete(fileID primitive.ObjectID) error {
+	return b.bucket.Delete(fileID)
+}
+
 // MongoStore is an implementation of the db.Store interface
 type MongoStore struct {
 	db *mongo.Database
 }
 
+// Compile time check to see if MongoStore streams data
+var _ StreamStore = &MongoStore{}
+
 // This exists only for allowing us to mock the collection object
 // for testing purposes
 var getCollection = func(coll string, m *MongoStore) MongoCollection {
 	return m.db.Collection(coll)
 }
 
+// This exists only for allowing us to mock the GridFS bucket object
+// for testing purposes. The files of coll are stored in the bucket
+// named after it.
+var getBucket = func(coll string, m *MongoStore) (MongoBucket, error) {
+	bucket, err := gridfs.NewBucket(m.db, options.GridFSBucket().SetName(coll))
+	if err != nil {
+		return nil, err
+	}
+	return gridfsBucket{bucket: bucket}, nil
+}
+
 // This exists only for allowing us to mock the DecodeBytes function
 // Mainly because we cannot construct a SingleResult struct from our
 // tests. All fields in that struct are private.
@@ -226,24 +273,43 @@ func (m *MongoStore) Read(coll string, key Key, tag string) ([]byte, error) {
 	}
 
 	c := getCollection(coll, m)
-	ctx := context.Background()
+	tagoid, err := m.findTagID(c, key, tag)
+	if err != nil {
+		return nil, err
+	}
 
+	return m.readTagObject(c, tagoid, tag)
+}
+
+// findTagID returns the objectID of the data of tag in the masterkey
+// document of key
+func (m *MongoStore) findTagID(c MongoCollection, key Key, tag string) (primitive.ObjectID, error) {
 	//Get the masterkey document based on given key
 	filter := bson.D{{"key", key}}
 	keydata, err := decodeBytes(c.FindOne(context.Background(), filter))
+	if err == mongo.ErrNoDocuments {
+		return primitive.NilObjectID, pkgerrors.Wrap(ErrNotFound, "Error finding master table")
+	}
 	if err != nil {
-		return nil, pkgerrors.Errorf("Error finding master table: %s", err.Error())
+		return primitive.NilObjectID, pkgerrors.Errorf("Error finding master table: %s", err.Error())
 	}
 
 	//Read the tag objectID from document
 	tagoid, ok := keydata.Lookup(tag).ObjectIDOK()
 	if !ok {
-		return nil, pkgerrors.Errorf("Error finding objectID for tag %s", tag)
+		return primitive.NilObjectID, pkgerrors.Wrapf(ErrNotFound, "Error finding objectID for tag %s", tag)
 	}
+	return tagoid, nil
+}
 
+// readTagObject reads the data of tag from the document with the objectID
+func (m *MongoStore) readTagObject(c MongoCollection, tagoid primitive.ObjectID, tag string) ([]byte, error) {
 	//Use tag objectID to read the data from store
-	filter = bson.D{{"_id", tagoid}}
-	tagdata, err := decodeBytes(c.FindOne(ctx, filter))
+	filter := bson.D{{"_id", tagoid}}
+	tagdata, err := decodeBytes(c.FindOne(context.Background(), filter))
+	if err == mongo.ErrNoDocuments {
+		return nil, pkgerrors.Wrapf(ErrNotFound, "Error reading object of tag %s", tag)
+	}
 	if err != nil {
 		return nil, pkgerrors.Errorf("Error reading found object: %s", err.Error())
 	}
@@ -258,18 +324,128 @@ func (m *MongoStore) Read(coll string, key Key, tag string) ([]byte, error) {
 	}
 }
 
+// WriteStream stores the data read from r in a GridFS file and links it
+// with tag in the masterkey document, replacing the data linked before
+func (m *MongoStore) WriteStream(coll string, key Key, tag string, r io.Reader) error {
+	if r == nil || !m.validateParams(coll, key, tag) {
+		return pkgerrors.New("No Data to store")
+	}
+
+	bucket, err := getBucket(coll, m)
+	if err != nil {
+		return pkgerrors.Errorf("Error opening bucket: %s", err.Error())
+	}
+	fileID, err := bucket.UploadFromStream(tag, r)
+	if err != nil {
+		return pkgerrors.Errorf("Error uploading into database: %s", err.Error())
+	}
+
+	//Add objectID of the file to masterKey document
+	//Create masterkey document if it does not exist
+	c := getCollection(coll, m)
+	filter := bson.D{{"key", key}}
+	keydata, err := decodeBytes(
+		c.FindOneAndUpdate(
+			context.Background(),
+			filter,
+			bson.D{
+				{"$set", bson.D{
+					{tag, fileID},
+				}},
+			},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)))
+	//A created masterkey document has no document before
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		bucket.Delete(fileID)
+		return pkgerrors.Errorf("Error updating master table: %s", err.Error())
+	}
+
+	//Delete the data the tag was linked with before
+	oldoid, ok := keydata.Lookup(tag).ObjectIDOK()
+	if ok && oldoid != fileID {
+		err = m.deleteTagObject(coll, oldoid)
+		if err != nil {
+			return pkgerrors.Errorf("Error deleting replaced data: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ReadStream opens a reader of the data stored for this key and for this
+// particular tag. Data written with WriteStream is streamed from its GridFS
+// file, data stored with Create is read from its document at once.
+func (m *MongoStore) ReadStream(coll string, key Key, tag string) (io.ReadCloser, error) {
+	if !m.validateParams(coll, key, tag) {
+		return nil, pkgerrors.New("Mandatory fields are missing")
+	}
+
+	c := getCollection(coll, m)
+	tagoid, err := m.findTagID(c, key, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := getBucket(coll, m)
+	if err != nil {
+		return nil, pkgerrors.Errorf("Error opening bucket: %s", err.Error())
+	}
+	stream, err := bucket.OpenDownloadStream(tagoid)
+	if err == gridfs.ErrFileNotFound {
+		value, err := m.readTagObject(c, tagoid, tag)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(value)), nil
+	}
+	if err != nil {
+		return nil, pkgerrors.Errorf("Error opening stream: %s", err.Error())
+	}
+
+	return stream, nil
+}
+
 // Helper function that deletes an object by its ID
 func (m *MongoStore) deleteObjectByID(coll string, objID primitive.ObjectID) error {
+	_, err := m.deleteDocument(coll, objID)
+	return err
+}
+
+// deleteDocument deletes the document with the ID and reports whether
+// there was one
+func (m *MongoStore) deleteDocument(coll string, objID primitive.ObjectID) (bool, error) {
 
 	c := getCollection(coll, m)
 	ctx := context.Background()
 
-	_, err := c.DeleteOne(ctx, bson.D{{"_id", objID}})
+	res, err := c.DeleteOne(ctx, bson.D{{"_id", objID}})
 	if err != nil {
-		return pkgerrors.Errorf("Error Deleting from database: %s", err.Error())
+		return false, pkgerrors.Errorf("Error Deleting from database: %s", err.Error())
 	}
 
 	log.Printf("Deleted Obj with ID %s", objID.String())
+	return res != nil && res.DeletedCount > 0, nil
+}
+
+// deleteTagObject deletes the data of a tag, stored in a document by Create
+// or in a GridFS file by WriteStream
+func (m *MongoStore) deleteTagObject(coll string, objID primitive.ObjectID) error {
+	deleted, err := m.deleteDocument(coll, objID)
+	if err != nil || deleted {
+		return err
+	}
+
+	bucket, err := getBucket(coll, m)
+	if err != nil {
+		return pkgerrors.Errorf("Error opening bucket: %s", err.Error())
+	}
+	err = bucket.Delete(objID)
+	if err != nil && err != gridfs.ErrFileNotFound {
+		return pkgerrors.Errorf("Error Deleting file from database: %s", err.Error())
+	}
 	return nil
 }
 
@@ -316,8 +492,8 @@ func (m *MongoStore) Delete(coll string, key Key, tag string) error {
 		return pkgerrors.Errorf("Error finding objectID for tag %s", tag)
 	}
 
-	//Use tag objectID to read the data from store
-	err = m.deleteObjectByID(coll, tagoid)
+	//Use tag objectID to delete the data from store
+	err = m.deleteTagObject(coll, tagoid)
 	if err != nil {
 		return pkgerrors.Errorf("Error deleting from database: %s", err.Error())
 	}
@@ -19,16 +19,41 @@ package db
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
+	"io/ioutil"
 	"reflect"
 	"strings"
 	"testing"
 
 	pkgerrors "github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Binary form of
+// {
+//	"_id" : ObjectId("5c115156777ff85654248ae1"),
+//  "key" : bson.D{{"name","testdef"},{"version","v1"}},
+//  "metadata" : ObjectId("5c115156777ff85654248ae1")
+// }
+var testMasterTable = bson.Raw{
+	'\x58', '\x00', '\x00', '\x00', '\x03', '\x6b', '\x65', '\x79',
+	'\x00', '\x27', '\x00', '\x00', '\x00', '\x02', '\x6e', '\x61',
+	'\x6d', '\x65', '\x00', '\x08', '\x00', '\x00', '\x00', '\x74',
+	'\x65', '\x73', '\x74', '\x64', '\x65', '\x66', '\x00', '\x02',
+	'\x76', '\x65', '\x72', '\x73', '\x69', '\x6f', '\x6e', '\x00',
+	'\x03', '\x00', '\x00', '\x00', '\x76', '\x31', '\x00', '\x00',
+	'\x07', '\x6d', '\x65', '\x74', '\x61', '\x64', '\x61', '\x74',
+	'\x61', '\x00', '\x5c', '\x11', '\x51', '\x56', '\x77', '\x7f',
+	'\xf8', '\x56', '\x54', '\x24', '\x8a', '\xe1', '\x07', '\x5f',
+	'\x69', '\x64', '\x00', '\x5c', '\x11', '\x51', '\x56', '\x77',
+	'\x7f', '\xf8', '\x56', '\x54', '\x24', '\x8a', '\xe1', '\x00',
+}
+
 //Implements the functions used currently in mongo.go
 type mockCollection struct {
 	Err          error
@@ -70,6 +95,45 @@ func (c *mockCollection) Find(ctx context.Context, filter interface{},
 	return c.mCursor, c.Err
 }
 
+//Implements the GridFS operations used currently in mongo.go
+type mockBucket struct {
+	Err   error
+	files map[primitive.ObjectID][]byte
+}
+
+func (b *mockBucket) UploadFromStream(filename string, source io.Reader) (primitive.ObjectID, error) {
+	if b.Err != nil {
+		return primitive.NilObjectID, b.Err
+	}
+
+	data, err := ioutil.ReadAll(source)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if b.files == nil {
+		b.files = make(map[primitive.ObjectID][]byte)
+	}
+	id := primitive.NewObjectID()
+	b.files[id] = data
+	return id, nil
+}
+
+func (b *mockBucket) OpenDownloadStream(fileID primitive.ObjectID) (io.ReadCloser, error) {
+	data, ok := b.files[fileID]
+	if !ok {
+		return nil, gridfs.ErrFileNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *mockBucket) Delete(fileID primitive.ObjectID) error {
+	if _, ok := b.files[fileID]; !ok {
+		return gridfs.ErrFileNotFound
+	}
+	delete(b.files, fileID)
+	return nil
+}
+
 func TestCreate(t *testing.T) {
 	testCases := []struct {
 		label         string
@@ -226,6 +290,7 @@ func TestRead(t *testing.T) {
 		mockColl      *mockCollection
 		bson          bson.Raw
 		expectedError string
+		notFound      bool
 		expected      []byte
 	}{
 		{
@@ -286,6 +351,20 @@ func TestRead(t *testing.T) {
 			},
 			mockColl:      &mockCollection{},
 			expectedError: "Error finding objectID",
+			notFound:      true,
+		},
+		{
+			label: "UnSuccessfull Read of entry: key not found",
+			input: map[string]interface{}{
+				"coll": "collname",
+				"key":  MockKey{Key: "keyvalue"},
+				"tag":  "metadata",
+			},
+			mockColl: &mockCollection{
+				Err: mongo.ErrNoDocuments,
+			},
+			expectedError: "Error finding master table",
+			notFound:      true,
 		},
 		{
 			label: "UnSuccessfull Read of entry",
@@ -331,6 +410,9 @@ func TestRead(t *testing.T) {
 				if !strings.Contains(string(err.Error()), testCase.expectedError) {
 					t.Fatalf("Read method returned an error (%s)", err)
 				}
+				if errors.Is(err, ErrNotFound) != testCase.notFound {
+					t.Fatalf("Read method returned an error (%s), not found expected: %v", err, testCase.notFound)
+				}
 			} else {
 				if bytes.Compare(got, testCase.expected) != 0 {
 					t.Fatalf("Read returned unexpected data: %v, expected: %v",
@@ -441,6 +523,9 @@ func TestDelete(t *testing.T) {
 			decodeBytes = func(sr *mongo.SingleResult) (bson.Raw, error) {
 				return testCase.bson, testCase.mockColl.Err
 			}
+			getBucket = func(coll string, m *MongoStore) (MongoBucket, error) {
+				return &mockBucket{}, nil
+			}
 			err := m.Delete(testCase.input["coll"].(string), testCase.input["key"].(Key),
 				testCase.input["tag"].(string))
 			if err != nil {
@@ -595,3 +680,152 @@ func TestReadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteStream(t *testing.T) {
+	oldID, _ := testMasterTable.Lookup("metadata").ObjectIDOK()
+	testCases := []struct {
+		label         string
+		bson          bson.Raw
+		mockColl      *mockCollection
+		mockBucket    *mockBucket
+		expectedError string
+	}{
+		{
+			label:      "Stream the data of a new key",
+			mockColl:   &mockCollection{Err: mongo.ErrNoDocuments},
+			mockBucket: &mockBucket{},
+		},
+		{
+			label:    "Replace the streamed data of the tag",
+			bson:     testMasterTable,
+			mockColl: &mockCollection{},
+			mockBucket: &mockBucket{
+				files: map[primitive.ObjectID][]byte{oldID: []byte("old data")},
+			},
+		},
+		{
+			label:         "UnSuccessfull upload of the data",
+			mockColl:      &mockCollection{},
+			mockBucket:    &mockBucket{Err: pkgerrors.New("DB Error")},
+			expectedError: "DB Error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			m, _ := NewMongoStore("name", &mongo.Database{})
+			getCollection = func(coll string, m *MongoStore) MongoCollection {
+				return testCase.mockColl
+			}
+			getBucket = func(coll string, m *MongoStore) (MongoBucket, error) {
+				return testCase.mockBucket, nil
+			}
+			decodeBytes = func(sr *mongo.SingleResult) (bson.Raw, error) {
+				return testCase.bson, testCase.mockColl.Err
+			}
+
+			err := m.(StreamStore).WriteStream("collname", MockKey{Key: "keyvalue"}, "metadata", strings.NewReader("new data"))
+			if err != nil {
+				if testCase.expectedError == "" || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("WriteStream method returned an error (%s)", err)
+				}
+				return
+			}
+			if testCase.expectedError != "" {
+				t.Fatalf("WriteStream method returned nil, expected error %s", testCase.expectedError)
+			}
+
+			// Only the written data is left in the bucket
+			if len(testCase.mockBucket.files) != 1 {
+				t.Fatalf("Expected one file in the bucket, got %d", len(testCase.mockBucket.files))
+			}
+			for _, data := range testCase.mockBucket.files {
+				if string(data) != "new data" {
+					t.Fatalf("WriteStream stored %q, expected %q", data, "new data")
+				}
+			}
+		})
+	}
+}
+
+func TestReadStream(t *testing.T) {
+	fileID, _ := testMasterTable.Lookup("metadata").ObjectIDOK()
+	testCases := []struct {
+		label         string
+		tag           string
+		mockColl      *mockCollection
+		mockBucket    *mockBucket
+		expectedError string
+		notFound      bool
+		expected      []byte
+	}{
+		{
+			label:    "Stream data written with WriteStream",
+			tag:      "metadata",
+			mockColl: &mockCollection{},
+			mockBucket: &mockBucket{
+				files: map[primitive.ObjectID][]byte{fileID: []byte("streamed data")},
+			},
+			expected: []byte("streamed data"),
+		},
+		{
+			label:      "Read data stored with Create",
+			tag:        "metadata",
+			mockColl:   &mockCollection{},
+			mockBucket: &mockBucket{},
+			// This is not the document because we are mocking decodeBytes
+			expected: []byte{92, 17, 81, 86, 119, 127, 248, 86, 84, 36, 138, 225},
+		},
+		{
+			label:         "UnSuccessfull Read of stream: tag not found",
+			tag:           "badtag",
+			mockColl:      &mockCollection{},
+			mockBucket:    &mockBucket{},
+			expectedError: "Error finding objectID",
+			notFound:      true,
+		},
+		{
+			label:         "UnSuccessfull Read of stream: key not found",
+			tag:           "metadata",
+			mockColl:      &mockCollection{Err: mongo.ErrNoDocuments},
+			mockBucket:    &mockBucket{},
+			expectedError: "Error finding master table",
+			notFound:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			m, _ := NewMongoStore("name", &mongo.Database{})
+			getCollection = func(coll string, m *MongoStore) MongoCollection {
+				return testCase.mockColl
+			}
+			getBucket = func(coll string, m *MongoStore) (MongoBucket, error) {
+				return testCase.mockBucket, nil
+			}
+			decodeBytes = func(sr *mongo.SingleResult) (bson.Raw, error) {
+				return testMasterTable, testCase.mockColl.Err
+			}
+
+			reader, err := m.(StreamStore).ReadStream("collname", MockKey{Key: "keyvalue"}, testCase.tag)
+			if err != nil {
+				if testCase.expectedError == "" || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("ReadStream method returned an error (%s)", err)
+				}
+				if errors.Is(err, ErrNotFound) != testCase.notFound {
+					t.Fatalf("ReadStream method returned an error (%s), not found expected: %v", err, testCase.notFound)
+				}
+				return
+			}
+			defer reader.Close()
+
+			got, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Reading the stream returned an error (%s)", err)
+			}
+			if !bytes.Equal(got, testCase.expected) {
+				t.Fatalf("ReadStream returned unexpected data: %v, expected: %v", got, testCase.expected)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@ package db
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"reflect"
 
 	pkgerrors "github.com/pkg/errors"
@@ -23,6 +25,10 @@ import (
 // DBconn interface used to talk a concrete Database connection
 var DBconn Store
 
+// ErrNotFound is returned by Read when nothing is stored for the key, or
+// for the tag of the key. It can be matched with errors.Is.
+var ErrNotFound = errors.New("Key or tag not found")
+
 // Key is an interface that will be implemented by anypackage
 // that wants to use the Store interface. This allows various
 // db backends and key types.
@@ -43,6 +49,7 @@ type Store interface {
 	Create(table string, key Key, tag string, data interface{}) error
 
 	// Reads data for a particular key with specific tag.
+	// Returns ErrNotFound if there is no data for them.
 	Read(table string, key Key, tag string) ([]byte, error)
 
 	// Update data for particular key with specific tag
@@ -56,6 +63,19 @@ type Store interface {
 	ReadAll(table string, tag string) (map[string][]byte, error)
 }
 
+// StreamStore is implemented by the stores able to write and read large
+// data as streams instead of holding it in memory
+type StreamStore interface {
+	// Writes the data read from r for key with tag, replacing the data
+	// stored for them
+	WriteStream(table string, key Key, tag string, r io.Reader) error
+
+	// Opens a reader of the data stored for key with tag, either with
+	// WriteStream or with Create. The reader must be closed.
+	// Returns ErrNotFound if there is no data for them.
+	ReadStream(table string, key Key, tag string) (io.ReadCloser, error)
+}
+
 // CreateDBClient creates the DB client
 func CreateDBClient(dbType string) error {
 	var err error
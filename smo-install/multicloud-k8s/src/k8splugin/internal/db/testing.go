@@ -14,7 +14,10 @@ limitations under the License.
 package db
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 
 	pkgerrors "github.com/pkg/errors"
 )
@@ -45,20 +48,28 @@ func (m *MockDB) Create(table string, key Key, tag string, data interface{}) err
 	if err != nil {
 		return err
 	}
-
-	d := make(map[string][]byte)
-	d[tag] = djs
+	// Strings are read back as they were stored, like in mongo
+	if str, ok := data.(string); ok {
+		djs = []byte(str)
+	}
 
 	if m.Items == nil {
 		m.Items = make(map[string]map[string][]byte)
 	}
-	m.Items[key.String()] = d
+	// Other tags stored under the same key are kept, like in mongo
+	d, ok := m.Items[key.String()]
+	if !ok {
+		d = make(map[string][]byte)
+		m.Items[key.String()] = d
+	}
+	d[tag] = djs
 
 	return m.Err
 }
 
+// Update replaces the stored data, like Create
 func (m *MockDB) Update(table string, key Key, tag string, data interface{}) error {
-	return m.Err
+	return m.Create(table, key, tag, data)
 }
 
 // MockDB uses simple JSON and not BSON
@@ -75,13 +86,34 @@ func (m *MockDB) Read(table string, key Key, tag string) ([]byte, error) {
 		return nil, m.Err
 	}
 
-	for k, v := range m.Items {
-		if k == key.String() {
-			return v[tag], nil
-		}
+	// Missing keys and tags are reported like MongoStore does
+	value, ok := m.Items[key.String()][tag]
+	if !ok {
+		return nil, pkgerrors.Wrapf(ErrNotFound, "Reading %s tag %s", key, tag)
 	}
+	return value, nil
+}
 
-	return nil, m.Err
+// WriteStream stores the data read from r, like Create stores a string
+func (m *MockDB) WriteStream(table string, key Key, tag string, r io.Reader) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return m.Create(table, key, tag, string(data))
+}
+
+// ReadStream returns a reader of the data returned by Read
+func (m *MockDB) ReadStream(table string, key Key, tag string) (io.ReadCloser, error) {
+	value, err := m.Read(table, key, tag)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(value)), nil
 }
 
 func (m *MockDB) Delete(table string, key Key, tag string) error {
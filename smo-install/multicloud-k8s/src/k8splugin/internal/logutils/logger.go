@@ -26,3 +26,19 @@ func Warn(msg string, fields Fields) {
 func Info(msg string, fields Fields) {
 	log.WithFields(log.Fields(fields)).Info(msg)
 }
+
+// Debug uses the fields provided and logs
+func Debug(msg string, fields Fields) {
+	log.WithFields(log.Fields(fields)).Debug(msg)
+}
+
+// SetLevel sets the minimum level of the logged messages, one of
+// panic, fatal, error, warn, info, debug or trace
+func SetLevel(level string) error {
+	l, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(l)
+	return nil
+}
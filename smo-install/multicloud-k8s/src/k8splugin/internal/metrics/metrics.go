@@ -0,0 +1,72 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Result label values of the plugin operations
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Registry holds the metrics of the k8splugin, it is served by Handler
+var Registry = prometheus.NewRegistry()
+
+var (
+	pluginOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k8splugin_plugin_operations_total",
+		Help: "Plugin operations per operation, resource kind and result",
+	}, []string{"operation", "kind", "result"})
+
+	pluginOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "k8splugin_plugin_operation_duration_seconds",
+		Help:    "Duration of the plugin operations per operation, resource kind and result",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"operation", "kind", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		pluginOperations,
+		pluginOperationDuration,
+	)
+}
+
+// ObservePluginOperation records an operation, e.g. "create", made by a
+// plugin on a resource kind since start. The result is taken from *err so
+// that it can be deferred by methods with a named error result:
+//
+//	defer metrics.ObservePluginOperation("create", "Service", time.Now(), &err)
+func ObservePluginOperation(operation, kind string, start time.Time, err *error) {
+	result := ResultSuccess
+	if err != nil && *err != nil {
+		result = ResultError
+	}
+	pluginOperations.WithLabelValues(operation, kind, result).Inc()
+	pluginOperationDuration.WithLabelValues(operation, kind, result).Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the metrics of Registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
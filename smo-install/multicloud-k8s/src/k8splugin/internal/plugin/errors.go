@@ -0,0 +1,164 @@
+/*
+ * Copyright © 2021 Nokia Bell Labs.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Errors returned by the plugins, they can be matched with errors.Is
+// whatever message they are wrapped with
+var (
+	// ErrDecode is returned when the manifest cannot be decoded
+	ErrDecode = errors.New("decode error")
+	// ErrWrongResourceType is returned when the manifest holds a resource
+	// of another kind than the one handled by the plugin
+	ErrWrongResourceType = errors.New("wrong resource type")
+	// ErrNotFound is returned when the resource doesn't exist
+	ErrNotFound = errors.New("resource not found")
+	// ErrAlreadyExists is returned when the resource to create exists
+	ErrAlreadyExists = errors.New("resource already exists")
+	// ErrImmutableField is returned when an update changes a field that
+	// cannot change once the resource is created
+	ErrImmutableField = errors.New("immutable field")
+)
+
+// kindError is an error matching one of the plugin error values while
+// keeping the message and the cause of the original error
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e kindError) Error() string {
+	return e.err.Error()
+}
+
+// Is reports whether target is the plugin error value of e
+func (e kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Unwrap and Cause return the original error, for errors.Is/As and
+// pkgerrors.Cause respectively
+func (e kindError) Unwrap() error {
+	return e.err
+}
+
+func (e kindError) Cause() error {
+	return e.err
+}
+
+// WithKind marks err as one of the plugin error values
+func WithKind(err error, kind error) error {
+	if err == nil {
+		return nil
+	}
+	return kindError{kind: kind, err: err}
+}
+
+// WrapAPIError wraps an error returned by the Kubernetes API with message.
+// Not found and already exists errors are marked as ErrNotFound and
+// ErrAlreadyExists so that callers don't depend on the client library.
+func WrapAPIError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	wrapped := pkgerrors.Wrap(err, message)
+	switch {
+	case k8serrors.IsNotFound(err):
+		return WithKind(wrapped, ErrNotFound)
+	case k8serrors.IsAlreadyExists(err):
+		return WithKind(wrapped, ErrAlreadyExists)
+	}
+	return wrapped
+}
+
+// KindMismatchError is returned by AssertKind when an object is not of the
+// kind handled by a plugin. It matches ErrWrongResourceType with errors.Is,
+// the kinds can be read with errors.As.
+type KindMismatchError struct {
+	Expected schema.GroupVersionKind
+	Actual   schema.GroupVersionKind
+}
+
+func (e *KindMismatchError) Error() string {
+	return fmt.Sprintf("%s is not the expected %s", formatGVK(e.Actual), formatGVK(e.Expected))
+}
+
+// Is reports whether target is ErrWrongResourceType
+func (e *KindMismatchError) Is(target error) bool {
+	return target == ErrWrongResourceType
+}
+
+// ClusterIPTransitionError is returned when an update turns a service into
+// a headless one, with clusterIP None, or a headless service into one with
+// a clusterIP. The apiserver doesn't allow it, the service has to be deleted
+// and created again. It matches ErrImmutableField with errors.Is.
+type ClusterIPTransitionError struct {
+	Name string
+	// From and To are the live and desired clusterIP, To is empty when the
+	// clusterIP is left to the apiserver to allocate
+	From string
+	To   string
+}
+
+func (e *ClusterIPTransitionError) Error() string {
+	to := e.To
+	if to == "" {
+		to = "an allocated IP"
+	}
+	return fmt.Sprintf("service %s cannot change its clusterIP from %s to %s: the clusterIP is immutable, "+
+		"delete and recreate the service or set the %s update conflict policy", e.Name, e.From, to, UpdateConflictRecreate)
+}
+
+// Is reports whether target is ErrImmutableField
+func (e *ClusterIPTransitionError) Is(target error) bool {
+	return target == ErrImmutableField
+}
+
+// formatGVK returns a GroupVersionKind the way it is written in manifests,
+// e.g. "apps/v1 Deployment"
+func formatGVK(gvk schema.GroupVersionKind) string {
+	if gvk.Empty() {
+		return "unknown kind"
+	}
+	return gvk.GroupVersion().String() + " " + gvk.Kind
+}
+
+// AssertKind returns a *KindMismatchError unless obj is of the expected
+// kind. Objects decoded from manifests carry their kind, the one of other
+// typed objects is looked up in the client-go scheme.
+func AssertKind(obj runtime.Object, expected schema.GroupVersionKind) error {
+	actual := obj.GetObjectKind().GroupVersionKind()
+	if actual.Empty() {
+		if kinds, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(kinds) > 0 {
+			actual = kinds[0]
+		}
+	}
+	if actual != expected {
+		return &KindMismatchError{Expected: expected, Actual: actual}
+	}
+	return nil
+}
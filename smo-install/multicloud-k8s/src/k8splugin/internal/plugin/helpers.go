@@ -18,6 +18,8 @@
 package plugin
 
 import (
+	"context"
+	"encoding/json"
 	"k8s.io/client-go/rest"
 	"log"
 	"strings"
@@ -29,10 +31,15 @@ import (
 
 	pkgerrors "github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
@@ -57,27 +64,52 @@ type KubernetesConnector interface {
 	GetInstanceID() string
 }
 
-// Reference is the interface that is implemented
+// DryRunConnector is implemented by connectors whose writes must only be
+// validated by the apiserver without being persisted, e.g. to plan the
+// changes of a bundle before applying it
+type DryRunConnector interface {
+	//DryRun reports whether create and update requests are dry runs
+	DryRun() bool
+}
+
+// OwnerConnector is implemented by connectors whose resources are owned by
+// another object, e.g. the ResourceBundleState of the instance, so that
+// Kubernetes garbage collects them once the owner is deleted
+type OwnerConnector interface {
+	//GetOwnerReference returns the owner of the created resources, or nil
+	GetOwnerReference() *metaV1.OwnerReference
+	//GetOwnerNamespace returns the namespace of the owner, empty when it is
+	//cluster scoped
+	GetOwnerNamespace() string
+}
+
+// Reference is the interface that is implemented.
+// The context of each method is passed to the requests made to Kubernetes,
+// cancelling it or reaching its deadline stops the operation.
 type Reference interface {
 	//Create a kubernetes resource described by the yaml in yamlFilePath
-	Create(yamlFilePath string, namespace string, client KubernetesConnector) (string, error)
+	Create(ctx context.Context, yamlFilePath string, namespace string, client KubernetesConnector) (string, error)
 
 	//Get a kubernetes resource based on the groupVersionKind and resourceName provided in resource
-	Get(resource helm.KubernetesResource, namespace string, client KubernetesConnector) (string, error)
+	Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) (string, error)
 
 	//List all resources of the specified GroupVersionKind in the given namespace
 	//If gvk is empty, the plugin will return all supported objects in the namespace
-	List(gvk schema.GroupVersionKind, namespace string, client KubernetesConnector) ([]helm.KubernetesResource, error)
+	List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client KubernetesConnector) ([]helm.KubernetesResource, error)
 
 	//Delete a kubernetes resource described in the provided namespace
-	Delete(resource helm.KubernetesResource, namespace string, client KubernetesConnector) error
+	Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) error
 
 	//Update kubernetes resource based on the groupVersionKind and resourceName provided in resource
-	Update(yamlFilePath string, namespace string, client KubernetesConnector) (string, error)
+	Update(ctx context.Context, yamlFilePath string, namespace string, client KubernetesConnector) (string, error)
 
+	//Patch a kubernetes resource with a patch of the given type, e.g. a JSON merge patch
+	Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+		namespace string, client KubernetesConnector) (string, error)
 
 	//WatchUntilReady a kubernetes resource until it's ready
-	WatchUntilReady(timeout time.Duration,
+	WatchUntilReady(ctx context.Context,
+		timeout time.Duration,
 		ns string,
 		res helm.KubernetesResource,
 		mapper meta.RESTMapper,
@@ -86,12 +118,67 @@ type Reference interface {
 		clientSet kubernetes.Interface) error
 }
 
+// ProgressFunc receives the intermediate steps reported while waiting for a
+// resource, e.g. the reason and message of its events
+type ProgressFunc func(reason, message string)
+
+// ProgressWatcher is implemented by plugins that can report progress while
+// waiting for a resource to become ready
+type ProgressWatcher interface {
+	//WatchUntilReadyWithProgress waits like WatchUntilReady and calls
+	//progress for each step observed during the wait
+	WatchUntilReadyWithProgress(ctx context.Context,
+		timeout time.Duration,
+		ns string,
+		res helm.KubernetesResource,
+		clientSet kubernetes.Interface,
+		progress ProgressFunc) error
+}
+
+// DeletionWatcher is implemented by plugins that can wait for a deleted
+// resource to be removed, e.g. once its finalizers ran
+type DeletionWatcher interface {
+	//WatchUntilDeleted returns once the resource doesn't exist anymore or
+	//its deletion is observed, and a timeout error otherwise
+	WatchUntilDeleted(ctx context.Context,
+		timeout time.Duration,
+		ns string,
+		res helm.KubernetesResource,
+		clientSet kubernetes.Interface) error
+}
+
+// BytesCreator is implemented by plugins that can create resources from a
+// manifest held in memory, without writing it to a file first
+type BytesCreator interface {
+	//CreateFromBytes creates the kubernetes resources described by the
+	//JSON or YAML manifest
+	CreateFromBytes(ctx context.Context, manifest []byte, namespace string, client KubernetesConnector) (string, error)
+}
+
+// RawGetter is implemented by plugins that can return the whole live object
+// rather than only its name
+type RawGetter interface {
+	//GetRaw returns the JSON encoded object described by resource
+	GetRaw(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) ([]byte, error)
+}
+
+// kindPlugins maps the kinds handled by a plugin that is not named after
+// them to the name of that plugin
+var kindPlugins = map[string]string{
+	"role":        "rbac",
+	"rolebinding": "rbac",
+}
+
 // GetPluginByKind returns a plugin by the kind name
 // If plugin does not exist, it will return the generic plugin
 // TODO: Change this once we have a plugin registration mechanism
 func GetPluginByKind(kind string) (Reference, error) {
 
-	typePlugin, ok := utils.LoadedPlugins[strings.ToLower(kind)]
+	name := strings.ToLower(kind)
+	if pluginName, ok := kindPlugins[name]; ok {
+		name = pluginName
+	}
+	typePlugin, ok := utils.LoadedPlugins[name]
 	if !ok {
 		log.Println("No plugin for kind " + kind + " found. Using generic Plugin")
 		typePlugin, ok = utils.LoadedPlugins["generic"]
@@ -111,7 +198,7 @@ func GetPluginByKind(kind string) (Reference, error) {
 		return nil, pkgerrors.New("ExportedVariable does not implement plugins.Reference interface type")
 	}
 
-	return pluginImpl, nil
+	return WithTracing(kind, pluginImpl), nil
 }
 
 // TagPodsIfPresent finds the PodTemplateSpec from any workload
@@ -139,15 +226,427 @@ func TagPodsIfPresent(unstruct *unstructured.Unstructured, tag string) {
 		return
 	}
 
-	labels := podTemplateSpec.GetLabels()
-	if labels == nil {
-		labels = map[string]string{}
-	}
-	labels[config.GetConfiguration().KubernetesLabelName] = tag
-	podTemplateSpec.SetLabels(labels)
+	SetInstanceLabel(podTemplateSpec, tag)
 
 	updatedTemplate, err := runtime.DefaultUnstructuredConverter.ToUnstructured(podTemplateSpec)
 
 	//Set the label
 	spec["template"] = updatedTemplate
 }
+
+// ValidateAPIVersions checks the apiVersion/kind of every template against
+// the API resources discovered on the cluster behind client.
+// It returns the templates that the cluster does not serve, so that a bundle
+// can be rejected before any of its resources are applied.
+// When the cluster reports no API resources at all, there is nothing to
+// validate against and no template is reported.
+func ValidateAPIVersions(templates []helm.KubernetesResourceTemplate,
+	client KubernetesConnector) ([]helm.KubernetesResourceTemplate, error) {
+
+	_, resLists, err := client.GetStandardClient().Discovery().ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, pkgerrors.Wrap(err, "Discovering cluster API resources")
+	}
+	if len(resLists) == 0 {
+		log.Printf("Cluster reported no API resources, skipping the API version validation")
+		return nil, nil
+	}
+
+	//Index the served kinds by groupVersion
+	served := map[string]map[string]bool{}
+	for _, resList := range resLists {
+		kinds := map[string]bool{}
+		for _, res := range resList.APIResources {
+			kinds[res.Kind] = true
+		}
+		served[resList.GroupVersion] = kinds
+	}
+
+	var unsupported []helm.KubernetesResourceTemplate
+	for _, templ := range templates {
+		gv := templ.GVK.GroupVersion().String()
+		if !served[gv][templ.GVK.Kind] {
+			log.Printf("Kind %s is not served by the cluster in %s", templ.GVK.Kind, gv)
+			unsupported = append(unsupported, templ)
+		}
+	}
+
+	return unsupported, nil
+}
+
+// SetInstanceLabel labels obj with the instance ID under the configured
+// KubernetesLabelName. A different value already set on obj, e.g. by the
+// manifest, is kept and a warning is logged: the resource is then not
+// tracked as part of the instance.
+func SetInstanceLabel(obj metaV1.Object, instanceID string) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	labels := obj.GetLabels()
+	//Check if labels exist for this object
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	current, ok := labels[labelName]
+	if ok && current != "" && current != instanceID {
+		log.Printf("Warning: %s %s keeps its label %s=%s, it is not tracked by instance %s",
+			kindName(obj), obj.GetName(), labelName, current, instanceID)
+		return
+	}
+	labels[labelName] = instanceID
+	obj.SetLabels(labels)
+}
+
+// SetOwnerReference adds the owner of the client to the owner references of
+// obj when the client is an OwnerConnector with an owner. A reference to the
+// same owner, e.g. from the manifest, is replaced. namespace is the one obj
+// is applied in, empty when obj is cluster scoped. A namespaced owner can
+// only own objects of its namespace, other objects are left without it.
+func SetOwnerReference(obj metaV1.Object, namespace string, client KubernetesConnector) {
+	ownerConnector, ok := client.(OwnerConnector)
+	if !ok {
+		return
+	}
+	owner := ownerConnector.GetOwnerReference()
+	if owner == nil {
+		return
+	}
+	if ownerNamespace := ownerConnector.GetOwnerNamespace(); ownerNamespace != "" && ownerNamespace != namespace {
+		return
+	}
+
+	references := obj.GetOwnerReferences()
+	for i, reference := range references {
+		if reference.UID == owner.UID {
+			references[i] = *owner
+			obj.SetOwnerReferences(references)
+			return
+		}
+	}
+	obj.SetOwnerReferences(append(references, *owner))
+}
+
+// kindName returns the kind of obj for the logs, or "Object" when it is
+// not set
+func kindName(obj metaV1.Object) string {
+	if typed, ok := obj.(runtime.Object); ok {
+		if kind := typed.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+			return kind
+		}
+	}
+	return "Object"
+}
+
+// SetExtraLabels sets the labels configured in ExtraLabels on obj, over the
+// values of the manifest. A label named like the instance label is skipped,
+// and so are invalid label keys and values.
+func SetExtraLabels(obj metaV1.Object) {
+	extraLabels := config.GetConfiguration().ExtraLabels
+	if len(extraLabels) == 0 {
+		return
+	}
+
+	labelName := config.GetConfiguration().KubernetesLabelName
+	labels := obj.GetLabels()
+	//Check if labels exist for this object
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	for key, value := range extraLabels {
+		if key == labelName {
+			log.Printf("Extra label %s can't replace the instance label", key)
+			continue
+		}
+		errs := append(validation.IsQualifiedName(key), validation.IsValidLabelValue(value)...)
+		if len(errs) > 0 {
+			log.Printf("Extra label %s=%s can't be set: %s", key, value, strings.Join(errs, "; "))
+			continue
+		}
+		labels[key] = value
+	}
+	obj.SetLabels(labels)
+}
+
+// PromoteAnnotationsToLabels mirrors the annotations configured in
+// AnnotationsToLabels to labels on obj, so that label based policy
+// selectors match resources whose manifests only carry annotations.
+// Annotation values that are not valid label values are skipped.
+func PromoteAnnotationsToLabels(obj metaV1.Object) {
+	mapping := config.GetConfiguration().AnnotationsToLabels
+	if len(mapping) == 0 {
+		return
+	}
+
+	annotations := obj.GetAnnotations()
+	labels := obj.GetLabels()
+	//Check if labels exist for this object
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	for annotationKey, labelKey := range mapping {
+		value, ok := annotations[annotationKey]
+		if !ok {
+			continue
+		}
+		if labelKey == "" {
+			labelKey = annotationKey
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			log.Printf("Annotation %s can't be promoted to label %s: %s",
+				annotationKey, labelKey, strings.Join(errs, "; "))
+			continue
+		}
+		labels[labelKey] = value
+	}
+	obj.SetLabels(labels)
+}
+
+// Policies applied by the plugins when an Update is rejected because it
+// changes immutable fields. See Configuration.UpdateConflictPolicy.
+const (
+	// UpdateConflictFail returns the apiserver error to the caller
+	UpdateConflictFail = "Fail"
+	// UpdateConflictSkipImmutable keeps the live value of the conflicting
+	// fields and retries the update with the remaining changes
+	UpdateConflictSkipImmutable = "SkipImmutable"
+	// UpdateConflictRecreate deletes the live object and creates it again
+	UpdateConflictRecreate = "Recreate"
+)
+
+// ImmutableFieldPaths returns the paths of the fields that the apiserver
+// reported as immutable when rejecting an update, or nil if err is not
+// such a rejection
+func ImmutableFieldPaths(err error) []string {
+	if !k8serrors.IsInvalid(err) {
+		return nil
+	}
+	status, ok := pkgerrors.Cause(err).(k8serrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+
+	var paths []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Field != "" && strings.Contains(cause.Message, "immutable") {
+			paths = append(paths, cause.Field)
+		}
+	}
+	return paths
+}
+
+// CopyFieldPaths overwrites the fields at the given dotted paths in dst
+// with their values in src. Fields missing from src are removed from dst.
+// A path that indexes into a list, e.g. spec.ipFamilies[0], copies the
+// whole list.
+func CopyFieldPaths(dst, src runtime.Object, paths []string) error {
+	srcMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(src)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Converting source object")
+	}
+	dstMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dst)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Converting destination object")
+	}
+
+	for _, path := range paths {
+		if i := strings.Index(path, "["); i >= 0 {
+			path = path[:i]
+		}
+		fields := strings.Split(path, ".")
+		value, found, err := unstructured.NestedFieldCopy(srcMap, fields...)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "Reading field %s", path)
+		}
+		if !found {
+			unstructured.RemoveNestedField(dstMap, fields...)
+			continue
+		}
+		if err := unstructured.SetNestedField(dstMap, value, fields...); err != nil {
+			return pkgerrors.Wrapf(err, "Setting field %s", path)
+		}
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(dstMap, dst)
+}
+
+// ConvertVersion converts in into out, another version of the same kind.
+// It is meant for kinds whose fields didn't change between the versions,
+// e.g. batch/v1beta1 and batch/v1 CronJobs, the fields are copied through
+// their JSON form. The apiVersion and kind of out are set to gvk.
+func ConvertVersion(in, out runtime.Object, gvk schema.GroupVersionKind) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Marshal object")
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return pkgerrors.Wrapf(err, "Convert object to %s", gvk.GroupVersion())
+	}
+	out.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// Policies applied by the plugins when the namespace declared in a manifest
+// differs from the one passed by the caller.
+// See Configuration.NamespaceConflictPolicy.
+const (
+	// NamespaceConflictError rejects the resource
+	NamespaceConflictError = "Error"
+	// NamespaceConflictPreferManifest keeps the namespace of the manifest
+	NamespaceConflictPreferManifest = "PreferManifest"
+	// NamespaceConflictPreferArgument overwrites the namespace of the manifest
+	NamespaceConflictPreferArgument = "PreferArgument"
+)
+
+// ResolveNamespace sets and returns the namespace obj is created in. An
+// empty namespace defaults to the one of the manifest, then to "default".
+// A conflict between both is handled by the configured policy.
+func ResolveNamespace(obj metaV1.Object, namespace string) (string, error) {
+	declared := obj.GetNamespace()
+	switch {
+	case namespace == "" && declared == "":
+		namespace = "default"
+	case namespace == "":
+		namespace = declared
+	case declared != "" && declared != namespace:
+		switch config.GetConfiguration().NamespaceConflictPolicy {
+		case NamespaceConflictError:
+			return "", pkgerrors.Errorf("%s declares namespace %s, conflicting with namespace %s",
+				obj.GetName(), declared, namespace)
+		case NamespaceConflictPreferManifest:
+			namespace = declared
+		}
+	}
+
+	obj.SetNamespace(namespace)
+	return namespace, nil
+}
+
+// DeletePropagation returns the configured propagation policy of deletions,
+// Background when none is set. See Configuration.DeletePropagationPolicy.
+func DeletePropagation() (metaV1.DeletionPropagation, error) {
+	policy := metaV1.DeletionPropagation(config.GetConfiguration().DeletePropagationPolicy)
+	switch policy {
+	case "":
+		return metaV1.DeletePropagationBackground, nil
+	case metaV1.DeletePropagationBackground, metaV1.DeletePropagationForeground, metaV1.DeletePropagationOrphan:
+		return policy, nil
+	}
+	return "", pkgerrors.Errorf("Invalid delete propagation policy %q, expected %s, %s or %s", policy,
+		metaV1.DeletePropagationBackground, metaV1.DeletePropagationForeground, metaV1.DeletePropagationOrphan)
+}
+
+// Finalizer policies selecting the manifest finalizers kept on created
+// resources. See Configuration.FinalizerPolicy.
+const (
+	// FinalizerPolicyAllow keeps all the finalizers of the manifest
+	FinalizerPolicyAllow = "Allow"
+	// FinalizerPolicyStrip removes all the finalizers of the manifest
+	FinalizerPolicyStrip = "Strip"
+	// FinalizerPolicyAllowlist keeps only the finalizers in AllowedFinalizers
+	FinalizerPolicyAllowlist = "Allowlist"
+)
+
+// FilterFinalizers removes the finalizers of obj rejected by the configured
+// policy, so that a manifest can't declare finalizers that no controller
+// clears and that block the deletion of the resource.
+func FilterFinalizers(obj metaV1.Object) {
+	finalizers := obj.GetFinalizers()
+	if len(finalizers) == 0 {
+		return
+	}
+
+	conf := config.GetConfiguration()
+	switch conf.FinalizerPolicy {
+	case FinalizerPolicyStrip:
+		log.Printf("Removing finalizers %v from %s", finalizers, obj.GetName())
+		obj.SetFinalizers(nil)
+	case FinalizerPolicyAllowlist:
+		allowed := map[string]bool{}
+		for _, f := range conf.AllowedFinalizers {
+			allowed[f] = true
+		}
+		var kept []string
+		for _, f := range finalizers {
+			if !allowed[f] {
+				log.Printf("Removing finalizer %s from %s", f, obj.GetName())
+				continue
+			}
+			kept = append(kept, f)
+		}
+		obj.SetFinalizers(kept)
+	}
+}
+
+// ServiceInfo is a listed Service along with the addressing details that
+// would otherwise take a Get per Service
+type ServiceInfo struct {
+	helm.KubernetesResource
+	Type      corev1.ServiceType   `json:"type"`
+	ClusterIP string               `json:"clusterIP"`
+	Ports     []corev1.ServicePort `json:"ports"`
+}
+
+// DeleteResult is the outcome of the deletion of one resource of a
+// collection. Error is set when the resource was not deleted.
+type DeleteResult struct {
+	Resource helm.KubernetesResource `json:"resource"`
+	Deleted  bool                    `json:"deleted"`
+	Error    string                  `json:"error,omitempty"`
+}
+
+// CreateResult is the outcome of the creation of the resources of one
+// manifest of a batch. Name lists the resources created from the manifest,
+// Error is set when some of them were not. RolledBack is set when the
+// resources were deleted again because another manifest of the batch failed.
+type CreateResult struct {
+	Manifest   string `json:"manifest"`
+	Name       string `json:"name,omitempty"`
+	Created    bool   `json:"created"`
+	RolledBack bool   `json:"rolledBack,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// InstanceLabelPatch returns a JSON merge patch setting the instance label,
+// to restore it after a patch removed or changed it
+func InstanceLabelPatch(client KubernetesConnector) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{
+				config.GetConfiguration().KubernetesLabelName: client.GetInstanceID(),
+			},
+		},
+	})
+}
+
+// FieldManager returns the field manager name to send with create, update
+// and patch requests made on behalf of the instance
+func FieldManager(client KubernetesConnector) string {
+	if name := config.GetConfiguration().FieldManager; name != "" {
+		return name
+	}
+	if client.GetInstanceID() == "" {
+		return "k8splugin"
+	}
+	return "k8splugin-" + client.GetInstanceID()
+}
+
+// WithRequestTimeout returns a context bounding a call of a plugin to the
+// configured KubernetesRequestTimeout, ctx itself when it is disabled. The
+// returned function releases the context and must be called.
+func WithRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := config.GetConfiguration().KubernetesRequestTimeout
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+}
+
+// DryRun returns the dry run option to send with create and update requests,
+// metaV1.DryRunAll when the client is a DryRunConnector asking for dry runs
+func DryRun(client KubernetesConnector) []string {
+	if dryRunner, ok := client.(DryRunConnector); ok && dryRunner.DryRun() {
+		return []string{metaV1.DryRunAll}
+	}
+	return nil
+}
@@ -17,12 +17,27 @@
 package plugin
 
 import (
+	"bytes"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+	"log"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
+	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
 )
 
 func TestTagPodsIfPresent(t *testing.T) {
@@ -81,3 +96,258 @@ func TestTagPodsIfPresent(t *testing.T) {
     })
   }
 }
+
+type testKubernetesConnector struct {
+	clientSet  *fake.Clientset
+	instanceID string
+}
+
+func (t testKubernetesConnector) GetMapper() meta.RESTMapper {
+	return nil
+}
+
+func (t testKubernetesConnector) GetDynamicClient() dynamic.Interface {
+	return nil
+}
+
+func (t testKubernetesConnector) GetStandardClient() kubernetes.Interface {
+	return t.clientSet
+}
+
+func (t testKubernetesConnector) GetInstanceID() string {
+	return t.instanceID
+}
+
+func TestValidateAPIVersions(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	clientSet.Resources = []*metaV1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metaV1.APIResource{
+				{Name: "services", Kind: "Service", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metaV1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			},
+		},
+	}
+	client := testKubernetesConnector{clientSet: clientSet}
+
+	templates := []helm.KubernetesResourceTemplate{
+		{
+			GVK:      schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
+			FilePath: "../../mock_files/mock_yamls/service.yaml",
+		},
+		{
+			GVK:      schema.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"},
+			FilePath: "../../mock_files/mock_yamls/deployment.yaml",
+		},
+		{
+			GVK:      schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			FilePath: "../../mock_files/mock_yamls/deployment.yaml",
+		},
+	}
+
+	unsupported, err := ValidateAPIVersions(templates, client)
+	if err != nil {
+		t.Fatalf("ValidateAPIVersions returned an unexpected error (%s)", err)
+	}
+	if len(unsupported) != 1 {
+		t.Fatalf("ValidateAPIVersions returned %d unsupported resources, expected 1", len(unsupported))
+	}
+	if unsupported[0].GVK != templates[1].GVK {
+		t.Fatalf("ValidateAPIVersions returned %v, expected %v", unsupported[0].GVK, templates[1].GVK)
+	}
+}
+
+func TestValidateAPIVersionsNoDiscovery(t *testing.T) {
+	client := testKubernetesConnector{clientSet: fake.NewSimpleClientset()}
+
+	templates := []helm.KubernetesResourceTemplate{
+		{
+			GVK:      schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
+			FilePath: "../../mock_files/mock_yamls/service.yaml",
+		},
+	}
+
+	unsupported, err := ValidateAPIVersions(templates, client)
+	if err != nil {
+		t.Fatalf("ValidateAPIVersions returned an unexpected error (%s)", err)
+	}
+	if len(unsupported) != 0 {
+		t.Fatalf("ValidateAPIVersions returned %v, expected no unsupported resources", unsupported)
+	}
+}
+
+func TestCopyFieldPaths(t *testing.T) {
+	dst := &coreV1.Service{Spec: coreV1.ServiceSpec{
+		ClusterIP:  "fd00::10",
+		IPFamilies: []coreV1.IPFamily{coreV1.IPv6Protocol},
+	}}
+	src := &coreV1.Service{Spec: coreV1.ServiceSpec{
+		ClusterIP:  "10.96.0.10",
+		IPFamilies: []coreV1.IPFamily{coreV1.IPv4Protocol, coreV1.IPv6Protocol},
+	}}
+
+	err := CopyFieldPaths(dst, src, []string{"spec.clusterIP", "spec.ipFamilies[0]"})
+	if err != nil {
+		t.Fatalf("CopyFieldPaths returned an error (%s)", err)
+	}
+	if dst.Spec.ClusterIP != "10.96.0.10" {
+		t.Fatalf("Expected clusterIP 10.96.0.10, got %s", dst.Spec.ClusterIP)
+	}
+	if len(dst.Spec.IPFamilies) != 2 || dst.Spec.IPFamilies[0] != coreV1.IPv4Protocol {
+		t.Fatalf("Expected ipFamilies [IPv4 IPv6], got %v", dst.Spec.IPFamilies)
+	}
+}
+
+func TestConvertVersion(t *testing.T) {
+	in := &batchV1beta1.CronJob{
+		TypeMeta:   metaV1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+		ObjectMeta: metaV1.ObjectMeta{Name: "cleanup", Labels: map[string]string{"app": "cleanup"}},
+		Spec:       batchV1beta1.CronJobSpec{Schedule: "0 3 * * *"},
+	}
+	out := &batchV1.CronJob{}
+
+	err := ConvertVersion(in, out, batchV1.SchemeGroupVersion.WithKind("CronJob"))
+	if err != nil {
+		t.Fatalf("ConvertVersion returned an error (%s)", err)
+	}
+	if out.APIVersion != "batch/v1" || out.Kind != "CronJob" {
+		t.Fatalf("Expected a batch/v1 CronJob, got %s %s", out.APIVersion, out.Kind)
+	}
+	if out.Name != "cleanup" || out.Labels["app"] != "cleanup" || out.Spec.Schedule != "0 3 * * *" {
+		t.Fatalf("Expected the fields to be converted, got %+v", out)
+	}
+}
+
+func TestSetInstanceLabel(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label           string
+		labels          map[string]string
+		expectedValue   string
+		expectedWarning bool
+	}{
+		{
+			label:         "Label an object without labels",
+			expectedValue: "inst1",
+		},
+		{
+			label:         "Keep the other labels",
+			labels:        map[string]string{"app": "db"},
+			expectedValue: "inst1",
+		},
+		{
+			label:         "Label already set by this instance",
+			labels:        map[string]string{labelName: "inst1"},
+			expectedValue: "inst1",
+		},
+		{
+			label:         "Fill an empty label",
+			labels:        map[string]string{labelName: ""},
+			expectedValue: "inst1",
+		},
+		{
+			label:           "Keep a conflicting value with a warning",
+			labels:          map[string]string{labelName: "user-value"},
+			expectedValue:   "user-value",
+			expectedWarning: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			obj := &unstructured.Unstructured{}
+			obj.SetKind("ConfigMap")
+			obj.SetName("settings")
+			obj.SetLabels(testCase.labels)
+			SetInstanceLabel(obj, "inst1")
+
+			if value := obj.GetLabels()[labelName]; value != testCase.expectedValue {
+				t.Fatalf("Expected label value %q, got %q", testCase.expectedValue, value)
+			}
+			if testCase.labels["app"] != "" && obj.GetLabels()["app"] != testCase.labels["app"] {
+				t.Fatalf("Expected the other labels to be kept, got %v", obj.GetLabels())
+			}
+			warned := strings.Contains(buf.String(), "Warning: ConfigMap settings keeps its label")
+			if warned != testCase.expectedWarning {
+				t.Fatalf("Expected warning %t, got log %q", testCase.expectedWarning, buf.String())
+			}
+		})
+	}
+}
+
+// testOwnerConnector is a testKubernetesConnector whose resources are owned
+// by owner, in ownerNamespace
+type testOwnerConnector struct {
+	testKubernetesConnector
+	owner          *metaV1.OwnerReference
+	ownerNamespace string
+}
+
+func (t testOwnerConnector) GetOwnerReference() *metaV1.OwnerReference {
+	return t.owner
+}
+
+func (t testOwnerConnector) GetOwnerNamespace() string {
+	return t.ownerNamespace
+}
+
+func TestSetOwnerReference(t *testing.T) {
+	owner := metaV1.OwnerReference{
+		APIVersion: "k8splugin.io/v1alpha1",
+		Kind:       "ResourceBundleState",
+		Name:       "inst1-rbs",
+		UID:        "bundle-uid",
+	}
+	testCases := []struct {
+		label          string
+		namespace      string
+		ownerNamespace string
+		expected       []metaV1.OwnerReference
+	}{
+		{
+			label:          "Own an object of the namespace of the owner",
+			namespace:      "test1",
+			ownerNamespace: "test1",
+			expected:       []metaV1.OwnerReference{owner},
+		},
+		{
+			label:          "Skip an object of another namespace",
+			namespace:      "test2",
+			ownerNamespace: "test1",
+		},
+		{
+			label:          "Skip a cluster scoped object",
+			ownerNamespace: "test1",
+		},
+		{
+			label:    "Own a cluster scoped object with a cluster scoped owner",
+			expected: []metaV1.OwnerReference{owner},
+		},
+		{
+			label:     "Own any namespaced object with a cluster scoped owner",
+			namespace: "test2",
+			expected:  []metaV1.OwnerReference{owner},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := testOwnerConnector{owner: &owner, ownerNamespace: testCase.ownerNamespace}
+			obj := &metaV1.ObjectMeta{Name: "obj", Namespace: testCase.namespace}
+			SetOwnerReference(obj, testCase.namespace, client)
+			if !reflect.DeepEqual(obj.OwnerReferences, testCase.expected) {
+				t.Fatalf("Expected owner references %+v, got %+v", testCase.expected, obj.OwnerReferences)
+			}
+		})
+	}
+}
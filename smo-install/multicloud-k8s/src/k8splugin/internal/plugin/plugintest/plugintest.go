@@ -0,0 +1,113 @@
+/*
+Copyright 2021 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugintest provides the helpers shared by the tests of the plugins
+package plugintest
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+
+	pkgerrors "github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FakeKubernetesConnector keeps the same clientset across calls so that
+// objects created by one plugin call are visible to the next ones
+type FakeKubernetesConnector struct {
+	ClientSet  kubernetes.Interface
+	InstanceID string
+}
+
+func (t FakeKubernetesConnector) GetMapper() meta.RESTMapper {
+	return nil
+}
+
+func (t FakeKubernetesConnector) GetDynamicClient() dynamic.Interface {
+	return nil
+}
+
+func (t FakeKubernetesConnector) GetStandardClient() kubernetes.Interface {
+	return t.ClientSet
+}
+
+func (t FakeKubernetesConnector) GetInstanceID() string {
+	return t.InstanceID
+}
+
+// WriteManifest stores the given yaml in a temporary file and returns its path
+func WriteManifest(t *testing.T, content string) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "manifest-*.yaml")
+	if err != nil {
+		t.Fatalf("Unable to create manifest file (%s)", err)
+	}
+	defer f.Close()
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	if _, err = f.WriteString(content); err != nil {
+		t.Fatalf("Unable to write manifest file (%s)", err)
+	}
+	return f.Name()
+}
+
+// CheckList fails unless ref lists the resources of gvk named names, in
+// this order, in the default namespace
+func CheckList(t *testing.T, ref plugin.Reference, client plugin.KubernetesConnector,
+	gvk schema.GroupVersionKind, names ...string) {
+
+	t.Helper()
+	list, err := ref.List(context.TODO(), gvk, "", client)
+	if err != nil {
+		t.Fatalf("List method returned an error (%s)", err)
+	}
+	expected := []helm.KubernetesResource{}
+	for _, name := range names {
+		expected = append(expected, helm.KubernetesResource{GVK: gvk, Name: name})
+	}
+	if !reflect.DeepEqual(list, expected) {
+		t.Fatalf("List method returned %v, expected %v", list, expected)
+	}
+}
+
+// CheckGetDelete gets res from the default namespace with ref, deletes it
+// and fails unless it can't be found anymore
+func CheckGetDelete(t *testing.T, ref plugin.Reference, client plugin.KubernetesConnector,
+	res helm.KubernetesResource) {
+
+	t.Helper()
+	name, err := ref.Get(context.TODO(), res, "", client)
+	if err != nil || name != res.Name {
+		t.Fatalf("Get method returned %q, %v", name, err)
+	}
+
+	err = ref.Delete(context.TODO(), res, "", client)
+	if err != nil {
+		t.Fatalf("Delete method returned an error (%s)", err)
+	}
+	_, err = ref.Get(context.TODO(), res, "", client)
+	if !k8serrors.IsNotFound(pkgerrors.Cause(err)) {
+		t.Fatalf("Expected the %s to be deleted, got %v", strings.ToLower(res.GVK.Kind), err)
+	}
+}
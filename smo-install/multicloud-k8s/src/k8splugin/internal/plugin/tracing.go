@@ -0,0 +1,272 @@
+/*
+ * Copyright © 2021 Nokia Bell Labs.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+)
+
+// tracerName identifies the spans of the plugin operations
+const tracerName = "github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+
+// Attributes recorded on the spans of the plugin operations
+const (
+	namespaceKey    = attribute.Key("k8s.namespace.name")
+	instanceIDKey   = attribute.Key("k8splugin.instance.id")
+	resourceNameKey = attribute.Key("k8splugin.resource.name")
+)
+
+// tracerProvider provides the tracer of the plugin operations. The global
+// provider is a no-op until one is registered with otel.SetTracerProvider.
+var tracerProvider = otel.GetTracerProvider()
+
+// SetTracerProvider sets the provider of the tracer used for the spans of
+// the plugin operations, e.g. a no-op or in-memory one in tests
+func SetTracerProvider(provider trace.TracerProvider) {
+	tracerProvider = provider
+}
+
+// WithTracing returns a Reference starting a span for each operation of ref,
+// named after the operation and kind, e.g. "Create Service". The namespace
+// and instance ID are recorded on the span, and so is the returned error.
+// The returned Reference only has the methods of Reference, the optional
+// interfaces of ref are reached with Unwrap or the As functions, e.g.
+// AsProgressWatcher, which keep tracing them.
+func WithTracing(kind string, ref Reference) Reference {
+	return tracedReference{Reference: ref, kind: kind}
+}
+
+// Unwrap returns the plugin wrapped by WithTracing, or ref itself
+func Unwrap(ref Reference) Reference {
+	for {
+		wrapper, ok := ref.(interface{ Unwrap() Reference })
+		if !ok {
+			return ref
+		}
+		ref = wrapper.Unwrap()
+	}
+}
+
+// AsProgressWatcher returns the ProgressWatcher implemented by the plugin of
+// ref, traced like ref
+func AsProgressWatcher(ref Reference) (ProgressWatcher, bool) {
+	watcher, ok := Unwrap(ref).(ProgressWatcher)
+	if traced, isTraced := ref.(tracedReference); ok && isTraced {
+		return tracedProgressWatcher{tracedReference: traced, watcher: watcher}, true
+	}
+	return watcher, ok
+}
+
+// AsDeletionWatcher returns the DeletionWatcher implemented by the plugin of
+// ref, traced like ref
+func AsDeletionWatcher(ref Reference) (DeletionWatcher, bool) {
+	watcher, ok := Unwrap(ref).(DeletionWatcher)
+	if traced, isTraced := ref.(tracedReference); ok && isTraced {
+		return tracedDeletionWatcher{tracedReference: traced, watcher: watcher}, true
+	}
+	return watcher, ok
+}
+
+// AsBytesCreator returns the BytesCreator implemented by the plugin of ref,
+// traced like ref
+func AsBytesCreator(ref Reference) (BytesCreator, bool) {
+	creator, ok := Unwrap(ref).(BytesCreator)
+	if traced, isTraced := ref.(tracedReference); ok && isTraced {
+		return tracedBytesCreator{tracedReference: traced, creator: creator}, true
+	}
+	return creator, ok
+}
+
+// AsRawGetter returns the RawGetter implemented by the plugin of ref, traced
+// like ref
+func AsRawGetter(ref Reference) (RawGetter, bool) {
+	getter, ok := Unwrap(ref).(RawGetter)
+	if traced, isTraced := ref.(tracedReference); ok && isTraced {
+		return tracedRawGetter{tracedReference: traced, getter: getter}, true
+	}
+	return getter, ok
+}
+
+// startSpan starts the span of an operation on a resource of the plugin kind
+func (t tracedReference) startSpan(ctx context.Context, operation string, namespace string,
+	attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, namespaceKey.String(namespace))
+	return tracerProvider.Tracer(tracerName).Start(ctx, operation+" "+t.kind, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedReference wraps the operations of a plugin in spans
+type tracedReference struct {
+	Reference
+	kind string
+}
+
+// Unwrap returns the traced plugin
+func (t tracedReference) Unwrap() Reference {
+	return t.Reference
+}
+
+func (t tracedReference) Create(ctx context.Context, yamlFilePath string, namespace string, client KubernetesConnector) (string, error) {
+	ctx, span := t.startSpan(ctx, "Create", namespace, instanceIDKey.String(client.GetInstanceID()))
+	name, err := t.Reference.Create(ctx, yamlFilePath, namespace, client)
+	span.SetAttributes(resourceNameKey.String(name))
+	endSpan(span, err)
+	return name, err
+}
+
+func (t tracedReference) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) (string, error) {
+	ctx, span := t.startSpan(ctx, "Get", namespace, instanceIDKey.String(client.GetInstanceID()),
+		resourceNameKey.String(resource.Name))
+	name, err := t.Reference.Get(ctx, resource, namespace, client)
+	endSpan(span, err)
+	return name, err
+}
+
+func (t tracedReference) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client KubernetesConnector) ([]helm.KubernetesResource, error) {
+	ctx, span := t.startSpan(ctx, "List", namespace, instanceIDKey.String(client.GetInstanceID()))
+	resources, err := t.Reference.List(ctx, gvk, namespace, client)
+	endSpan(span, err)
+	return resources, err
+}
+
+func (t tracedReference) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) error {
+	ctx, span := t.startSpan(ctx, "Delete", namespace, instanceIDKey.String(client.GetInstanceID()),
+		resourceNameKey.String(resource.Name))
+	err := t.Reference.Delete(ctx, resource, namespace, client)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedReference) Update(ctx context.Context, yamlFilePath string, namespace string, client KubernetesConnector) (string, error) {
+	ctx, span := t.startSpan(ctx, "Update", namespace, instanceIDKey.String(client.GetInstanceID()))
+	name, err := t.Reference.Update(ctx, yamlFilePath, namespace, client)
+	span.SetAttributes(resourceNameKey.String(name))
+	endSpan(span, err)
+	return name, err
+}
+
+func (t tracedReference) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client KubernetesConnector) (string, error) {
+	ctx, span := t.startSpan(ctx, "Patch", namespace, instanceIDKey.String(client.GetInstanceID()),
+		resourceNameKey.String(resource.Name))
+	name, err := t.Reference.Patch(ctx, resource, patchData, patchType, namespace, client)
+	endSpan(span, err)
+	return name, err
+}
+
+// WatchUntilReady has no connector, the span has no instance ID
+func (t tracedReference) WatchUntilReady(ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	ctx, span := t.startSpan(ctx, "WatchUntilReady", ns, resourceNameKey.String(res.Name))
+	err := t.Reference.WatchUntilReady(ctx, timeout, ns, res, mapper, restClient, objType, clientSet)
+	endSpan(span, err)
+	return err
+}
+
+// tracedProgressWatcher wraps the waits of a plugin reporting the progress
+// of its resources in spans
+type tracedProgressWatcher struct {
+	tracedReference
+	watcher ProgressWatcher
+}
+
+func (t tracedProgressWatcher) WatchUntilReadyWithProgress(ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	clientSet kubernetes.Interface,
+	progress ProgressFunc) error {
+	ctx, span := t.startSpan(ctx, "WatchUntilReady", ns, resourceNameKey.String(res.Name))
+	err := t.watcher.WatchUntilReadyWithProgress(ctx, timeout, ns, res, clientSet, progress)
+	endSpan(span, err)
+	return err
+}
+
+// tracedDeletionWatcher wraps the waits of a plugin for deleted resources in
+// spans
+type tracedDeletionWatcher struct {
+	tracedReference
+	watcher DeletionWatcher
+}
+
+func (t tracedDeletionWatcher) WatchUntilDeleted(ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	clientSet kubernetes.Interface) error {
+	ctx, span := t.startSpan(ctx, "WatchUntilDeleted", ns, resourceNameKey.String(res.Name))
+	err := t.watcher.WatchUntilDeleted(ctx, timeout, ns, res, clientSet)
+	endSpan(span, err)
+	return err
+}
+
+// tracedBytesCreator wraps the creations of a plugin from manifests held in
+// memory in spans
+type tracedBytesCreator struct {
+	tracedReference
+	creator BytesCreator
+}
+
+func (t tracedBytesCreator) CreateFromBytes(ctx context.Context, manifest []byte, namespace string, client KubernetesConnector) (string, error) {
+	ctx, span := t.startSpan(ctx, "Create", namespace, instanceIDKey.String(client.GetInstanceID()))
+	name, err := t.creator.CreateFromBytes(ctx, manifest, namespace, client)
+	span.SetAttributes(resourceNameKey.String(name))
+	endSpan(span, err)
+	return name, err
+}
+
+// tracedRawGetter wraps the reads of whole objects by a plugin in spans
+type tracedRawGetter struct {
+	tracedReference
+	getter RawGetter
+}
+
+func (t tracedRawGetter) GetRaw(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) ([]byte, error) {
+	ctx, span := t.startSpan(ctx, "GetRaw", namespace, instanceIDKey.String(client.GetInstanceID()),
+		resourceNameKey.String(resource.Name))
+	raw, err := t.getter.GetRaw(ctx, resource, namespace, client)
+	endSpan(span, err)
+	return raw, err
+}
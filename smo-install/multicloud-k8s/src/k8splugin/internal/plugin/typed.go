@@ -0,0 +1,312 @@
+/*
+ * Copyright © 2021 Nokia Bell Labs.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+
+	pkgerrors "github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Object is a Kubernetes object of a typed client
+type Object interface {
+	runtime.Object
+	metaV1.Object
+}
+
+// TypedClient is the client of one kind in a namespace, e.g.
+// CoreV1().ConfigMaps(namespace), adapted to Object by the plugin of the
+// kind so that TypedResource can use it
+type TypedClient interface {
+	Create(ctx context.Context, obj Object, opts metaV1.CreateOptions) (Object, error)
+	Get(ctx context.Context, name string, opts metaV1.GetOptions) (Object, error)
+	//List returns a page of objects, e.g. a *v1.ConfigMapList
+	List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error)
+	Update(ctx context.Context, obj Object, opts metaV1.UpdateOptions) (Object, error)
+	Patch(ctx context.Context, name string, patchType types.PatchType, data []byte, opts metaV1.PatchOptions) (Object, error)
+	Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error
+}
+
+// TypedResource implements the Reference methods shared by the plugins of
+// the kinds served by the standard client. Such a plugin delegates its
+// methods to a TypedResource and only implements the decoding of its kind
+// and its readiness.
+type TypedResource struct {
+	//GVK is the kind of the resources, its Kind names them in errors and logs
+	GVK schema.GroupVersionKind
+	//Client returns the client of the kind in namespace
+	Client func(clientSet kubernetes.Interface, namespace string) TypedClient
+	//Decode reads a manifest of the kind
+	Decode func(yamlFilePath string) (Object, error)
+	//SetInstanceLabel labels a created or updated object with the instance
+	//ID, e.g. along with the pods it creates. SetInstanceLabel is used when
+	//it is nil.
+	SetInstanceLabel func(obj Object, instanceID string)
+	//KeepExisting, if set, copies to an updated object the fields of the
+	//existing one that can't change once they are set
+	KeepExisting func(obj, existing Object)
+}
+
+// Create decodes the manifest and creates its object
+func (r TypedResource) Create(ctx context.Context, yamlFilePath string, namespace string, client KubernetesConnector) (string, error) {
+	obj, err := r.Decode(yamlFilePath)
+	if err != nil {
+		return "", err
+	}
+	return r.CreateObject(ctx, obj, namespace, client)
+}
+
+// CreateObject creates a decoded object, labeled with the instance ID
+func (r TypedResource) CreateObject(ctx context.Context, obj Object, namespace string, client KubernetesConnector) (string, error) {
+	namespace, err := ResolveNamespace(obj, namespace)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Resolve namespace error")
+	}
+
+	r.setInstanceLabel(obj, client.GetInstanceID())
+	PromoteAnnotationsToLabels(obj)
+	FilterFinalizers(obj)
+
+	result, err := r.Client(client.GetStandardClient(), namespace).Create(ctx, obj, metaV1.CreateOptions{
+		FieldManager: FieldManager(client),
+	})
+	if err != nil {
+		return "", WrapAPIError(err, fmt.Sprintf("Create %s error", r.GVK.Kind))
+	}
+
+	return result.GetName(), nil
+}
+
+// List the objects of the namespace
+func (r TypedResource) List(ctx context.Context, namespace string, client KubernetesConnector) ([]helm.KubernetesResource, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	opts := metaV1.ListOptions{
+		Limit: utils.ResourcesListLimit,
+	}
+
+	objects := r.Client(client.GetStandardClient(), namespace)
+	result := make([]helm.KubernetesResource, 0, utils.ResourcesListLimit)
+	for {
+		list, err := objects.List(ctx, opts)
+		if err != nil {
+			return nil, WrapAPIError(err, fmt.Sprintf("Get %s list error", r.GVK.Kind))
+		}
+
+		err = meta.EachListItem(list, func(item runtime.Object) error {
+			accessor, err := meta.Accessor(item)
+			if err != nil {
+				return err
+			}
+			result = append(result, helm.KubernetesResource{
+				GVK:  r.GVK,
+				Name: accessor.GetName(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Read %s list error", r.GVK.Kind)
+		}
+
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Read %s list error", r.GVK.Kind)
+		}
+		if listMeta.GetContinue() == "" {
+			break
+		}
+		opts.Continue = listMeta.GetContinue()
+	}
+
+	return result, nil
+}
+
+// Delete the named object, its dependents are deleted in the background
+func (r TypedResource) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	deletePolicy := metaV1.DeletePropagationBackground
+	opts := metaV1.DeleteOptions{
+		PropagationPolicy: &deletePolicy,
+	}
+
+	logutils.Info("Deleting "+r.GVK.Kind, r.logFields("delete", namespace, resource.Name, client))
+	if err := r.Client(client.GetStandardClient(), namespace).Delete(ctx, resource.Name, opts); err != nil {
+		return WrapAPIError(err, fmt.Sprintf("Delete %s error", r.GVK.Kind))
+	}
+
+	return nil
+}
+
+// Get returns the name of the object, to check that it exists
+func (r TypedResource) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client KubernetesConnector) (string, error) {
+	obj, err := r.GetObject(ctx, resource.Name, namespace, client.GetStandardClient())
+	if err != nil {
+		return "", err
+	}
+	return obj.GetName(), nil
+}
+
+// GetObject reads the named object
+func (r TypedResource) GetObject(ctx context.Context, name string, namespace string, clientSet kubernetes.Interface) (Object, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	obj, err := r.Client(clientSet, namespace).Get(ctx, name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, WrapAPIError(err, fmt.Sprintf("Get %s error", r.GVK.Kind))
+	}
+	return obj, nil
+}
+
+// Update decodes the manifest and updates its object
+func (r TypedResource) Update(ctx context.Context, yamlFilePath string, namespace string, client KubernetesConnector) (string, error) {
+	obj, err := r.Decode(yamlFilePath)
+	if err != nil {
+		return "", err
+	}
+	return r.UpdateObject(ctx, obj, namespace, client)
+}
+
+// UpdateObject updates a decoded object, it is created if it doesn't exist
+// yet
+func (r TypedResource) UpdateObject(ctx context.Context, obj Object, namespace string, client KubernetesConnector) (string, error) {
+	namespace, err := ResolveNamespace(obj, namespace)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Resolve namespace error")
+	}
+
+	objects := r.Client(client.GetStandardClient(), namespace)
+	existing, err := objects.Get(ctx, obj.GetName(), metaV1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return r.CreateObject(ctx, obj, namespace, client)
+	}
+	if err != nil {
+		return "", WrapAPIError(err, fmt.Sprintf("Get %s error", r.GVK.Kind))
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if r.KeepExisting != nil {
+		r.KeepExisting(obj, existing)
+	}
+
+	r.setInstanceLabel(obj, client.GetInstanceID())
+	PromoteAnnotationsToLabels(obj)
+
+	result, err := objects.Update(ctx, obj, metaV1.UpdateOptions{
+		FieldManager: FieldManager(client),
+	})
+	if err != nil {
+		return "", WrapAPIError(err, fmt.Sprintf("Update %s error", r.GVK.Kind))
+	}
+
+	return result.GetName(), nil
+}
+
+// Patch the named object. The instance label is set again if the patch
+// removed or changed it.
+func (r TypedResource) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client KubernetesConnector) (string, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	objects := r.Client(client.GetStandardClient(), namespace)
+	opts := metaV1.PatchOptions{
+		FieldManager: FieldManager(client),
+	}
+	obj, err := objects.Patch(ctx, resource.Name, patchType, patchData, opts)
+	if err != nil {
+		return "", WrapAPIError(err, fmt.Sprintf("Patch %s error", r.GVK.Kind))
+	}
+
+	if obj.GetLabels()[config.GetConfiguration().KubernetesLabelName] != client.GetInstanceID() {
+		labelPatch, err := InstanceLabelPatch(client)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Marshal label patch error")
+		}
+		obj, err = objects.Patch(ctx, resource.Name, types.MergePatchType, labelPatch, opts)
+		if err != nil {
+			return "", WrapAPIError(err, "Restore instance label error")
+		}
+	}
+
+	return obj.GetName(), nil
+}
+
+// WaitUntil reads the named object every interval until ready reports that
+// it is ready or returns an error. It waits for at most timeout, without
+// limit when timeout is not positive.
+func (r TypedResource) WaitUntil(ctx context.Context, timeout, interval time.Duration, name string, namespace string,
+	clientSet kubernetes.Interface, ready func(obj Object) (bool, error)) error {
+	condition := func() (bool, error) {
+		obj, err := r.GetObject(ctx, name, namespace, clientSet)
+		if err != nil {
+			return false, err
+		}
+		return ready(obj)
+	}
+
+	var err error
+	if timeout <= 0 {
+		err = wait.PollImmediateInfinite(interval, condition)
+	} else {
+		err = wait.PollImmediate(interval, timeout, condition)
+	}
+	if err != nil {
+		return pkgerrors.Wrapf(err, "Waiting for %s %s", r.GVK.Kind, name)
+	}
+	return nil
+}
+
+func (r TypedResource) setInstanceLabel(obj Object, instanceID string) {
+	if r.SetInstanceLabel != nil {
+		r.SetInstanceLabel(obj, instanceID)
+		return
+	}
+	SetInstanceLabel(obj, instanceID)
+}
+
+// logFields returns the fields logged with an operation on an object
+func (r TypedResource) logFields(operation, namespace, name string, client KubernetesConnector) logutils.Fields {
+	return logutils.Fields{
+		"kind":      r.GVK.Kind,
+		"operation": operation,
+		"namespace": namespace,
+		"name":      name,
+		"instance":  client.GetInstanceID(),
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright © 2021 Nokia Bell Labs.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+
+	pkgerrors "github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// testConfigMapClient adapts the ConfigMap client to TypedClient
+type testConfigMapClient struct {
+	configMaps typedCoreV1.ConfigMapInterface
+}
+
+func (c testConfigMapClient) Create(ctx context.Context, obj Object, opts metaV1.CreateOptions) (Object, error) {
+	return c.configMaps.Create(ctx, obj.(*coreV1.ConfigMap), opts)
+}
+
+func (c testConfigMapClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (Object, error) {
+	return c.configMaps.Get(ctx, name, opts)
+}
+
+func (c testConfigMapClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.configMaps.List(ctx, opts)
+}
+
+func (c testConfigMapClient) Update(ctx context.Context, obj Object, opts metaV1.UpdateOptions) (Object, error) {
+	return c.configMaps.Update(ctx, obj.(*coreV1.ConfigMap), opts)
+}
+
+func (c testConfigMapClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (Object, error) {
+	return c.configMaps.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c testConfigMapClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.configMaps.Delete(ctx, name, opts)
+}
+
+// testConfigMaps is a TypedResource of ConfigMaps keeping the "owner" data
+// key of the existing ConfigMaps on update
+var testConfigMaps = TypedResource{
+	GVK: coreV1.SchemeGroupVersion.WithKind("ConfigMap"),
+	Client: func(clientSet kubernetes.Interface, namespace string) TypedClient {
+		return testConfigMapClient{clientSet.CoreV1().ConfigMaps(namespace)}
+	},
+	Decode: func(yamlFilePath string) (Object, error) {
+		obj, err := utils.DecodeManifest(yamlFilePath, nil)
+		if err != nil {
+			return nil, err
+		}
+		return obj.(*coreV1.ConfigMap), nil
+	},
+	KeepExisting: func(obj, existing Object) {
+		obj.(*coreV1.ConfigMap).Data["owner"] = existing.(*coreV1.ConfigMap).Data["owner"]
+	},
+}
+
+func writeTestManifest(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "typed-*.yaml")
+	if err != nil {
+		t.Fatalf("Unable to create manifest file (%s)", err)
+	}
+	defer f.Close()
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	if _, err = f.WriteString(content); err != nil {
+		t.Fatalf("Unable to write manifest file (%s)", err)
+	}
+	return f.Name()
+}
+
+func TestTypedResource(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	clientSet := fake.NewSimpleClientset()
+	client := testKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+	ctx := context.TODO()
+	res := helm.KubernetesResource{GVK: testConfigMaps.GVK, Name: "settings"}
+	manifest := writeTestManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  level: debug
+`)
+
+	// Update creates the object when it doesn't exist yet
+	name, err := testConfigMaps.Update(ctx, manifest, "test1", client)
+	if err != nil || name != "settings" {
+		t.Fatalf("Update method returned %q, %v", name, err)
+	}
+	configMap, err := clientSet.CoreV1().ConfigMaps("test1").Get(ctx, name, metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the configmap to be created (%s)", err)
+	}
+	if configMap.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected the configmap to be labeled with the instance ID, got %v", configMap.Labels)
+	}
+
+	configMap.Data["owner"] = "operator"
+	if _, err = clientSet.CoreV1().ConfigMaps("test1").Update(ctx, configMap, metaV1.UpdateOptions{}); err != nil {
+		t.Fatalf("Unable to update the configmap (%s)", err)
+	}
+	if _, err = testConfigMaps.Update(ctx, manifest, "test1", client); err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	configMap, _ = clientSet.CoreV1().ConfigMaps("test1").Get(ctx, name, metaV1.GetOptions{})
+	expectedData := map[string]string{"level": "debug", "owner": "operator"}
+	if !reflect.DeepEqual(configMap.Data, expectedData) {
+		t.Fatalf("Expected the existing fields to be kept, got %v", configMap.Data)
+	}
+
+	// A patch removing the instance label doesn't untrack the object
+	patch := []byte(`{"metadata":{"labels":{"` + labelName + `":null}}}`)
+	if _, err = testConfigMaps.Patch(ctx, res, patch, types.MergePatchType, "test1", client); err != nil {
+		t.Fatalf("Patch method returned an error (%s)", err)
+	}
+	configMap, _ = clientSet.CoreV1().ConfigMaps("test1").Get(ctx, name, metaV1.GetOptions{})
+	if configMap.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected the instance label to be restored, got %v", configMap.Labels)
+	}
+
+	list, err := testConfigMaps.List(ctx, "test1", client)
+	if err != nil {
+		t.Fatalf("List method returned an error (%s)", err)
+	}
+	if !reflect.DeepEqual(list, []helm.KubernetesResource{res}) {
+		t.Fatalf("List method returned %v, expected %v", list, []helm.KubernetesResource{res})
+	}
+
+	if err = testConfigMaps.Delete(ctx, res, "test1", client); err != nil {
+		t.Fatalf("Delete method returned an error (%s)", err)
+	}
+	_, err = testConfigMaps.Get(ctx, res, "test1", client)
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "Get ConfigMap error") {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if err = testConfigMaps.Delete(ctx, res, "test1", client); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestTypedResourceWaitUntil(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(&coreV1.ConfigMap{
+		ObjectMeta: metaV1.ObjectMeta{Name: "settings", Namespace: "default"},
+	})
+	ctx := context.TODO()
+
+	reads := 0
+	err := testConfigMaps.WaitUntil(ctx, time.Second, time.Millisecond, "settings", "", clientSet, func(obj Object) (bool, error) {
+		reads++
+		return reads == 3, nil
+	})
+	if err != nil || reads != 3 {
+		t.Fatalf("WaitUntil returned %v after %d reads, expected success after 3", err, reads)
+	}
+
+	err = testConfigMaps.WaitUntil(ctx, 10*time.Millisecond, time.Millisecond, "settings", "", clientSet, func(obj Object) (bool, error) {
+		return false, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "Waiting for ConfigMap settings") {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+
+	failure := pkgerrors.New("not ready for good")
+	err = testConfigMaps.WaitUntil(ctx, time.Second, time.Millisecond, "settings", "", clientSet, func(obj Object) (bool, error) {
+		return false, failure
+	})
+	if pkgerrors.Cause(err) != failure {
+		t.Fatalf("Expected the readiness error, got %v", err)
+	}
+}
@@ -18,6 +18,7 @@ package rb
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
 	pkgerrors "github.com/pkg/errors"
@@ -25,8 +26,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// ValidateTarGz checks that content is a gzip compressed tar archive of
+// directories and regular files whose names stay inside the archive
+func ValidateTarGz(content []byte) error {
+	return isTarGz(bytes.NewReader(content))
+}
+
 func isTarGz(r io.Reader) error {
 	//Check if it is a valid gz
 	gzf, err := gzip.NewReader(r)
@@ -62,6 +70,13 @@ func isTarGz(r io.Reader) error {
 				header.Name, string(header.Typeflag))
 		}
 
+		//Reject entries that would be extracted outside of the target
+		for _, element := range strings.Split(filepath.ToSlash(header.Name), "/") {
+			if element == ".." {
+				return pkgerrors.Errorf("Invalid path in tar %s", header.Name)
+			}
+		}
+
 		first = false
 	}
 
@@ -17,7 +17,9 @@
 package rb
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -68,6 +70,20 @@ func TestIsTarGz(t *testing.T) {
 		}
 	})
 
+	t.Run("Path traversal in tar.gz", func(t *testing.T) {
+		var buf bytes.Buffer
+		gzw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gzw)
+		tw.WriteHeader(&tar.Header{Name: "chart/../../etc/passwd", Typeflag: tar.TypeReg, Mode: 0644})
+		tw.Close()
+		gzw.Close()
+
+		err := ValidateTarGz(buf.Bytes())
+		if err == nil || !strings.Contains(err.Error(), "Invalid path") {
+			t.Errorf("Expected an invalid path error, got %v", err)
+		}
+	})
+
 	t.Run("Empty tar.gz", func(t *testing.T) {
 		content := []byte{}
 		err := isTarGz(bytes.NewBuffer(content))
@@ -18,12 +18,19 @@ package rb
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/db"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
@@ -39,6 +46,77 @@ type Definition struct {
 	ChartName   string            `json:"chart-name"`
 	Description string            `json:"description"`
 	Labels      map[string]string `json:"labels"`
+	// SchemaVersion is the version of the stored record layout, older
+	// records are upgraded when they are read
+	SchemaVersion int `json:"schema-version"`
+	// ContentSHA256 is the hex encoded SHA-256 digest of the uploaded
+	// bundle, it is set by Upload
+	ContentSHA256 string `json:"content-sha256,omitempty"`
+	// CreatedAt and UpdatedAt are the RFC3339 times the definition was
+	// created and last updated, they are set by the DefinitionManager and
+	// empty for definitions stored before they were introduced
+	CreatedAt string `json:"created-at,omitempty"`
+	UpdatedAt string `json:"updated-at,omitempty"`
+}
+
+// timeNow returns the current time, it is replaced in tests
+var timeNow = time.Now
+
+// timestamp returns the current time as stored in Definition.CreatedAt and
+// Definition.UpdatedAt
+func timestamp() string {
+	return timeNow().UTC().Format(time.RFC3339Nano)
+}
+
+// ContentDigest returns the hex encoded SHA-256 digest of a bundle, as
+// stored in Definition.ContentSHA256
+func ContentDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ETag returns a strong entity tag of the definition, it changes whenever
+// one of the fields of the definition does
+func (d Definition) ETag() string {
+	// Marshaling is stable, map keys are sorted
+	out, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(out)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// ErrDefinitionNotFound is returned when no definition is stored for a
+// name and version
+var ErrDefinitionNotFound = errors.New("Resource Bundle Definition not found")
+
+// ErrDefinitionContentNotFound is returned when no bundle was uploaded for
+// a definition
+var ErrDefinitionContentNotFound = errors.New("Resource Bundle Definition content not found")
+
+// ErrDefinitionExists is returned when a definition is already stored for a
+// name and version
+var ErrDefinitionExists = errors.New("Definition already exists")
+
+// ErrDefinitionInUse is returned by Delete when instances created from the
+// definition still exist
+var ErrDefinitionInUse = errors.New("Definition is used by instances")
+
+// ErrPreconditionFailed is returned by Update when the stored definition
+// doesn't match the entity tags it was given
+var ErrPreconditionFailed = errors.New("Definition precondition failed")
+
+// ETagMatches reports whether the If-Match header value match lists etag.
+// Weak tags never match, If-Match requires a strong comparison.
+func ETagMatches(match, etag string) bool {
+	for _, tag := range strings.Split(match, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (tag == etag && etag != "") {
+			return true
+		}
+	}
+	return false
 }
 
 // DefinitionKey is the key structure that is used in the database
@@ -61,11 +139,16 @@ func (dk DefinitionKey) String() string {
 // DefinitionManager is an interface exposes the resource bundle definition functionality
 type DefinitionManager interface {
 	Create(def Definition) (Definition, error)
-	Update(def Definition) (Definition, error)
+	Update(def Definition, ifMatch string) (Definition, error)
 	List(name string) ([]Definition, error)
 	Get(name string, version string) (Definition, error)
-	Delete(name string, version string) error
+	Delete(name string, version string, force bool) error
 	Upload(name string, version string, inp []byte) error
+	OpenContent(name string, version string) (Definition, io.ReadCloser, error)
+	Clone(name string, version string, targetVersion string) (Definition, error)
+	Watch() (<-chan DefinitionEvent, func())
+	Ping() error
+	CountInstances(name string, version string) (int, error)
 }
 
 // DefinitionClient implements the DefinitionManager
@@ -73,6 +156,8 @@ type DefinitionManager interface {
 type DefinitionClient struct {
 	storeName           string
 	tagMeta, tagContent string
+	// tagInstance is the tag of the instances stored by the app package
+	tagInstance string
 }
 
 // NewDefinitionClient returns an instance of the DefinitionClient
@@ -80,9 +165,10 @@ type DefinitionClient struct {
 // Uses rbdef collection in underlying db
 func NewDefinitionClient() *DefinitionClient {
 	return &DefinitionClient{
-		storeName:  "rbdef",
-		tagMeta:    "defmetadata",
-		tagContent: "defcontent",
+		storeName:   "rbdef",
+		tagMeta:     "defmetadata",
+		tagContent:  "defcontent",
+		tagInstance: "instance",
 	}
 }
 
@@ -95,9 +181,15 @@ func (v *DefinitionClient) Create(def Definition) (Definition, error) {
 	//Check if this definition already exists
 	_, err := v.Get(def.RBName, def.RBVersion)
 	if err == nil {
-		return Definition{}, pkgerrors.New("Definition already exists")
+		return Definition{}, ErrDefinitionExists
 	}
 
+	def.SchemaVersion = DefinitionSchemaVersion
+	// The digest describes uploaded content, there is none yet
+	def.ContentSHA256 = ""
+	def.CreatedAt = timestamp()
+	def.UpdatedAt = def.CreatedAt
+
 	err = db.DBconn.Create(v.storeName, key, v.tagMeta, def)
 	if err != nil {
 		return Definition{}, pkgerrors.Wrap(err, "Creating DB Entry")
@@ -137,26 +229,45 @@ func (v *DefinitionClient) Create(def Definition) (Definition, error) {
 		return Definition{}, pkgerrors.Wrap(err, "Upload Empty Profile")
 	}
 
+	definitionEvents.publish(DefinitionCreated, def)
 	return def, nil
 }
 
-// Update an entry for the resource in the database`
-func (v *DefinitionClient) Update(def Definition) (Definition, error) {
+// Update an entry for the resource in the database. A non empty ifMatch is
+// an If-Match header value, the update then fails with ErrPreconditionFailed
+// unless it lists the ETag of the stored definition. The comparison and the
+// write happen under the lock of the definition.
+func (v *DefinitionClient) Update(def Definition, ifMatch string) (Definition, error) {
+	unlock := lockDefinition(def.RBName, def.RBVersion)
+	defer unlock()
 
 	//Construct composite key consisting of name and version
 	key := DefinitionKey{RBName: def.RBName, RBVersion: def.RBVersion}
 
 	//Check if this definition already exists
-	_, err := v.Get(def.RBName, def.RBVersion)
+	existing, err := v.Get(def.RBName, def.RBVersion)
 	if err != nil {
+		if ifMatch != "" && errors.Is(err, ErrDefinitionNotFound) {
+			return Definition{}, pkgerrors.Wrap(ErrPreconditionFailed, "Definition does not exist")
+		}
 		return Definition{}, pkgerrors.New("Definition does not exists")
 	}
+	if ifMatch != "" && !ETagMatches(ifMatch, existing.ETag()) {
+		return Definition{}, pkgerrors.Wrap(ErrPreconditionFailed, "Definition was modified")
+	}
+
+	def.SchemaVersion = DefinitionSchemaVersion
+	// The digest only changes with the uploaded content
+	def.ContentSHA256 = existing.ContentSHA256
+	def.CreatedAt = existing.CreatedAt
+	def.UpdatedAt = timestamp()
 
 	err = db.DBconn.Update(v.storeName, key, v.tagMeta, def)
 	if err != nil {
 		return Definition{}, pkgerrors.Wrap(err, "Updating DB Entry")
 	}
 
+	definitionEvents.publish(DefinitionUpdated, def)
 	return def, nil
 }
 
@@ -177,6 +288,7 @@ func (v *DefinitionClient) List(name string) ([]Definition, error) {
 				log.Printf("[Definition] Error Unmarshaling value for: %s", key)
 				continue
 			}
+			migrateDefinition(&def)
 
 			//Select only the definitions that match name provided
 			//If name is empty, return all
@@ -196,6 +308,9 @@ func (v *DefinitionClient) Get(name string, version string) (Definition, error)
 	//Construct the composite key to select the entry
 	key := DefinitionKey{RBName: name, RBVersion: version}
 	value, err := db.DBconn.Read(v.storeName, key, v.tagMeta)
+	if errors.Is(err, db.ErrNotFound) {
+		return Definition{}, pkgerrors.Wrap(ErrDefinitionNotFound, "Error getting Resource Bundle Definition")
+	}
 	if err != nil {
 		return Definition{}, pkgerrors.Wrap(err, "Get Resource Bundle definition")
 	}
@@ -207,18 +322,40 @@ func (v *DefinitionClient) Get(name string, version string) (Definition, error)
 		if err != nil {
 			return Definition{}, pkgerrors.Wrap(err, "Unmarshaling Value")
 		}
+		migrateDefinition(&def)
 		return def, nil
 	}
 
-	return Definition{}, pkgerrors.New("Error getting Resource Bundle Definition")
+	return Definition{}, pkgerrors.Wrap(ErrDefinitionNotFound, "Error getting Resource Bundle Definition")
 }
 
-// Delete the Resource Bundle definition from database
-func (v *DefinitionClient) Delete(name string, version string) error {
+// Delete the Resource Bundle definition from database. A definition still
+// used by instances is only deleted with force, otherwise Delete fails with
+// ErrDefinitionInUse.
+func (v *DefinitionClient) Delete(name string, version string, force bool) error {
+	unlock := lockDefinition(name, version)
+	defer unlock()
+
+	//Check if this definition exists
+	_, err := v.Get(name, version)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Delete Resource Bundle Definition")
+	}
+
+	if !force {
+		count, err := v.CountInstances(name, version)
+		if err != nil {
+			return pkgerrors.Wrap(err, "Delete Resource Bundle Definition")
+		}
+		if count > 0 {
+			return pkgerrors.Wrapf(ErrDefinitionInUse, "Definition %s/%s is used by %d instances, "+
+				"delete them first or set force=true", name, version, count)
+		}
+	}
 
 	//Construct the composite key to select the entry
 	key := DefinitionKey{RBName: name, RBVersion: version}
-	err := db.DBconn.Delete(v.storeName, key, v.tagMeta)
+	err = db.DBconn.Delete(v.storeName, key, v.tagMeta)
 	if err != nil {
 		return pkgerrors.Wrap(err, "Delete Resource Bundle Definition")
 	}
@@ -242,11 +379,68 @@ func (v *DefinitionClient) Delete(name string, version string) error {
 		return pkgerrors.Wrap(err, "Deleting default profile")
 	}
 
+	definitionEvents.publish(DefinitionDeleted, Definition{RBName: name, RBVersion: version})
 	return nil
 }
 
-// Upload the contents of resource bundle into database
+// keyedLocks holds a mutex per key. A mutex only exists while it is held or
+// waited for, so the number of entries is bounded by the goroutines using
+// them rather than by the keys ever locked.
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	// users counts the goroutines holding or waiting for the mutex
+	users int
+}
+
+// lock locks the mutex of key and returns the function unlocking it
+func (l *keyedLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*keyedLock{}
+	}
+	entry, ok := l.locks[key]
+	if !ok {
+		entry = &keyedLock{}
+		l.locks[key] = entry
+	}
+	entry.users++
+	l.mu.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+		l.mu.Lock()
+		entry.users--
+		if entry.users == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// definitionLocks serializes the writes of a definition, keyed by
+// DefinitionKey. They only hold within this process, replicas sharing the
+// database are not serialized with each other.
+var definitionLocks keyedLocks
+
+// lockDefinition locks the writes of a definition and returns the function
+// unlocking them
+func lockDefinition(name string, version string) func() {
+	return definitionLocks.lock(DefinitionKey{RBName: name, RBVersion: version}.String())
+}
+
+// Upload the contents of resource bundle into database. Concurrent uploads
+// of a same definition are serialized: a later upload waits for the one in
+// progress and then replaces its content, so that the stored content and
+// its digest always come from a single upload.
 func (v *DefinitionClient) Upload(name string, version string, inp []byte) error {
+	unlock := lockDefinition(name, version)
+	defer unlock()
 
 	//Check if definition metadata exists
 	def, err := v.Get(name, version)
@@ -288,24 +482,179 @@ func (v *DefinitionClient) Upload(name string, version string, inp []byte) error
 		if def.ChartName == "" {
 			return pkgerrors.New("Unable to detect chart name")
 		}
-
-		//TODO: Use db update api once db supports it.
-		err = db.DBconn.Create(v.storeName, key, v.tagMeta, def)
-		if err != nil {
-			return pkgerrors.Wrap(err, "Storing updated chart metadata")
-		}
 	}
 
 	//Encode given byte stream to text for storage
 	encodedStr := base64.StdEncoding.EncodeToString(inp)
-	err = db.DBconn.Create(v.storeName, key, v.tagContent, encodedStr)
+	if store, ok := db.DBconn.(db.StreamStore); ok {
+		err = store.WriteStream(v.storeName, key, v.tagContent, strings.NewReader(encodedStr))
+	} else {
+		err = db.DBconn.Create(v.storeName, key, v.tagContent, encodedStr)
+	}
 	if err != nil {
 		return pkgerrors.Errorf("Error uploading data to db: %s", err.Error())
 	}
 
+	//Store the detected chart name and the digest of the content
+	def.ContentSHA256 = ContentDigest(inp)
+	def.UpdatedAt = timestamp()
+	//TODO: Use db update api once db supports it.
+	err = db.DBconn.Create(v.storeName, key, v.tagMeta, def)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Storing updated chart metadata")
+	}
+
+	definitionEvents.publish(DefinitionUpdated, def)
 	return nil
 }
 
+// OpenContent returns the definition along with a reader of the bundle
+// tarball uploaded for it, streamed from the store when it is a
+// db.StreamStore. The definition is locked until the reader is closed, so
+// that the content matches the returned definition, e.g. its ContentSHA256.
+func (v *DefinitionClient) OpenContent(name string, version string) (Definition, io.ReadCloser, error) {
+	unlock := lockDefinition(name, version)
+
+	def, err := v.Get(name, version)
+	if err != nil {
+		unlock()
+		return Definition{}, nil, err
+	}
+
+	content, err := v.openContent(name, version)
+	if err != nil {
+		unlock()
+		return Definition{}, nil, err
+	}
+
+	return def, &lockedReader{ReadCloser: content, unlock: unlock}, nil
+}
+
+// openContent returns a reader decoding the content stored for the
+// definition
+func (v *DefinitionClient) openContent(name string, version string) (io.ReadCloser, error) {
+	key := DefinitionKey{RBName: name, RBVersion: version}
+
+	var stored io.ReadCloser
+	var err error
+	if store, ok := db.DBconn.(db.StreamStore); ok {
+		stored, err = store.ReadStream(v.storeName, key, v.tagContent)
+	} else {
+		var value []byte
+		value, err = db.DBconn.Read(v.storeName, key, v.tagContent)
+		if err == nil && len(value) == 0 {
+			err = db.ErrNotFound
+		}
+		stored = ioutil.NopCloser(bytes.NewReader(value))
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, pkgerrors.Wrapf(ErrDefinitionContentNotFound, "Definition %s/%s", name, version)
+	}
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Get Resource Bundle definition content")
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{base64.NewDecoder(base64.StdEncoding, stored), stored}, nil
+}
+
+// lockedReader releases the lock of a definition once its content is closed
+type lockedReader struct {
+	io.ReadCloser
+	unlock func()
+	once   sync.Once
+}
+
+func (r *lockedReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.unlock)
+	return err
+}
+
+// Clone creates the targetVersion of a definition from an existing version,
+// copying its metadata and its uploaded content if there is some
+func (v *DefinitionClient) Clone(name string, version string, targetVersion string) (Definition, error) {
+	var content []byte
+	source, reader, err := v.OpenContent(name, version)
+	switch {
+	case errors.Is(err, ErrDefinitionContentNotFound):
+		source, err = v.Get(name, version)
+		if err != nil {
+			return Definition{}, pkgerrors.Wrap(err, "Clone Resource Bundle Definition")
+		}
+	case err != nil:
+		return Definition{}, pkgerrors.Wrap(err, "Clone Resource Bundle Definition")
+	default:
+		content, err = ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return Definition{}, pkgerrors.Wrap(err, "Read Resource Bundle Definition content")
+		}
+	}
+
+	target := source
+	target.RBVersion = targetVersion
+	target, err = v.Create(target)
+	if err != nil {
+		return Definition{}, pkgerrors.Wrap(err, "Clone Resource Bundle Definition")
+	}
+	if content == nil {
+		return target, nil
+	}
+
+	err = v.Upload(name, targetVersion, content)
+	if err != nil {
+		return Definition{}, pkgerrors.Wrap(err, "Clone Resource Bundle Definition content")
+	}
+	return v.Get(name, targetVersion)
+}
+
+// CountInstances returns how many instances were created from the
+// definition and not deleted yet
+func (v *DefinitionClient) CountInstances(name string, version string) (int, error) {
+	res, err := db.DBconn.ReadAll(v.storeName, v.tagInstance)
+	// Stores report no instance at all as an error with an empty result
+	if err != nil && res == nil {
+		return 0, pkgerrors.Wrap(err, "Listing Instances")
+	}
+
+	count := 0
+	for key, value := range res {
+		// Only the definition the instance was created from is read
+		instance := struct {
+			Request struct {
+				RBName    string `json:"rb-name"`
+				RBVersion string `json:"rb-version"`
+			} `json:"request"`
+		}{}
+		if err := db.DBconn.Unmarshal(value, &instance); err != nil {
+			log.Printf("[Definition] Error Unmarshaling Instance: %s", key)
+			continue
+		}
+		if instance.Request.RBName == name && instance.Request.RBVersion == version {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Ping checks that the store of the definitions is reachable
+func (v *DefinitionClient) Ping() error {
+	err := db.DBconn.HealthCheck()
+	if err != nil {
+		return pkgerrors.Wrap(err, "Resource Bundle Definition store")
+	}
+	return nil
+}
+
+// Watch returns a channel receiving an event for every Definition created,
+// updated or deleted from now on, and a function to stop watching
+func (v *DefinitionClient) Watch() (<-chan DefinitionEvent, func()) {
+	return definitionEvents.subscribe()
+}
+
 // Download the contents of the resource bundle definition from DB
 // Returns a byte array of the contents which is used by the
 // ExtractTarBall code to create the folder structure on disk
@@ -318,23 +667,18 @@ func (v *DefinitionClient) Download(name string, version string) ([]byte, error)
 		return nil, pkgerrors.Errorf("Invalid Definition ID provided: %s", err.Error())
 	}
 
-	//Construct the composite key to select the entry
-	key := DefinitionKey{RBName: name, RBVersion: version}
-	value, err := db.DBconn.Read(v.storeName, key, v.tagContent)
+	content, err := v.openContent(name, version)
 	if err != nil {
-		return nil, pkgerrors.Wrap(err, "Get Resource Bundle definition content")
+		return nil, err
 	}
+	defer content.Close()
 
-	if value != nil {
-		//Decode the string from base64
-		out, err := base64.StdEncoding.DecodeString(string(value))
-		if err != nil {
-			return nil, pkgerrors.Wrap(err, "Decode base64 string")
-		}
-
-		if out != nil && len(out) != 0 {
-			return out, nil
-		}
+	out, err := ioutil.ReadAll(content)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode base64 string")
+	}
+	if len(out) == 0 {
+		return nil, pkgerrors.New("Error downloading Definition content")
 	}
-	return nil, pkgerrors.New("Error downloading Definition content")
+	return out, nil
 }
@@ -17,18 +17,37 @@
 package rb
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/db"
 
 	pkgerrors "github.com/pkg/errors"
 )
 
+// setTimeNow makes the definition timestamps read the times returned by
+// now until the end of the test
+func setTimeNow(t *testing.T, now func() time.Time) {
+	timeNow = now
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
 func TestCreateDefinition(t *testing.T) {
+	created := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	setTimeNow(t, func() time.Time { return created })
+
 	testCases := []struct {
 		label         string
 		inp           Definition
@@ -45,10 +64,13 @@ func TestCreateDefinition(t *testing.T) {
 				ChartName:   "",
 			},
 			expected: Definition{
-				RBName:      "testresourcebundle",
-				RBVersion:   "v1",
-				Description: "testresourcebundle",
-				ChartName:   "",
+				RBName:        "testresourcebundle",
+				RBVersion:     "v1",
+				Description:   "testresourcebundle",
+				ChartName:     "",
+				SchemaVersion: DefinitionSchemaVersion,
+				CreatedAt:     "2021-06-01T10:00:00Z",
+				UpdatedAt:     "2021-06-01T10:00:00Z",
 			},
 			expectedError: "",
 			mockdb:        &db.MockDB{},
@@ -84,6 +106,50 @@ func TestCreateDefinition(t *testing.T) {
 	}
 }
 
+func TestUpdateDefinitionTimestamps(t *testing.T) {
+	created := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	updated := created.Add(90 * time.Minute)
+	now := created
+	setTimeNow(t, func() time.Time { return now })
+
+	db.DBconn = &db.MockDB{}
+	impl := NewDefinitionClient()
+	def, err := impl.Create(Definition{
+		RBName:    "testresourcebundle",
+		RBVersion: "v1",
+		// Timestamps supplied by the client are ignored
+		CreatedAt: "2000-01-01T00:00:00Z",
+		UpdatedAt: "2000-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Create returned an unexpected error %s", err)
+	}
+	if def.CreatedAt != "2021-06-01T10:00:00Z" || def.UpdatedAt != def.CreatedAt {
+		t.Fatalf("Create returned timestamps %s and %s, expected the creation time", def.CreatedAt, def.UpdatedAt)
+	}
+
+	now = updated
+	def.Description = "updated description"
+	def.CreatedAt = "2000-01-01T00:00:00Z"
+	if _, err = impl.Update(def, ""); err != nil {
+		t.Fatalf("Update returned an unexpected error %s", err)
+	}
+
+	got, err := impl.Get("testresourcebundle", "v1")
+	if err != nil {
+		t.Fatalf("Get returned an unexpected error %s", err)
+	}
+	if got.CreatedAt != "2021-06-01T10:00:00Z" {
+		t.Errorf("Update changed CreatedAt to %s", got.CreatedAt)
+	}
+	if got.UpdatedAt != "2021-06-01T11:30:00Z" {
+		t.Errorf("Update set UpdatedAt to %s, expected 2021-06-01T11:30:00Z", got.UpdatedAt)
+	}
+	if got.Description != "updated description" {
+		t.Errorf("Update did not store the description, got %s", got.Description)
+	}
+}
+
 func TestListDefinition(t *testing.T) {
 
 	testCases := []struct {
@@ -98,16 +164,20 @@ func TestListDefinition(t *testing.T) {
 			name:  "testresourcebundle",
 			expected: []Definition{
 				{
-					RBName:      "testresourcebundle",
-					RBVersion:   "v1",
-					Description: "testresourcebundle",
-					ChartName:   "testchart",
+					RBName:        "testresourcebundle",
+					RBVersion:     "v1",
+					Description:   "testresourcebundle",
+					ChartName:     "testchart",
+					Labels:        map[string]string{},
+					SchemaVersion: DefinitionSchemaVersion,
 				},
 				{
-					RBName:      "testresourcebundle",
-					RBVersion:   "v2",
-					Description: "testresourcebundle_version2",
-					ChartName:   "testchart",
+					RBName:        "testresourcebundle",
+					RBVersion:     "v2",
+					Description:   "testresourcebundle_version2",
+					ChartName:     "testchart",
+					Labels:        map[string]string{},
+					SchemaVersion: DefinitionSchemaVersion,
 				},
 			},
 			expectedError: "",
@@ -188,10 +258,39 @@ func TestGetDefinition(t *testing.T) {
 			name:    "testresourcebundle",
 			version: "v1",
 			expected: Definition{
-				RBName:      "testresourcebundle",
-				RBVersion:   "v1",
-				Description: "testresourcebundle",
-				ChartName:   "testchart",
+				RBName:        "testresourcebundle",
+				RBVersion:     "v1",
+				Description:   "testresourcebundle",
+				ChartName:     "testchart",
+				Labels:        map[string]string{"vendor": "onap"},
+				SchemaVersion: 1,
+			},
+			expectedError: "",
+			mockdb: &db.MockDB{
+				Items: map[string]map[string][]byte{
+					DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String(): {
+						"defmetadata": []byte(
+							"{\"rb-name\":\"testresourcebundle\"," +
+								"\"description\":\"testresourcebundle\"," +
+								"\"rb-version\":\"v1\"," +
+								"\"chart-name\":\"testchart\"," +
+								"\"labels\":{\"vendor\":\"onap\"}," +
+								"\"schema-version\":1}"),
+					},
+				},
+			},
+		},
+		{
+			label:   "Get Resource Bundle Definition Stored Without Schema Version",
+			name:    "testresourcebundle",
+			version: "v1",
+			expected: Definition{
+				RBName:        "testresourcebundle",
+				RBVersion:     "v1",
+				Description:   "testresourcebundle",
+				ChartName:     "testchart",
+				Labels:        map[string]string{},
+				SchemaVersion: DefinitionSchemaVersion,
 			},
 			expectedError: "",
 			mockdb: &db.MockDB{
@@ -206,6 +305,26 @@ func TestGetDefinition(t *testing.T) {
 				},
 			},
 		},
+		{
+			label:         "Get Missing Resource Bundle Definition",
+			name:          "testresourcebundle",
+			version:       "v1",
+			expectedError: "Resource Bundle Definition not found",
+			mockdb:        &db.MockDB{},
+		},
+		{
+			label:         "Get Resource Bundle Definition Without Metadata",
+			name:          "testresourcebundle",
+			version:       "v1",
+			expectedError: "Resource Bundle Definition not found",
+			mockdb: &db.MockDB{
+				Items: map[string]map[string][]byte{
+					DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String(): {
+						"defcontent": []byte("H4sICCVd3FwAA3Byb2ZpbGUxLnRhcgDt1NEKgjAUxvFd7ylG98"),
+					},
+				},
+			},
+		},
 		{
 			label:         "Get Error",
 			expectedError: "DB Error",
@@ -238,11 +357,25 @@ func TestGetDefinition(t *testing.T) {
 }
 
 func TestDeleteDefinition(t *testing.T) {
+	usedDefinition := func() map[string]map[string][]byte {
+		return map[string]map[string][]byte{
+			DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String(): {
+				"defmetadata": []byte(
+					"{\"rb-name\":\"testresourcebundle\"," +
+						"\"rb-version\":\"v1\"}"),
+			},
+			"inst1": {
+				"instance": []byte("{\"id\":\"inst1\",\"request\":" +
+					"{\"rb-name\":\"testresourcebundle\",\"rb-version\":\"v1\"}}"),
+			},
+		}
+	}
 
 	testCases := []struct {
 		label         string
 		name          string
 		version       string
+		force         bool
 		expectedError string
 		mockdb        *db.MockDB
 	}{
@@ -250,7 +383,36 @@ func TestDeleteDefinition(t *testing.T) {
 			label:   "Delete Resource Bundle Definition",
 			name:    "testresourcebundle",
 			version: "v1",
-			mockdb:  &db.MockDB{},
+			mockdb: &db.MockDB{
+				Items: map[string]map[string][]byte{
+					DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String(): {
+						"defmetadata": []byte(
+							"{\"rb-name\":\"testresourcebundle\"," +
+								"\"rb-version\":\"v1\"}"),
+					},
+				},
+			},
+		},
+		{
+			label:         "Delete Resource Bundle Definition Used By Instances",
+			name:          "testresourcebundle",
+			version:       "v1",
+			expectedError: "used by 1 instances",
+			mockdb:        &db.MockDB{Items: usedDefinition()},
+		},
+		{
+			label:   "Force Delete Resource Bundle Definition Used By Instances",
+			name:    "testresourcebundle",
+			version: "v1",
+			force:   true,
+			mockdb:  &db.MockDB{Items: usedDefinition()},
+		},
+		{
+			label:         "Delete Missing Resource Bundle Definition",
+			name:          "testresourcebundle",
+			version:       "v1",
+			expectedError: "Resource Bundle Definition not found",
+			mockdb:        &db.MockDB{},
 		},
 		{
 			label:         "Delete Error",
@@ -265,7 +427,10 @@ func TestDeleteDefinition(t *testing.T) {
 		t.Run(testCase.label, func(t *testing.T) {
 			db.DBconn = testCase.mockdb
 			impl := NewDefinitionClient()
-			err := impl.Delete(testCase.name, testCase.version)
+			err := impl.Delete(testCase.name, testCase.version, testCase.force)
+			if err == nil && testCase.expectedError != "" {
+				t.Fatalf("Delete returned nil, expected error %s", testCase.expectedError)
+			}
 			if err != nil {
 				if testCase.expectedError == "" {
 					t.Fatalf("Delete returned an unexpected error %s", err)
@@ -278,6 +443,51 @@ func TestDeleteDefinition(t *testing.T) {
 	}
 }
 
+func TestCountDefinitionInstances(t *testing.T) {
+	instance := func(name, version string) map[string][]byte {
+		return map[string][]byte{
+			"instance": []byte("{\"id\":\"inst\",\"request\":{\"rb-name\":\"" + name +
+				"\",\"rb-version\":\"" + version + "\"}}"),
+		}
+	}
+
+	testCases := []struct {
+		label    string
+		items    map[string]map[string][]byte
+		expected int
+	}{
+		{
+			label: "Definition used by instances",
+			items: map[string]map[string][]byte{
+				"inst1": instance("testresourcebundle", "v1"),
+				"inst2": instance("testresourcebundle", "v1"),
+				"inst3": instance("testresourcebundle", "v2"),
+				"inst4": instance("otherresourcebundle", "v1"),
+			},
+			expected: 2,
+		},
+		{
+			label:    "Definition without instances",
+			items:    map[string]map[string][]byte{"inst3": instance("testresourcebundle", "v2")},
+			expected: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			db.DBconn = &db.MockDB{Items: testCase.items}
+			impl := NewDefinitionClient()
+			got, err := impl.CountInstances("testresourcebundle", "v1")
+			if err != nil {
+				t.Fatalf("CountInstances returned an unexpected error %s", err)
+			}
+			if got != testCase.expected {
+				t.Errorf("CountInstances returned %d, expected %d", got, testCase.expected)
+			}
+		})
+	}
+}
+
 func TestUploadDefinition(t *testing.T) {
 	testCases := []struct {
 		label         string
@@ -516,6 +726,223 @@ func TestUploadDefinition(t *testing.T) {
 	}
 }
 
+// overlapStore records whether its writes overlap, they are slowed down
+// to leave time for a concurrent one
+type overlapStore struct {
+	*db.MockDB
+	writing    int32
+	overlapped int32
+}
+
+func (s *overlapStore) Create(table string, key db.Key, tag string, data interface{}) error {
+	if atomic.AddInt32(&s.writing, 1) > 1 {
+		atomic.StoreInt32(&s.overlapped, 1)
+	}
+	defer atomic.AddInt32(&s.writing, -1)
+	time.Sleep(20 * time.Millisecond)
+	return s.MockDB.Create(table, key, tag, data)
+}
+
+func (s *overlapStore) Update(table string, key db.Key, tag string, data interface{}) error {
+	return s.Create(table, key, tag, data)
+}
+
+func TestConcurrentUploadDefinition(t *testing.T) {
+	// newTarball returns a bundle holding a chart with the given description
+	newTarball := func(description string) []byte {
+		var content bytes.Buffer
+		gw := gzip.NewWriter(&content)
+		tw := tar.NewWriter(gw)
+		chart := []byte("name: testchart\ndescription: " + description + "\n")
+		tw.WriteHeader(&tar.Header{Name: "testchart/Chart.yaml", Mode: 0644, Size: int64(len(chart))})
+		tw.Write(chart)
+		tw.Close()
+		gw.Close()
+		return content.Bytes()
+	}
+	tarballs := [][]byte{newTarball("first upload"), newTarball("second upload")}
+
+	store := &overlapStore{MockDB: &db.MockDB{
+		Items: map[string]map[string][]byte{
+			DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String(): {
+				"defmetadata": []byte("{\"rb-name\":\"testresourcebundle\",\"rb-version\":\"v1\"," +
+					"\"chart-name\":\"testchart\"}"),
+			},
+		},
+	}}
+	db.DBconn = store
+	impl := NewDefinitionClient()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(tarballs))
+	for i := range tarballs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = impl.Upload("testresourcebundle", "v1", tarballs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Upload returned an unexpected error %s", err)
+		}
+	}
+	if store.overlapped != 0 {
+		t.Fatalf("Expected the concurrent uploads to be serialized")
+	}
+
+	stored, err := impl.Download("testresourcebundle", "v1")
+	if err != nil {
+		t.Fatalf("Download returned an unexpected error %s", err)
+	}
+	if !bytes.Equal(stored, tarballs[0]) && !bytes.Equal(stored, tarballs[1]) {
+		t.Fatalf("Expected the stored content to be one of the uploaded bundles")
+	}
+	if err = isTarGz(bytes.NewBuffer(stored)); err != nil {
+		t.Fatalf("Expected the stored content to be a complete tarball (%s)", err)
+	}
+	def, err := impl.Get("testresourcebundle", "v1")
+	if err != nil {
+		t.Fatalf("Get returned an unexpected error %s", err)
+	}
+	if def.ContentSHA256 != ContentDigest(stored) {
+		t.Fatalf("Expected the digest of the stored content, got %s", def.ContentSHA256)
+	}
+}
+
+func TestUpdateDefinitionIfMatch(t *testing.T) {
+	stored := Definition{
+		RBName:      "testresourcebundle",
+		RBVersion:   "v1",
+		ChartName:   "testchart",
+		Description: "test description",
+	}
+
+	testCases := []struct {
+		label         string
+		name          string
+		ifMatch       func(current Definition) string
+		expectedError error
+	}{
+		{
+			label:   "Update without If-Match",
+			name:    "testresourcebundle",
+			ifMatch: func(Definition) string { return "" },
+		},
+		{
+			label:   "Update with the current ETag",
+			name:    "testresourcebundle",
+			ifMatch: func(current Definition) string { return current.ETag() },
+		},
+		{
+			label:   "Update with any ETag",
+			name:    "testresourcebundle",
+			ifMatch: func(Definition) string { return "*" },
+		},
+		{
+			label: "Update with a stale ETag",
+			name:  "testresourcebundle",
+			ifMatch: func(current Definition) string {
+				current.Description = "modified by another client"
+				return current.ETag()
+			},
+			expectedError: ErrPreconditionFailed,
+		},
+		{
+			label:         "Update a missing definition with If-Match",
+			name:          "missing",
+			ifMatch:       func(Definition) string { return "*" },
+			expectedError: ErrPreconditionFailed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			db.DBconn = &db.MockDB{Items: map[string]map[string][]byte{}}
+			impl := NewDefinitionClient()
+			if _, err := impl.Create(stored); err != nil {
+				t.Fatalf("Create returned an unexpected error %s", err)
+			}
+			current, err := impl.Get("testresourcebundle", "v1")
+			if err != nil {
+				t.Fatalf("Get returned an unexpected error %s", err)
+			}
+
+			update := stored
+			update.RBName = testCase.name
+			update.Description = "new description"
+			_, err = impl.Update(update, testCase.ifMatch(current))
+			if !errors.Is(err, testCase.expectedError) {
+				t.Fatalf("Update returned %v, expected %v", err, testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestConcurrentUpdateDefinitionIfMatch(t *testing.T) {
+	store := &overlapStore{MockDB: &db.MockDB{Items: map[string]map[string][]byte{}}}
+	db.DBconn = store
+	impl := NewDefinitionClient()
+	def, err := impl.Create(Definition{RBName: "testresourcebundle", RBVersion: "v1", ChartName: "testchart"})
+	if err != nil {
+		t.Fatalf("Create returned an unexpected error %s", err)
+	}
+	etag := def.ETag()
+
+	// Both clients read the same version, only the first write may succeed
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			update := def
+			update.Description = fmt.Sprintf("update %d", i)
+			_, errs[i] = impl.Update(update, etag)
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if errors.Is(err, ErrPreconditionFailed) {
+			failed++
+		} else if err != nil {
+			t.Fatalf("Update returned an unexpected error %s", err)
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("Expected exactly one update to fail its precondition, got %v", errs)
+	}
+}
+
+func TestKeyedLocks(t *testing.T) {
+	var locks keyedLocks
+
+	unlock := locks.lock("a")
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		locks.lock("a")()
+	}()
+	// A different key doesn't wait for a
+	locks.lock("b")()
+
+	select {
+	case <-locked:
+		t.Fatalf("Expected the second lock of a to wait for the first")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+
+	if len(locks.locks) != 0 {
+		t.Fatalf("Expected the unused locks to be dropped, got %v", locks.locks)
+	}
+}
+
 func TestDownloadDefinition(t *testing.T) {
 	testCases := []struct {
 		label         string
@@ -612,3 +1039,195 @@ func TestDownloadDefinition(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenDefinitionContent(t *testing.T) {
+	metadata := []byte("{\"rb-name\":\"testresourcebundle\",\"rb-version\":\"v1\",\"chart-name\":\"firewall\"}")
+	content := []byte{0x1f, 0x8b, 0x08, 0x00, 0x01, 0x02, 0x03}
+	key := DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String()
+
+	testCases := []struct {
+		label         string
+		version       string
+		items         map[string][]byte
+		expected      []byte
+		expectedError error
+	}{
+		{
+			label:   "Open the uploaded content",
+			version: "v1",
+			items: map[string][]byte{
+				"defmetadata": metadata,
+				"defcontent":  []byte(base64.StdEncoding.EncodeToString(content)),
+			},
+			expected: content,
+		},
+		{
+			label:         "No content was uploaded",
+			version:       "v1",
+			items:         map[string][]byte{"defmetadata": metadata},
+			expectedError: ErrDefinitionContentNotFound,
+		},
+		{
+			label:         "Unknown definition",
+			version:       "v2",
+			items:         map[string][]byte{"defmetadata": metadata},
+			expectedError: ErrDefinitionNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			db.DBconn = &db.MockDB{
+				Items: map[string]map[string][]byte{key: testCase.items},
+			}
+			def, reader, err := NewDefinitionClient().OpenContent("testresourcebundle", testCase.version)
+			if testCase.expectedError != nil {
+				if !errors.Is(err, testCase.expectedError) {
+					t.Fatalf("OpenContent returned %v, expected %v", err, testCase.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenContent returned an unexpected error %s", err)
+			}
+			defer reader.Close()
+			if def.RBName != "testresourcebundle" || def.RBVersion != testCase.version {
+				t.Fatalf("OpenContent returned the definition %+v", def)
+			}
+			data, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Reading the content returned an error %s", err)
+			}
+			if !bytes.Equal(data, testCase.expected) {
+				t.Fatalf("OpenContent returned %v, expected %v", data, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestOpenContentLocksDefinition(t *testing.T) {
+	key := DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String()
+	db.DBconn = &db.MockDB{
+		Items: map[string]map[string][]byte{
+			key: {
+				"defmetadata": []byte("{\"rb-name\":\"testresourcebundle\",\"rb-version\":\"v1\"}"),
+				"defcontent":  []byte(base64.StdEncoding.EncodeToString([]byte("content"))),
+			},
+		},
+	}
+	impl := NewDefinitionClient()
+
+	_, reader, err := impl.OpenContent("testresourcebundle", "v1")
+	if err != nil {
+		t.Fatalf("OpenContent returned an unexpected error %s", err)
+	}
+
+	deleted := make(chan error)
+	go func() {
+		deleted <- impl.Delete("testresourcebundle", "v1", true)
+	}()
+	select {
+	case <-deleted:
+		t.Fatalf("Expected the definition to be deleted only once its content is closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	reader.Close()
+	select {
+	case err := <-deleted:
+		if err != nil {
+			t.Fatalf("Delete returned an unexpected error %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the definition to be deleted once its content is closed")
+	}
+}
+
+func TestCloneDefinition(t *testing.T) {
+	cloned := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	setTimeNow(t, func() time.Time { return cloned })
+
+	var content bytes.Buffer
+	gw := gzip.NewWriter(&content)
+	tw := tar.NewWriter(gw)
+	chart := []byte("name: testchart\n")
+	tw.WriteHeader(&tar.Header{Name: "testchart/Chart.yaml", Mode: 0644, Size: int64(len(chart))})
+	tw.Write(chart)
+	tw.Close()
+	gw.Close()
+
+	metadata := []byte("{\"rb-name\":\"testresourcebundle\",\"rb-version\":\"v1\"," +
+		"\"chart-name\":\"testchart\",\"description\":\"test description\"}")
+
+	testCases := []struct {
+		label         string
+		source        map[string][]byte
+		targetVersion string
+		expected      Definition
+		expectedError error
+	}{
+		{
+			label: "Clone the metadata and the content",
+			source: map[string][]byte{
+				"defmetadata": metadata,
+				"defcontent":  []byte(base64.StdEncoding.EncodeToString(content.Bytes())),
+			},
+			targetVersion: "v2",
+			expected: Definition{
+				RBName:        "testresourcebundle",
+				RBVersion:     "v2",
+				ChartName:     "testchart",
+				Description:   "test description",
+				Labels:        map[string]string{},
+				SchemaVersion: DefinitionSchemaVersion,
+				ContentSHA256: ContentDigest(content.Bytes()),
+				CreatedAt:     "2021-06-01T10:00:00Z",
+				UpdatedAt:     "2021-06-01T10:00:00Z",
+			},
+		},
+		{
+			label:         "Clone to an existing version",
+			source:        map[string][]byte{"defmetadata": metadata},
+			targetVersion: "v1",
+			expectedError: ErrDefinitionExists,
+		},
+		{
+			label:         "Clone a missing definition",
+			targetVersion: "v2",
+			expectedError: ErrDefinitionNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			items := map[string]map[string][]byte{}
+			if testCase.source != nil {
+				items[DefinitionKey{RBName: "testresourcebundle", RBVersion: "v1"}.String()] = testCase.source
+			}
+			db.DBconn = &db.MockDB{Items: items}
+			impl := NewDefinitionClient()
+
+			got, err := impl.Clone("testresourcebundle", "v1", testCase.targetVersion)
+			if testCase.expectedError != nil {
+				if !errors.Is(err, testCase.expectedError) {
+					t.Fatalf("Clone returned %v, expected %v", err, testCase.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Clone returned an unexpected error %s", err)
+			}
+			if !reflect.DeepEqual(got, testCase.expected) {
+				t.Errorf("Clone returned unexpected body: got %v; expected %v", got, testCase.expected)
+			}
+
+			cloned, err := impl.Download("testresourcebundle", testCase.targetVersion)
+			if err != nil {
+				t.Fatalf("Download of the clone returned an error %s", err)
+			}
+			if !bytes.Equal(cloned, content.Bytes()) {
+				t.Errorf("Cloned content differs from the source content")
+			}
+		})
+	}
+}
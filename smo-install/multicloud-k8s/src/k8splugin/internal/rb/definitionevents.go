@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rb
+
+import (
+	"log"
+	"sync"
+)
+
+// Types of the events published for Definition changes
+const (
+	DefinitionCreated = "create"
+	DefinitionUpdated = "update"
+	DefinitionDeleted = "delete"
+)
+
+// DefinitionEvent describes a change of a Definition
+type DefinitionEvent struct {
+	Type       string     `json:"type"`
+	Definition Definition `json:"definition"`
+}
+
+// definitionEventBufferSize is the number of events queued for a watcher
+// before new events are dropped for it
+const definitionEventBufferSize = 64
+
+// definitionBroadcaster fans out Definition events to all watchers.
+// It is shared by all DefinitionClients so that changes made through any
+// of them are seen by every watcher.
+type definitionBroadcaster struct {
+	sync.Mutex
+	watchers map[chan DefinitionEvent]struct{}
+}
+
+var definitionEvents = &definitionBroadcaster{
+	watchers: map[chan DefinitionEvent]struct{}{},
+}
+
+// subscribe registers a new watcher. The returned function unregisters it
+// and closes its channel.
+func (b *definitionBroadcaster) subscribe() (<-chan DefinitionEvent, func()) {
+	ch := make(chan DefinitionEvent, definitionEventBufferSize)
+	b.Lock()
+	b.watchers[ch] = struct{}{}
+	b.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.Lock()
+			delete(b.watchers, ch)
+			b.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish sends the event to all watchers without blocking on slow ones
+func (b *definitionBroadcaster) publish(eventType string, def Definition) {
+	event := DefinitionEvent{Type: eventType, Definition: def}
+	b.Lock()
+	defer b.Unlock()
+	for ch := range b.watchers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("[Definition] Dropping %s event of %s/%s for a slow watcher",
+				eventType, def.RBName, def.RBVersion)
+		}
+	}
+}
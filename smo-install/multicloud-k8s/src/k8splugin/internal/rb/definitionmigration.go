@@ -0,0 +1,48 @@
+/*
+ * Copyright 2018 Intel Corporation, Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rb
+
+// DefinitionSchemaVersion is the schema version of the definitions
+// written by this release
+const DefinitionSchemaVersion = len(definitionMigrations)
+
+// definitionMigrations upgrade a stored definition from the schema version
+// equal to their index to the next one. Records written before versioning
+// was introduced have no schema-version and read as version 0.
+// Append a migration here whenever a field is added to Definition that
+// needs a default for older records.
+var definitionMigrations = [...]func(def *Definition){
+	// 0 -> 1: labels were optional and could be stored as null
+	func(def *Definition) {
+		if def.Labels == nil {
+			def.Labels = map[string]string{}
+		}
+	},
+}
+
+// migrateDefinition upgrades a definition read from the database to the
+// current schema version. The stored record is left as it is and gets
+// rewritten in the new shape on its next update.
+func migrateDefinition(def *Definition) {
+	if def.SchemaVersion < 0 {
+		def.SchemaVersion = 0
+	}
+	for v := def.SchemaVersion; v < DefinitionSchemaVersion; v++ {
+		definitionMigrations[v](def)
+	}
+	def.SchemaVersion = DefinitionSchemaVersion
+}
@@ -14,6 +14,9 @@ limitations under the License.
 package utils
 
 import (
+	"bufio"
+	"bytes"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -21,12 +24,16 @@ import (
 	"path/filepath"
 	"plugin"
 	"strings"
+	"sync"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/db"
 
 	pkgerrors "github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -42,30 +49,152 @@ type ResourceData struct {
 	VnfId        string
 }
 
-// DecodeYAML reads a YAMl file to extract the Kubernetes object definition
+// decodeScheme holds the types DecodeYAML can decode into. It starts with
+// the built-in Kubernetes types and can be extended with RegisterScheme.
+var (
+	decodeMutex  sync.RWMutex
+	decodeScheme = runtime.NewScheme()
+	decodeCodecs serializer.CodecFactory
+)
+
+func init() {
+	utilruntime.Must(scheme.AddToScheme(decodeScheme))
+	decodeCodecs = serializer.NewCodecFactory(decodeScheme)
+}
+
+// RegisterScheme adds types to the scheme used by DecodeYAML, so that
+// custom resources can be decoded into their Go structs. addToScheme is
+// typically the AddToScheme function generated for an API group.
+func RegisterScheme(addToScheme func(*runtime.Scheme) error) error {
+	decodeMutex.Lock()
+	defer decodeMutex.Unlock()
+
+	if err := addToScheme(decodeScheme); err != nil {
+		return pkgerrors.Wrap(err, "Register scheme error")
+	}
+	decodeCodecs = serializer.NewCodecFactory(decodeScheme)
+	return nil
+}
+
+// DecodeYAML reads a YAMl file to extract the Kubernetes object definition.
+// JSON files are accepted as well, see DecodeManifest.
 func DecodeYAML(path string, into runtime.Object) (runtime.Object, error) {
+	return DecodeManifest(path, into)
+}
+
+// DecodeManifest reads a JSON or YAML file to extract the Kubernetes object
+// definition. The format is detected from the first non-whitespace byte,
+// an opening brace for JSON.
+func DecodeManifest(path string, into runtime.Object) (runtime.Object, error) {
+	rawBytes, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeManifestBytes(rawBytes, into)
+}
+
+// DecodeManifestDocuments reads a JSON file, or a YAML file holding one or
+// more documents separated by "---", and decodes each of them. Documents
+// that are empty or only hold comments are skipped.
+func DecodeManifestDocuments(path string) ([]runtime.Object, error) {
+	rawBytes, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeManifestDocumentsBytes(rawBytes)
+}
+
+// DecodeManifestDocumentsBytes is DecodeManifestDocuments for a manifest
+// that is already in memory
+func DecodeManifestDocumentsBytes(rawBytes []byte) ([]runtime.Object, error) {
+	if isJSONManifest(rawBytes) {
+		obj, err := DecodeManifestBytes(rawBytes, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []runtime.Object{obj}, nil
+	}
+
+	var objs []runtime.Object
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(rawBytes)))
+	for index := 0; ; index++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Read YAML document %d error", index)
+		}
+		if isEmptyYAMLDocument(doc) {
+			continue
+		}
+		obj, err := DecodeManifestBytes(doc, nil)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "Document %d", index)
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+func readManifest(path string) ([]byte, error) {
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
 			return nil, pkgerrors.New("File " + path + " not found")
-		} else {
-			return nil, pkgerrors.Wrap(err, "Stat file error")
 		}
+		return nil, pkgerrors.Wrap(err, "Stat file error")
 	}
 
 	rawBytes, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, pkgerrors.Wrap(err, "Read YAML file error")
+		return nil, pkgerrors.Wrap(err, "Read manifest file error")
 	}
+	return rawBytes, nil
+}
 
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	obj, _, err := decode(rawBytes, nil, into)
+// DecodeManifestBytes decodes a single JSON or YAML document that is
+// already in memory
+func DecodeManifestBytes(data []byte, into runtime.Object) (runtime.Object, error) {
+	format := "JSON"
+	if !isJSONManifest(data) {
+		format = "YAML"
+		converted, err := utilyaml.ToJSON(data)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Deserialize YAML error")
+		}
+		data = converted
+	}
+
+	decodeMutex.RLock()
+	decode := decodeCodecs.UniversalDeserializer().Decode
+	decodeMutex.RUnlock()
+	obj, _, err := decode(data, nil, into)
 	if err != nil {
-		return nil, pkgerrors.Wrap(err, "Deserialize YAML error")
+		return nil, pkgerrors.Wrapf(err, "Deserialize %s error", format)
 	}
 
 	return obj, nil
 }
 
+// isJSONManifest reports whether data starts with a JSON object
+func isJSONManifest(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+func isEmptyYAMLDocument(doc []byte) bool {
+	for _, line := range bytes.Split(doc, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' && !bytes.Equal(line, []byte("---")) {
+			return false
+		}
+	}
+	return true
+}
+
 // CheckDatabaseConnection checks if the database is up and running and
 // plugin can talk to it
 func CheckDatabaseConnection() error {
@@ -14,6 +14,10 @@ limitations under the License.
 package utils
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -21,6 +25,7 @@ import (
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestDecodeYAML(t *testing.T) {
@@ -91,3 +96,165 @@ func TestDecodeYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeManifestDocuments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "utils")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "services.yaml")
+	content := `---
+# Source: chart/templates/empty.yaml
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm-a
+`
+	if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write manifest (%s)", err)
+	}
+
+	objs, err := DecodeManifestDocuments(path)
+	if err != nil {
+		t.Fatalf("DecodeManifestDocuments returned an error (%s)", err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("DecodeManifestDocuments returned %d objects, expected 2", len(objs))
+	}
+	if _, ok := objs[0].(*coreV1.Service); !ok {
+		t.Fatalf("Expected a Service, got %T", objs[0])
+	}
+	if _, ok := objs[1].(*coreV1.ConfigMap); !ok {
+		t.Fatalf("Expected a ConfigMap, got %T", objs[1])
+	}
+}
+
+func TestDecodeManifestFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "utils")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifests := map[string]string{
+		"service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-a
+  labels:
+    app: a
+spec:
+  ports:
+  - name: http
+    port: 80
+`,
+		"service.json": `
+  {
+    "apiVersion": "v1",
+    "kind": "Service",
+    "metadata": {"name": "svc-a", "labels": {"app": "a"}},
+    "spec": {"ports": [{"name": "http", "port": 80}]}
+  }`,
+	}
+
+	decoded := map[string]runtime.Object{}
+	for name, content := range manifests {
+		path := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write manifest (%s)", err)
+		}
+		obj, err := DecodeManifest(path, nil)
+		if err != nil {
+			t.Fatalf("DecodeManifest returned an error for %s (%s)", name, err)
+		}
+		if _, ok := obj.(*coreV1.Service); !ok {
+			t.Fatalf("Expected a Service from %s, got %T", name, obj)
+		}
+		decoded[name] = obj
+
+		objs, err := DecodeManifestDocuments(path)
+		if err != nil || len(objs) != 1 {
+			t.Fatalf("DecodeManifestDocuments returned %d objects for %s (%v)", len(objs), name, err)
+		}
+		if !reflect.DeepEqual(objs[0], obj) {
+			t.Fatalf("DecodeManifestDocuments returned %v for %s, expected %v", objs[0], name, obj)
+		}
+	}
+
+	if !reflect.DeepEqual(decoded["service.json"], decoded["service.yaml"]) {
+		t.Fatalf("JSON and YAML manifests decoded differently:\n%v\n%v",
+			decoded["service.json"], decoded["service.yaml"])
+	}
+}
+
+// widget is a custom resource type used to test scheme registration
+type widget struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		Size int `json:"size"`
+	} `json:"spec"`
+}
+
+func (w *widget) DeepCopyObject() runtime.Object {
+	out := *w
+	w.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return &out
+}
+
+func TestRegisterScheme(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decode-yaml")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "widget.yaml")
+	content := []byte(`apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: mock-widget
+spec:
+  size: 3
+`)
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("Unable to write manifest (%s)", err)
+	}
+
+	if _, err = DecodeYAML(path, nil); err == nil {
+		t.Fatal("Decode YAML method decoded an unregistered kind")
+	}
+
+	gv := schema.GroupVersion{Group: "example.com", Version: "v1"}
+	err = RegisterScheme(func(s *runtime.Scheme) error {
+		s.AddKnownTypeWithName(gv.WithKind("Widget"), &widget{})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register scheme method returned an error (%s)", err)
+	}
+
+	result, err := DecodeYAML(path, nil)
+	if err != nil {
+		t.Fatalf("Decode YAML method returned an error (%s)", err)
+	}
+	w, ok := result.(*widget)
+	if !ok {
+		t.Fatalf("Decode YAML method returned %T, expected *widget", result)
+	}
+	if w.Name != "mock-widget" || w.Spec.Size != 3 {
+		t.Fatalf("Decode YAML method returned an unexpected widget %+v", w)
+	}
+
+	// Built-in kinds are still decoded
+	if _, err = DecodeYAML("../../mock_files/mock_yamls/deployment.yaml", nil); err != nil {
+		t.Fatalf("Decode YAML method returned an error (%s)", err)
+	}
+}
@@ -15,11 +15,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
+
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"time"
@@ -28,10 +32,17 @@ import (
 // ExportedVariable is what we will look for when calling the plugin
 var ExportedVariable mockPlugin
 
+// WatchedTimeouts records the timeout of the last WatchUntilReady per kind
+var WatchedTimeouts = map[string]time.Duration{}
+
+// CreatedOwners records the owner references set on Create per manifest
+var CreatedOwners = map[string][]metav1.OwnerReference{}
+
 type mockPlugin struct {
 }
 
 func (g mockPlugin) WatchUntilReady(
+	ctx context.Context,
 	timeout time.Duration,
 	ns string,
 	res helm.KubernetesResource,
@@ -39,16 +50,20 @@ func (g mockPlugin) WatchUntilReady(
 	restClient rest.Interface,
 	objType runtime.Object,
 	clientSet kubernetes.Interface) error {
+	WatchedTimeouts[res.GVK.Kind] = timeout
 	return nil
 }
 
 // Create object in a specific Kubernetes resource
-func (p mockPlugin) Create(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p mockPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	var obj metav1.ObjectMeta
+	plugin.SetOwnerReference(&obj, namespace, client)
+	CreatedOwners[yamlFilePath] = obj.OwnerReferences
 	return "resource-name", nil
 }
 
 // List of existing resources
-func (p mockPlugin) List(gvk schema.GroupVersionKind, namespace string,
+func (p mockPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string,
 	client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
 	returnVal := []helm.KubernetesResource{
 		{
@@ -62,17 +77,23 @@ func (p mockPlugin) List(gvk schema.GroupVersionKind, namespace string,
 }
 
 // Delete existing resources
-func (p mockPlugin) Delete(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+func (p mockPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
 	return nil
 }
 
 // Get existing resource host
-func (p mockPlugin) Get(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p mockPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return resource.Name, nil
+}
+
+// Patch existing resources
+func (p mockPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
 	return resource.Name, nil
 }
 
 // Update existing resources
-func (p mockPlugin) Update(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p mockPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
 
         return "", nil
 }
@@ -0,0 +1,147 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if configMapPlugin implements the correct interface
+var _ plugin.Reference = configMapPlugin{}
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable configMapPlugin
+
+// configMapPlugin manages ConfigMaps
+type configMapPlugin struct {
+}
+
+// configMaps implements the methods of configMapPlugin shared with the
+// plugins of the other kinds
+var configMaps = plugin.TypedResource{
+	GVK: coreV1.SchemeGroupVersion.WithKind("ConfigMap"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return configMapClient{clientSet.CoreV1().ConfigMaps(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeConfigMap(yamlFilePath)
+	},
+}
+
+// WatchUntilReady checks that the ConfigMap exists, ConfigMaps have no readiness
+func (g configMapPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	_, err := configMaps.GetObject(ctx, res.Name, ns, clientSet)
+	return err
+}
+
+// Create a configmap object in a specific Kubernetes cluster
+func (p configMapPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return configMaps.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing configmaps hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to configmaps only
+func (p configMapPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return configMaps.List(ctx, namespace, client)
+}
+
+// Delete an existing configmap hosted in a specific Kubernetes cluster
+func (p configMapPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return configMaps.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing configmap hosted in a specific Kubernetes cluster
+func (p configMapPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return configMaps.Get(ctx, resource, namespace, client)
+}
+
+// Update a configmap object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p configMapPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return configMaps.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a configmap object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p configMapPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return configMaps.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodeConfigMap(yamlFilePath string) (*coreV1.ConfigMap, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode configmap object error")
+	}
+
+	configMap, ok := obj.(*coreV1.ConfigMap)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than ConfigMap")
+	}
+	return configMap, nil
+}
+
+// configMapClient adapts the ConfigMap client to plugin.TypedClient
+type configMapClient struct {
+	configMaps typedCoreV1.ConfigMapInterface
+}
+
+func (c configMapClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.configMaps.Create(ctx, obj.(*coreV1.ConfigMap), opts)
+}
+
+func (c configMapClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.configMaps.Get(ctx, name, opts)
+}
+
+func (c configMapClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.configMaps.List(ctx, opts)
+}
+
+func (c configMapClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.configMaps.Update(ctx, obj.(*coreV1.ConfigMap), opts)
+}
+
+func (c configMapClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.configMaps.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c configMapClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.configMaps.Delete(ctx, name, opts)
+}
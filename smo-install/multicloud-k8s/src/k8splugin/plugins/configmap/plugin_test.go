@@ -0,0 +1,128 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const configMapManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: mock-configmap
+data:
+  log-level: %s
+`
+
+func TestCreateConfigMap(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create a configmap with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than ConfigMap",
+		},
+		{
+			label:    "Successfully create a configmap",
+			manifest: fmt.Sprintf(configMapManifest, "info"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := configMapPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-configmap" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-configmap")
+			}
+
+			configMap, err := client.GetStandardClient().CoreV1().ConfigMaps("test1").
+				Get(context.TODO(), "mock-configmap", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected configmap to be created (%s)", err)
+			}
+			if configMap.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected configmap to be labeled with the instance ID, got %v", configMap.Labels)
+			}
+		})
+	}
+}
+
+func TestListGetDeleteConfigMap(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.ConfigMap{ObjectMeta: metaV1.ObjectMeta{Name: "settings", Namespace: "default"}},
+		&coreV1.ConfigMap{ObjectMeta: metaV1.ObjectMeta{Name: "scripts", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	gvk := coreV1.SchemeGroupVersion.WithKind("ConfigMap")
+
+	plugintest.CheckList(t, configMapPlugin{}, client, gvk, "scripts", "settings")
+	plugintest.CheckGetDelete(t, configMapPlugin{}, client, helm.KubernetesResource{GVK: gvk, Name: "settings"})
+}
+
+func TestUpdateConfigMap(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+
+	// The configmap is created when it doesn't exist
+	_, err := configMapPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(configMapManifest, "info")), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	_, err = configMapPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(configMapManifest, "debug")), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	configMap, err := client.GetStandardClient().CoreV1().ConfigMaps("test1").
+		Get(context.TODO(), "mock-configmap", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get configmap (%s)", err)
+	}
+	if !reflect.DeepEqual(configMap.Data, map[string]string{"log-level": "debug"}) {
+		t.Fatalf("Expected the updated data to be stored, got %v", configMap.Data)
+	}
+	if configMap.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected configmap to be labeled with the instance ID, got %v", configMap.Labels)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2018 Intel Corporation.
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedBatchV1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if cronJobPlugin implements the correct interface
+var _ plugin.Reference = cronJobPlugin{}
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable cronJobPlugin
+
+// cronJobPlugin manages batch/v1 CronJobs, served by Kubernetes 1.21 and
+// later. Manifests of batch/v1beta1 CronJobs are converted to batch/v1.
+type cronJobPlugin struct {
+}
+
+// cronJobs implements the methods of cronJobPlugin shared with the
+// plugins of the other kinds
+var cronJobs = plugin.TypedResource{
+	GVK: batchV1.SchemeGroupVersion.WithKind("CronJob"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return cronJobClient{clientSet.BatchV1().CronJobs(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeCronJob(yamlFilePath)
+	},
+	SetInstanceLabel: func(obj plugin.Object, instanceID string) {
+		setInstanceLabel(obj.(*batchV1.CronJob), instanceID)
+	},
+}
+
+// WatchUntilReady only checks that the CronJob exists, CronJobs have no
+// readiness. The Jobs it schedules are not waited for.
+func (g cronJobPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	_, err := cronJobs.GetObject(ctx, res.Name, ns, clientSet)
+	return err
+}
+
+// Create a cronjob object in a specific Kubernetes cluster
+func (p cronJobPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return cronJobs.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing cronjobs hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to cronjobs only
+func (p cronJobPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return cronJobs.List(ctx, namespace, client)
+}
+
+// Delete an existing cronjob hosted in a specific Kubernetes cluster
+func (p cronJobPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return cronJobs.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing cronjob hosted in a specific Kubernetes cluster
+func (p cronJobPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return cronJobs.Get(ctx, resource, namespace, client)
+}
+
+// Update a cronjob object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p cronJobPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return cronJobs.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a cronjob object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p cronJobPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return cronJobs.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+// decodeCronJob decodes a batch/v1 or batch/v1beta1 CronJob, the latter is
+// converted to batch/v1
+func decodeCronJob(yamlFilePath string) (*batchV1.CronJob, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode cronjob object error")
+	}
+
+	switch cronJob := obj.(type) {
+	case *batchV1.CronJob:
+		return cronJob, nil
+	case *batchV1beta1.CronJob:
+		converted := &batchV1.CronJob{}
+		err = plugin.ConvertVersion(cronJob, converted, batchV1.SchemeGroupVersion.WithKind("CronJob"))
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Convert cronjob object error")
+		}
+		return converted, nil
+	}
+	return nil, pkgerrors.New("Decoded object contains another resource different than CronJob")
+}
+
+// setInstanceLabel adds the instance label to the cronjob and to the jobs
+// and pods it creates
+func setInstanceLabel(cronJob *batchV1.CronJob, instanceID string) {
+	for _, obj := range []metaV1.Object{cronJob, &cronJob.Spec.JobTemplate, &cronJob.Spec.JobTemplate.Spec.Template} {
+		plugin.SetInstanceLabel(obj, instanceID)
+	}
+}
+
+// cronJobClient adapts the CronJob client to plugin.TypedClient
+type cronJobClient struct {
+	cronJobs typedBatchV1.CronJobInterface
+}
+
+func (c cronJobClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.cronJobs.Create(ctx, obj.(*batchV1.CronJob), opts)
+}
+
+func (c cronJobClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.cronJobs.Get(ctx, name, opts)
+}
+
+func (c cronJobClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.cronJobs.List(ctx, opts)
+}
+
+func (c cronJobClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.cronJobs.Update(ctx, obj.(*batchV1.CronJob), opts)
+}
+
+func (c cronJobClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.cronJobs.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c cronJobClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.cronJobs.Delete(ctx, name, opts)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	pkgerrors "github.com/pkg/errors"
+	batchV1 "k8s.io/api/batch/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const cronJobManifest = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: mock-cronjob
+spec:
+  schedule: "0 3 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          restartPolicy: OnFailure
+          containers:
+          - name: cleanup
+            image: busybox
+`
+
+func TestCreateCronJob(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create a cronjob from a Job",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: mock-job
+`,
+			expectedError: "contains another resource different than CronJob",
+		},
+		{
+			label:         "Fail to create a cronjob from invalid YAML",
+			manifest:      "kind: [CronJob",
+			expectedError: "Decode cronjob object error",
+		},
+		{
+			label:    "Successfully create a cronjob",
+			manifest: cronJobManifest,
+		},
+		{
+			label:    "Successfully create a batch/v1beta1 cronjob",
+			manifest: strings.Replace(cronJobManifest, "batch/v1", "batch/v1beta1", 1),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := cronJobPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-cronjob" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-cronjob")
+			}
+
+			cronJob, err := client.GetStandardClient().BatchV1().CronJobs("test1").
+				Get(context.TODO(), "mock-cronjob", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected cronjob to be created (%s)", err)
+			}
+			if cronJob.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected cronjob to be labeled with the instance ID, got %v", cronJob.Labels)
+			}
+			if cronJob.Spec.JobTemplate.Spec.Template.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected pods to be labeled with the instance ID, got %v",
+					cronJob.Spec.JobTemplate.Spec.Template.Labels)
+			}
+		})
+	}
+}
+
+func TestDeleteCronJob(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&batchV1.CronJob{ObjectMeta: metaV1.ObjectMeta{Name: "cleanup", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	res := helm.KubernetesResource{GVK: batchV1.SchemeGroupVersion.WithKind("CronJob"), Name: "cleanup"}
+
+	plugintest.CheckGetDelete(t, cronJobPlugin{}, client, res)
+}
+
+func TestWatchCronJobUntilReady(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&batchV1.CronJob{ObjectMeta: metaV1.ObjectMeta{Name: "cleanup", Namespace: "default"}},
+	)
+
+	err := cronJobPlugin{}.WatchUntilReady(context.TODO(), 0, "", helm.KubernetesResource{Name: "cleanup"}, nil, nil, nil, clientSet)
+	if err != nil {
+		t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+	}
+	err = cronJobPlugin{}.WatchUntilReady(context.TODO(), 0, "", helm.KubernetesResource{Name: "missing"}, nil, nil, nil, clientSet)
+	if !k8serrors.IsNotFound(pkgerrors.Cause(err)) {
+		t.Fatalf("Expected a missing cronjob to be reported, got %v", err)
+	}
+}
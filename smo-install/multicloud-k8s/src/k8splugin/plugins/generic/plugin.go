@@ -37,7 +37,9 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/app"
@@ -59,6 +61,7 @@ type genericPlugin struct {
 }
 
 func (g genericPlugin) WatchUntilReady(
+	ctx context.Context,
 	timeout time.Duration,
 	ns string,
 	res helm.KubernetesResource,
@@ -85,7 +88,7 @@ func (g genericPlugin) WatchUntilReady(
 	// - For all else, we watch until Ready.
 	// In the future, we might want to add some special logic for types
 	// like Ingress, Volume, etc.
-	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(ctx, timeout)
 	defer cancel()
 
 	_, err = watchtools.UntilWithSync(ctx, lw, objType, nil, func(e watch.Event) (bool, error) {
@@ -285,11 +288,7 @@ func (g genericPlugin) waitForStatefulSetSuccess(obj runtime.Object, name string
 }
 
 // Create generic object in a specific Kubernetes cluster
-func (g genericPlugin) Create(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
-	if namespace == "" {
-		namespace = "default"
-	}
-
+func (g genericPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
 	//Decode the yaml file to create a runtime.Object
 	unstruct := &unstructured.Unstructured{}
 	//Ignore the returned obj as we expect the data in unstruct
@@ -311,7 +310,7 @@ func (g genericPlugin) Create(yamlFilePath string, namespace string, client plug
 		resource := helm.KubernetesResource{}
 		resource.GVK = gvk
 		resource.Name = unstruct.GetName()
-		name, err := g.Get(resource, namespace, client)
+		name, err := g.Get(ctx, resource, namespace, client)
 		if err == nil && name == resource.Name {
 			//CRD update is not supported according to Helm spec
 			log.Warn(fmt.Sprintf("CRD %s create will be skipped. It already exists", name))
@@ -319,13 +318,8 @@ func (g genericPlugin) Create(yamlFilePath string, namespace string, client plug
 		}
 	}
 	//Add the tracking label to all resources created here
-	labels := unstruct.GetLabels()
-	//Check if labels exist for this object
-	if labels == nil {
-		labels = map[string]string{}
-	}
-	labels[config.GetConfiguration().KubernetesLabelName] = client.GetInstanceID()
-	unstruct.SetLabels(labels)
+	plugin.SetInstanceLabel(unstruct, client.GetInstanceID())
+	plugin.FilterFinalizers(unstruct)
 
 	// This checks if the resource we are creating has a podSpec in it
 	// Eg: Deployment, StatefulSet, Job etc..
@@ -337,9 +331,15 @@ func (g genericPlugin) Create(yamlFilePath string, namespace string, client plug
 
 	switch mapping.Scope.Name() {
 	case meta.RESTScopeNameNamespace:
-		createdObj, err = dynClient.Resource(gvr).Namespace(namespace).Create(context.TODO(), unstruct, metav1.CreateOptions{})
+		namespace, err = plugin.ResolveNamespace(unstruct, namespace)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Resolve namespace error")
+		}
+		plugin.SetOwnerReference(unstruct, namespace, client)
+		createdObj, err = dynClient.Resource(gvr).Namespace(namespace).Create(ctx, unstruct, metav1.CreateOptions{})
 	case meta.RESTScopeNameRoot:
-		createdObj, err = dynClient.Resource(gvr).Create(context.TODO(), unstruct, metav1.CreateOptions{})
+		plugin.SetOwnerReference(unstruct, "", client)
+		createdObj, err = dynClient.Resource(gvr).Create(ctx, unstruct, metav1.CreateOptions{})
 	default:
 		return "", pkgerrors.New("Got an unknown RESTSCopeName for mapping: " + gvk.String())
 	}
@@ -352,11 +352,7 @@ func (g genericPlugin) Create(yamlFilePath string, namespace string, client plug
 }
 
 // Update deployment object in a specific Kubernetes cluster
-func (g genericPlugin) Update(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
-	if namespace == "" {
-		namespace = "default"
-	}
-
+func (g genericPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
 	//Decode the yaml file to create a runtime.Object
 	unstruct := &unstructured.Unstructured{}
 	//Ignore the returned obj as we expect the data in unstruct
@@ -378,7 +374,7 @@ func (g genericPlugin) Update(yamlFilePath string, namespace string, client plug
 		resource := helm.KubernetesResource{}
 		resource.GVK = gvk
 		resource.Name = unstruct.GetName()
-		name, err := g.Get(resource, namespace, client)
+		name, err := g.Get(ctx, resource, namespace, client)
 		if err == nil && name == resource.Name {
 			//CRD update is not supported according to Helm spec
 			log.Warn(fmt.Sprintf("CRD %s update will be skipped", name))
@@ -387,13 +383,7 @@ func (g genericPlugin) Update(yamlFilePath string, namespace string, client plug
 	}
 
 	//Add the tracking label to all resources created here
-	labels := unstruct.GetLabels()
-	//Check if labels exist for this object
-	if labels == nil {
-		labels = map[string]string{}
-	}
-	labels[config.GetConfiguration().KubernetesLabelName] = client.GetInstanceID()
-	unstruct.SetLabels(labels)
+	plugin.SetInstanceLabel(unstruct, client.GetInstanceID())
 
 	// This checks if the resource we are creating has a podSpec in it
 	// Eg: Deployment, StatefulSet, Job etc..
@@ -405,9 +395,15 @@ func (g genericPlugin) Update(yamlFilePath string, namespace string, client plug
 
 	switch mapping.Scope.Name() {
 	case meta.RESTScopeNameNamespace:
-		updatedObj, err = dynClient.Resource(gvr).Namespace(namespace).Update(context.TODO(), unstruct, metav1.UpdateOptions{})
+		namespace, err = plugin.ResolveNamespace(unstruct, namespace)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Resolve namespace error")
+		}
+		plugin.SetOwnerReference(unstruct, namespace, client)
+		updatedObj, err = dynClient.Resource(gvr).Namespace(namespace).Update(ctx, unstruct, metav1.UpdateOptions{})
 	case meta.RESTScopeNameRoot:
-		updatedObj, err = dynClient.Resource(gvr).Update(context.TODO(), unstruct, metav1.UpdateOptions{})
+		plugin.SetOwnerReference(unstruct, "", client)
+		updatedObj, err = dynClient.Resource(gvr).Update(ctx, unstruct, metav1.UpdateOptions{})
 	default:
 		return "", pkgerrors.New("Got an unknown RESTSCopeName for mapping: " + gvk.String())
 	}
@@ -420,7 +416,7 @@ func (g genericPlugin) Update(yamlFilePath string, namespace string, client plug
 }
 
 // Get an existing resource hosted in a specific Kubernetes cluster
-func (g genericPlugin) Get(resource helm.KubernetesResource,
+func (g genericPlugin) Get(ctx context.Context, resource helm.KubernetesResource,
 	namespace string, client plugin.KubernetesConnector) (string, error) {
 	if namespace == "" {
 		namespace = "default"
@@ -442,9 +438,9 @@ func (g genericPlugin) Get(resource helm.KubernetesResource,
 	var unstruct *unstructured.Unstructured
 	switch mapping.Scope.Name() {
 	case meta.RESTScopeNameNamespace:
-		unstruct, err = dynClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), resource.Name, opts)
+		unstruct, err = dynClient.Resource(gvr).Namespace(namespace).Get(ctx, resource.Name, opts)
 	case meta.RESTScopeNameRoot:
-		unstruct, err = dynClient.Resource(gvr).Get(context.TODO(), resource.Name, opts)
+		unstruct, err = dynClient.Resource(gvr).Get(ctx, resource.Name, opts)
 	default:
 		return "", pkgerrors.New("Got an unknown RESTSCopeName for mapping: " + resource.GVK.String())
 	}
@@ -458,15 +454,66 @@ func (g genericPlugin) Get(resource helm.KubernetesResource,
 
 // List all existing resources of the GroupVersionKind
 // TODO: Implement in seperate patch
-func (g genericPlugin) List(gvk schema.GroupVersionKind, namespace string,
+func (g genericPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string,
 	client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
 
 	var returnData []helm.KubernetesResource
 	return returnData, nil
 }
 
+// Patch an existing resource hosted in a specific Kubernetes cluster
+// The instance label is set again if the patch removed or changed it
+func (g genericPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	dynClient := client.GetDynamicClient()
+	mapper := client.GetMapper()
+
+	mapping, err := mapper.RESTMapping(schema.GroupKind{
+		Group: resource.GVK.Group,
+		Kind:  resource.GVK.Kind,
+	}, resource.GVK.Version)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Mapping kind to resource error")
+	}
+
+	var resClient dynamic.ResourceInterface
+	switch mapping.Scope.Name() {
+	case meta.RESTScopeNameNamespace:
+		resClient = dynClient.Resource(mapping.Resource).Namespace(namespace)
+	case meta.RESTScopeNameRoot:
+		resClient = dynClient.Resource(mapping.Resource)
+	default:
+		return "", pkgerrors.New("Got an unknown RESTSCopeName for mapping: " + resource.GVK.String())
+	}
+
+	opts := metav1.PatchOptions{
+		FieldManager: plugin.FieldManager(client),
+	}
+	patchedObj, err := resClient.Patch(ctx, resource.Name, patchType, patchData, opts)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Patch object error")
+	}
+
+	if patchedObj.GetLabels()[config.GetConfiguration().KubernetesLabelName] != client.GetInstanceID() {
+		labelPatch, err := plugin.InstanceLabelPatch(client)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Marshal label patch error")
+		}
+		patchedObj, err = resClient.Patch(ctx, resource.Name, types.MergePatchType, labelPatch, opts)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Restore instance label error")
+		}
+	}
+
+	return patchedObj.GetName(), nil
+}
+
 // Delete an existing resource hosted in a specific Kubernetes cluster
-func (g genericPlugin) Delete(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+func (g genericPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
 	if namespace == "" {
 		namespace = "default"
 	}
@@ -495,9 +542,9 @@ func (g genericPlugin) Delete(resource helm.KubernetesResource, namespace string
 
 	switch mapping.Scope.Name() {
 	case meta.RESTScopeNameNamespace:
-		err = dynClient.Resource(gvr).Namespace(namespace).Delete(context.TODO(), resource.Name, opts)
+		err = dynClient.Resource(gvr).Namespace(namespace).Delete(ctx, resource.Name, opts)
 	case meta.RESTScopeNameRoot:
-		err = dynClient.Resource(gvr).Delete(context.TODO(), resource.Name, opts)
+		err = dynClient.Resource(gvr).Delete(ctx, resource.Name, opts)
 	default:
 		return pkgerrors.New("Got an unknown RESTSCopeName for mapping: " + resource.GVK.String())
 	}
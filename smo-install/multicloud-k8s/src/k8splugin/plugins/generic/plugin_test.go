@@ -0,0 +1,152 @@
+/*
+Copyright 2021 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+)
+
+// ownerConnector is a connector whose resources are owned by owner, in
+// ownerNamespace
+type ownerConnector struct {
+	dynamicClient  dynamic.Interface
+	mapper         meta.RESTMapper
+	owner          *metav1.OwnerReference
+	ownerNamespace string
+}
+
+func (t ownerConnector) GetMapper() meta.RESTMapper {
+	return t.mapper
+}
+
+func (t ownerConnector) GetDynamicClient() dynamic.Interface {
+	return t.dynamicClient
+}
+
+func (t ownerConnector) GetStandardClient() kubernetes.Interface {
+	return nil
+}
+
+func (t ownerConnector) GetInstanceID() string {
+	return "inst1"
+}
+
+func (t ownerConnector) GetOwnerReference() *metav1.OwnerReference {
+	return t.owner
+}
+
+func (t ownerConnector) GetOwnerNamespace() string {
+	return t.ownerNamespace
+}
+
+func TestCreateOwnerReference(t *testing.T) {
+	conf := config.GetConfiguration()
+	oldPolicy := conf.NamespaceConflictPolicy
+	defer func() {
+		conf.NamespaceConflictPolicy = oldPolicy
+	}()
+	conf.NamespaceConflictPolicy = plugin.NamespaceConflictPreferManifest
+
+	owner := metav1.OwnerReference{
+		APIVersion: "k8splugin.io/v1alpha1",
+		Kind:       "ResourceBundleState",
+		Name:       "inst1-rbs",
+		UID:        "bundle-uid",
+	}
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	clusterRoleGVR := schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+
+	testCases := []struct {
+		label     string
+		input     string
+		resource  schema.GroupVersionResource
+		namespace string
+		expected  []metav1.OwnerReference
+	}{
+		{
+			label: "Own an object of the namespace of the owner",
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+			resource:  configMapGVR,
+			namespace: "test1",
+			expected:  []metav1.OwnerReference{owner},
+		},
+		{
+			label: "Skip an object moved to another namespace",
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: test2
+`,
+			resource:  configMapGVR,
+			namespace: "test2",
+		},
+		{
+			label: "Skip a cluster scoped object",
+			input: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`,
+			resource: clusterRoleGVR,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+			mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+				meta.RESTScopeRoot)
+			client := ownerConnector{
+				dynamicClient:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+				mapper:         mapper,
+				owner:          &owner,
+				ownerNamespace: "test1",
+			}
+
+			name, err := genericPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.input), "test1", client)
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+
+			created, err := client.dynamicClient.Resource(testCase.resource).Namespace(testCase.namespace).
+				Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the object to be created (%s)", err)
+			}
+			if !reflect.DeepEqual(created.GetOwnerReferences(), testCase.expected) {
+				t.Fatalf("Expected owner references %+v, got %+v", testCase.expected, created.GetOwnerReferences())
+			}
+		})
+	}
+}
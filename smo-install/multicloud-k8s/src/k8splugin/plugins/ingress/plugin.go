@@ -0,0 +1,223 @@
+/*
+Copyright 2018 Intel Corporation.
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	extensionsV1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingV1 "k8s.io/api/networking/v1"
+	networkingV1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	typedNetworkingV1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if ingressPlugin implements the correct interface
+var _ plugin.Reference = ingressPlugin{}
+
+// readyPollInterval is how often an Ingress is read while waiting for its
+// load balancer address
+var readyPollInterval = 2 * time.Second
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable ingressPlugin
+
+type ingressPlugin struct {
+}
+
+// ingresses implements the methods of ingressPlugin shared with the
+// plugins of the other kinds
+var ingresses = plugin.TypedResource{
+	GVK: networkingV1.SchemeGroupVersion.WithKind("Ingress"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return ingressClient{clientSet.NetworkingV1().Ingresses(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeIngress(yamlFilePath)
+	},
+}
+
+// WatchUntilReady waits until the Ingress controller publishes the load
+// balancer address of the Ingress
+func (g ingressPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	return ingresses.WaitUntil(ctx, timeout, readyPollInterval, res.Name, ns, clientSet, func(obj plugin.Object) (bool, error) {
+		return len(obj.(*networkingV1.Ingress).Status.LoadBalancer.Ingress) > 0, nil
+	})
+}
+
+// Create an ingress object in a specific Kubernetes cluster
+func (p ingressPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return ingresses.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing ingresses hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to ingresses only
+func (p ingressPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return ingresses.List(ctx, namespace, client)
+}
+
+// Delete an existing ingress hosted in a specific Kubernetes cluster
+func (p ingressPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return ingresses.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing ingress hosted in a specific Kubernetes cluster
+func (p ingressPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return ingresses.Get(ctx, resource, namespace, client)
+}
+
+// Update an ingress object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p ingressPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return ingresses.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch an ingress object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p ingressPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return ingresses.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+// decodeIngress decodes a networking.k8s.io/v1 Ingress. The Ingresses of
+// networking.k8s.io/v1beta1 and extensions/v1beta1 are converted to
+// networking.k8s.io/v1.
+func decodeIngress(yamlFilePath string) (*networkingV1.Ingress, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode ingress object error")
+	}
+
+	switch ingress := obj.(type) {
+	case *networkingV1.Ingress:
+		return ingress, nil
+	case *networkingV1beta1.Ingress:
+		return convertIngress(ingress), nil
+	case *extensionsV1beta1.Ingress:
+		// extensions/v1beta1 has the same fields as networking.k8s.io/v1beta1
+		beta := &networkingV1beta1.Ingress{}
+		err = plugin.ConvertVersion(ingress, beta, networkingV1beta1.SchemeGroupVersion.WithKind("Ingress"))
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Convert ingress object error")
+		}
+		return convertIngress(beta), nil
+	}
+	return nil, pkgerrors.New("Decoded object contains another resource different than Ingress")
+}
+
+// convertIngress converts a networking.k8s.io/v1beta1 Ingress to v1. The
+// paths without a pathType get ImplementationSpecific, the v1beta1 default.
+func convertIngress(in *networkingV1beta1.Ingress) *networkingV1.Ingress {
+	out := &networkingV1.Ingress{
+		TypeMeta:   metaV1.TypeMeta{APIVersion: networkingV1.SchemeGroupVersion.String(), Kind: "Ingress"},
+		ObjectMeta: in.ObjectMeta,
+		Spec: networkingV1.IngressSpec{
+			IngressClassName: in.Spec.IngressClassName,
+			DefaultBackend:   convertIngressBackend(in.Spec.Backend),
+		},
+		Status: networkingV1.IngressStatus{LoadBalancer: in.Status.LoadBalancer},
+	}
+	for _, tls := range in.Spec.TLS {
+		out.Spec.TLS = append(out.Spec.TLS, networkingV1.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, rule := range in.Spec.Rules {
+		outRule := networkingV1.IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			outRule.HTTP = &networkingV1.HTTPIngressRuleValue{}
+			for _, path := range rule.HTTP.Paths {
+				pathType := networkingV1.PathTypeImplementationSpecific
+				if path.PathType != nil {
+					pathType = networkingV1.PathType(*path.PathType)
+				}
+				outRule.HTTP.Paths = append(outRule.HTTP.Paths, networkingV1.HTTPIngressPath{
+					Path:     path.Path,
+					PathType: &pathType,
+					Backend:  *convertIngressBackend(&path.Backend),
+				})
+			}
+		}
+		out.Spec.Rules = append(out.Spec.Rules, outRule)
+	}
+	return out
+}
+
+// convertIngressBackend converts the serviceName and servicePort of a
+// v1beta1 backend to the service of a v1 one
+func convertIngressBackend(in *networkingV1beta1.IngressBackend) *networkingV1.IngressBackend {
+	if in == nil {
+		return nil
+	}
+	out := &networkingV1.IngressBackend{Resource: in.Resource}
+	if in.ServiceName != "" {
+		out.Service = &networkingV1.IngressServiceBackend{Name: in.ServiceName}
+		if in.ServicePort.Type == intstr.String {
+			out.Service.Port.Name = in.ServicePort.StrVal
+		} else {
+			out.Service.Port.Number = in.ServicePort.IntVal
+		}
+	}
+	return out
+}
+
+// ingressClient adapts the Ingress client to plugin.TypedClient
+type ingressClient struct {
+	ingresses typedNetworkingV1.IngressInterface
+}
+
+func (c ingressClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.ingresses.Create(ctx, obj.(*networkingV1.Ingress), opts)
+}
+
+func (c ingressClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.ingresses.Get(ctx, name, opts)
+}
+
+func (c ingressClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.ingresses.List(ctx, opts)
+}
+
+func (c ingressClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.ingresses.Update(ctx, obj.(*networkingV1.Ingress), opts)
+}
+
+func (c ingressClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.ingresses.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c ingressClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.ingresses.Delete(ctx, name, opts)
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2018 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	coreV1 "k8s.io/api/core/v1"
+	networkingV1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const ingressManifest = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: mock-ingress
+spec:
+  rules:
+  - host: %s
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: mock-service
+            port:
+              number: 80
+`
+
+func TestCreateIngress(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create an ingress with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than Ingress",
+		},
+		{
+			label:    "Successfully create an ingress",
+			manifest: fmt.Sprintf(ingressManifest, "smo.example.com"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := ingressPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-ingress" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-ingress")
+			}
+
+			// The namespace falls back to default
+			ingress, err := client.GetStandardClient().NetworkingV1().Ingresses("default").
+				Get(context.TODO(), "mock-ingress", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected ingress to be created (%s)", err)
+			}
+			if ingress.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected ingress to be labeled with the instance ID, got %v", ingress.Labels)
+			}
+		})
+	}
+}
+
+func TestCreateIngressV1beta1(t *testing.T) {
+	for _, apiVersion := range []string{"networking.k8s.io/v1beta1", "extensions/v1beta1"} {
+		t.Run(apiVersion, func(t *testing.T) {
+			manifest := `apiVersion: ` + apiVersion + `
+kind: Ingress
+metadata:
+  name: mock-ingress
+spec:
+  backend:
+    serviceName: default-service
+    servicePort: http
+  tls:
+  - hosts:
+    - smo.example.com
+    secretName: smo-tls
+  rules:
+  - host: smo.example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: mock-service
+          servicePort: 80
+`
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			_, err := ingressPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, manifest), "", client)
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+
+			ingress, err := client.GetStandardClient().NetworkingV1().Ingresses("default").
+				Get(context.TODO(), "mock-ingress", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected ingress to be created (%s)", err)
+			}
+			defaultBackend := ingress.Spec.DefaultBackend
+			if defaultBackend == nil || defaultBackend.Service == nil ||
+				defaultBackend.Service.Name != "default-service" || defaultBackend.Service.Port.Name != "http" {
+				t.Fatalf("Expected default backend default-service:http, got %+v", defaultBackend)
+			}
+			if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "smo-tls" {
+				t.Fatalf("Expected TLS secret smo-tls, got %+v", ingress.Spec.TLS)
+			}
+			path := ingress.Spec.Rules[0].HTTP.Paths[0]
+			if path.Backend.Service == nil || path.Backend.Service.Name != "mock-service" || path.Backend.Service.Port.Number != 80 {
+				t.Fatalf("Expected backend mock-service:80, got %+v", path.Backend)
+			}
+			if path.PathType == nil || *path.PathType != networkingV1.PathTypeImplementationSpecific {
+				t.Fatalf("Expected pathType ImplementationSpecific, got %v", path.PathType)
+			}
+		})
+	}
+}
+
+func TestListGetDeleteIngress(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&networkingV1.Ingress{ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&networkingV1.Ingress{ObjectMeta: metaV1.ObjectMeta{Name: "api", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	gvk := networkingV1.SchemeGroupVersion.WithKind("Ingress")
+
+	plugintest.CheckList(t, ingressPlugin{}, client, gvk, "api", "web")
+	plugintest.CheckGetDelete(t, ingressPlugin{}, client, helm.KubernetesResource{GVK: gvk, Name: "web"})
+}
+
+func TestUpdateIngress(t *testing.T) {
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+
+	// The ingress is created when it doesn't exist
+	_, err := ingressPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(ingressManifest, "smo.example.com")), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	_, err = ingressPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(ingressManifest, "oran.example.com")), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	ingress, err := client.GetStandardClient().NetworkingV1().Ingresses("test1").
+		Get(context.TODO(), "mock-ingress", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get ingress (%s)", err)
+	}
+	if host := ingress.Spec.Rules[0].Host; host != "oran.example.com" {
+		t.Fatalf("Expected host oran.example.com, got %s", host)
+	}
+}
+
+func TestWatchIngressUntilReady(t *testing.T) {
+	oldInterval := readyPollInterval
+	readyPollInterval = 10 * time.Millisecond
+	defer func() {
+		readyPollInterval = oldInterval
+	}()
+
+	testCases := []struct {
+		label         string
+		status        networkingV1.IngressStatus
+		expectedError string
+	}{
+		{
+			label: "The load balancer address is published",
+			status: networkingV1.IngressStatus{
+				LoadBalancer: coreV1.LoadBalancerStatus{
+					Ingress: []coreV1.LoadBalancerIngress{{IP: "192.0.2.10"}},
+				},
+			},
+		},
+		{
+			label:         "Time out without load balancer address",
+			expectedError: "timed out",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(&networkingV1.Ingress{
+				ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "default"},
+				Status:     testCase.status,
+			})
+
+			err := ingressPlugin{}.WatchUntilReady(context.TODO(), 50*time.Millisecond, "",
+				helm.KubernetesResource{Name: "web"}, nil, nil, nil, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2018 Intel Corporation.
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedBatchV1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if jobPlugin implements the correct interface
+var _ plugin.Reference = jobPlugin{}
+
+// readyPollInterval is how often a Job is read while waiting for it to
+// complete
+var readyPollInterval = 2 * time.Second
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable jobPlugin
+
+type jobPlugin struct {
+}
+
+// jobs implements the methods of jobPlugin shared with the
+// plugins of the other kinds
+var jobs = plugin.TypedResource{
+	GVK: batchV1.SchemeGroupVersion.WithKind("Job"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return jobClient{clientSet.BatchV1().Jobs(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeJob(yamlFilePath)
+	},
+	SetInstanceLabel: func(obj plugin.Object, instanceID string) {
+		setInstanceLabel(obj.(*batchV1.Job), instanceID)
+	},
+	KeepExisting: func(obj, existing plugin.Object) {
+		keepGeneratedSelector(obj.(*batchV1.Job), existing.(*batchV1.Job))
+	},
+}
+
+// WatchUntilReady waits until the Job has completed. It fails as soon as
+// the Job reaches the Failed condition.
+func (g jobPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	return jobs.WaitUntil(ctx, timeout, readyPollInterval, res.Name, ns, clientSet, func(obj plugin.Object) (bool, error) {
+		return jobCompleted(obj.(*batchV1.Job))
+	})
+}
+
+// Create a job object in a specific Kubernetes cluster
+func (p jobPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return jobs.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing jobs hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to jobs only
+func (p jobPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return jobs.List(ctx, namespace, client)
+}
+
+// Delete an existing job hosted in a specific Kubernetes cluster
+func (p jobPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return jobs.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing job hosted in a specific Kubernetes cluster
+func (p jobPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return jobs.Get(ctx, resource, namespace, client)
+}
+
+// Update a job object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p jobPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return jobs.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a job object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p jobPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return jobs.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodeJob(yamlFilePath string) (*batchV1.Job, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode job object error")
+	}
+
+	job, ok := obj.(*batchV1.Job)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than Job")
+	}
+	return job, nil
+}
+
+// setInstanceLabel adds the instance label to the job and to the
+// pods it creates
+func setInstanceLabel(job *batchV1.Job, instanceID string) {
+	plugin.SetInstanceLabel(job, instanceID)
+	plugin.SetInstanceLabel(&job.Spec.Template, instanceID)
+}
+
+// jobCompleted reports whether the Job succeeded, and returns an error if
+// it failed
+func jobCompleted(job *batchV1.Job) (bool, error) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != coreV1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchV1.JobFailed:
+			return false, pkgerrors.Errorf("Job failed: %s: %s", c.Reason, c.Message)
+		case batchV1.JobComplete:
+			return true, nil
+		}
+	}
+
+	// spec.completions defaults to 1
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	return job.Status.Succeeded >= completions, nil
+}
+
+// keepGeneratedSelector copies the selector and pod template labels that
+// the API server generated for the existing Job, they are immutable and
+// would otherwise make the update invalid
+func keepGeneratedSelector(job, existing *batchV1.Job) {
+	if job.Spec.Selector != nil || existing.Spec.Selector == nil {
+		return
+	}
+	job.Spec.Selector = existing.Spec.Selector
+	if job.Spec.ManualSelector == nil {
+		job.Spec.ManualSelector = existing.Spec.ManualSelector
+	}
+
+	podLabels := job.Spec.Template.GetLabels()
+	if podLabels == nil {
+		podLabels = map[string]string{}
+	}
+	for key, value := range existing.Spec.Selector.MatchLabels {
+		if _, ok := podLabels[key]; !ok {
+			podLabels[key] = value
+		}
+	}
+	for _, key := range []string{"controller-uid", "job-name"} {
+		if value, ok := existing.Spec.Template.Labels[key]; ok {
+			if _, set := podLabels[key]; !set {
+				podLabels[key] = value
+			}
+		}
+	}
+	job.Spec.Template.SetLabels(podLabels)
+}
+
+// jobClient adapts the Job client to plugin.TypedClient
+type jobClient struct {
+	jobs typedBatchV1.JobInterface
+}
+
+func (c jobClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.jobs.Create(ctx, obj.(*batchV1.Job), opts)
+}
+
+func (c jobClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.jobs.Get(ctx, name, opts)
+}
+
+func (c jobClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.jobs.List(ctx, opts)
+}
+
+func (c jobClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.jobs.Update(ctx, obj.(*batchV1.Job), opts)
+}
+
+func (c jobClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.jobs.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c jobClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.jobs.Delete(ctx, name, opts)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const jobManifest = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: mock-job
+spec:
+  backoffLimit: %d
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: migrate
+        image: busybox
+`
+
+func TestCreateJob(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create a job with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than Job",
+		},
+		{
+			label:    "Successfully create a job",
+			manifest: fmt.Sprintf(jobManifest, 3),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := jobPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-job" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-job")
+			}
+
+			job, err := client.GetStandardClient().BatchV1().Jobs("test1").
+				Get(context.TODO(), "mock-job", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected job to be created (%s)", err)
+			}
+			if job.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected job to be labeled with the instance ID, got %v", job.Labels)
+			}
+			if job.Spec.Template.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected pods to be labeled with the instance ID, got %v", job.Spec.Template.Labels)
+			}
+		})
+	}
+}
+
+func TestListGetDeleteJob(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&batchV1.Job{ObjectMeta: metaV1.ObjectMeta{Name: "migrate", Namespace: "default"}},
+		&batchV1.Job{ObjectMeta: metaV1.ObjectMeta{Name: "backup", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	gvk := batchV1.SchemeGroupVersion.WithKind("Job")
+
+	plugintest.CheckList(t, jobPlugin{}, client, gvk, "backup", "migrate")
+	plugintest.CheckGetDelete(t, jobPlugin{}, client, helm.KubernetesResource{GVK: gvk, Name: "migrate"})
+}
+
+func TestUpdateJob(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	generated := map[string]string{"controller-uid": "1234", "job-name": "mock-job"}
+	clientSet := fake.NewSimpleClientset(&batchV1.Job{
+		ObjectMeta: metaV1.ObjectMeta{Name: "mock-job", Namespace: "test1"},
+		Spec: batchV1.JobSpec{
+			Selector: &metaV1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "1234"}},
+			Template: coreV1.PodTemplateSpec{ObjectMeta: metaV1.ObjectMeta{Labels: generated}},
+		},
+	})
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet, InstanceID: "inst1"}
+
+	_, err := jobPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(jobManifest, 5)), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	job, err := clientSet.BatchV1().Jobs("test1").Get(context.TODO(), "mock-job", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get job (%s)", err)
+	}
+	if job.Spec.BackoffLimit == nil || *job.Spec.BackoffLimit != 5 {
+		t.Fatalf("Expected backoffLimit 5, got %v", job.Spec.BackoffLimit)
+	}
+	if job.Spec.Selector == nil || job.Spec.Selector.MatchLabels["controller-uid"] != "1234" {
+		t.Fatalf("Expected the generated selector to be kept, got %v", job.Spec.Selector)
+	}
+	for key, value := range generated {
+		if job.Spec.Template.Labels[key] != value {
+			t.Fatalf("Expected pod label %s=%s to be kept, got %v", key, value, job.Spec.Template.Labels)
+		}
+	}
+	if job.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected job to be labeled with the instance ID, got %v", job.Labels)
+	}
+}
+
+func TestWatchJobUntilReady(t *testing.T) {
+	oldInterval := readyPollInterval
+	readyPollInterval = 10 * time.Millisecond
+	defer func() {
+		readyPollInterval = oldInterval
+	}()
+
+	completions := int32(2)
+	testCases := []struct {
+		label         string
+		spec          batchV1.JobSpec
+		final         batchV1.JobStatus
+		expectedError string
+	}{
+		{
+			label: "Job transitions to Complete",
+			final: batchV1.JobStatus{
+				Succeeded: 1,
+				Conditions: []batchV1.JobCondition{
+					{Type: batchV1.JobComplete, Status: coreV1.ConditionTrue},
+				},
+			},
+		},
+		{
+			label: "Job reaches the requested completions",
+			spec:  batchV1.JobSpec{Completions: &completions},
+			final: batchV1.JobStatus{Succeeded: 2},
+		},
+		{
+			label: "Job transitions to Failed",
+			final: batchV1.JobStatus{
+				Failed: 4,
+				Conditions: []batchV1.JobCondition{
+					{Type: batchV1.JobFailed, Status: coreV1.ConditionTrue,
+						Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+				},
+			},
+			expectedError: "BackoffLimitExceeded",
+		},
+		{
+			label:         "Time out while the job is running",
+			spec:          batchV1.JobSpec{Completions: &completions},
+			final:         batchV1.JobStatus{Active: 1, Succeeded: 1},
+			expectedError: "timed out",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(&batchV1.Job{
+				ObjectMeta: metaV1.ObjectMeta{Name: "migrate", Namespace: "test1"},
+				Spec:       testCase.spec,
+				Status:     batchV1.JobStatus{Active: 1},
+			})
+			// The job is reported as running on the first reads
+			gets := 0
+			clientSet.PrependReactor("get", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				gets++
+				if gets < 3 {
+					return false, nil, nil
+				}
+				return true, &batchV1.Job{
+					ObjectMeta: metaV1.ObjectMeta{Name: "migrate", Namespace: "test1"},
+					Spec:       testCase.spec,
+					Status:     testCase.final,
+				}, nil
+			})
+
+			err := jobPlugin{}.WatchUntilReady(context.TODO(), 200*time.Millisecond, "test1",
+				helm.KubernetesResource{Name: "migrate"}, nil, nil, nil, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				if !strings.Contains(err.Error(), "Waiting for Job migrate") {
+					t.Fatalf("Expected the error to name the job, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+			}
+			if gets < 3 {
+				t.Fatalf("Expected the job to be read until it completed, got %d reads", gets)
+			}
+		})
+	}
+}
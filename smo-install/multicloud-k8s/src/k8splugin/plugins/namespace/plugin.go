@@ -25,6 +25,7 @@ import (
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -43,6 +44,7 @@ type namespacePlugin struct {
 }
 
 func (g namespacePlugin) WatchUntilReady(
+	ctx context.Context,
 	timeout time.Duration,
 	ns string,
 	res helm.KubernetesResource,
@@ -54,18 +56,18 @@ func (g namespacePlugin) WatchUntilReady(
 }
 
 // Create a namespace object in a specific Kubernetes cluster
-func (p namespacePlugin) Create(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p namespacePlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
 	namespaceObj := &coreV1.Namespace{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name: namespace,
 		},
 	}
-	existingNs, err := client.GetStandardClient().CoreV1().Namespaces().Get(context.TODO(), namespace, metaV1.GetOptions{})
+	existingNs, err := client.GetStandardClient().CoreV1().Namespaces().Get(ctx, namespace, metaV1.GetOptions{})
 	if err == nil && len(existingNs.ManagedFields) > 0 && existingNs.ManagedFields[0].Manager == "k8plugin" {
 		log.Printf("Namespace (%s) already ensured by plugin. Skip", namespace)
 		return namespace, nil
 	}
-	_, err = client.GetStandardClient().CoreV1().Namespaces().Create(context.TODO(), namespaceObj, metaV1.CreateOptions{})
+	_, err = client.GetStandardClient().CoreV1().Namespaces().Create(ctx, namespaceObj, metaV1.CreateOptions{})
 	if err != nil {
 		return "", pkgerrors.Wrap(err, "Create Namespace error")
 	}
@@ -75,9 +77,9 @@ func (p namespacePlugin) Create(yamlFilePath string, namespace string, client pl
 }
 
 // Get an existing namespace hosted in a specific Kubernetes cluster
-func (p namespacePlugin) Get(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p namespacePlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
 	opts := metaV1.GetOptions{}
-	ns, err := client.GetStandardClient().CoreV1().Namespaces().Get(context.TODO(), resource.Name, opts)
+	ns, err := client.GetStandardClient().CoreV1().Namespaces().Get(ctx, resource.Name, opts)
 	if err != nil {
 		return "", pkgerrors.Wrap(err, "Get Namespace error")
 	}
@@ -86,28 +88,41 @@ func (p namespacePlugin) Get(resource helm.KubernetesResource, namespace string,
 }
 
 // Delete an existing namespace hosted in a specific Kubernetes cluster
-func (p namespacePlugin) Delete(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+func (p namespacePlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
 	deletePolicy := metaV1.DeletePropagationBackground
 	opts := metaV1.DeleteOptions{
 		PropagationPolicy: &deletePolicy,
 	}
 
 	log.Println("Deleting namespace: " + resource.Name)
-	if err := client.GetStandardClient().CoreV1().Namespaces().Delete(context.TODO(), resource.Name, opts); err != nil {
+	if err := client.GetStandardClient().CoreV1().Namespaces().Delete(ctx, resource.Name, opts); err != nil {
 		return pkgerrors.Wrap(err, "Delete namespace error")
 	}
 
 	return nil
 }
 
+// Patch an existing namespace hosted in a specific Kubernetes cluster
+// This plugin ignores the namespace argument
+func (p namespacePlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	ns, err := client.GetStandardClient().CoreV1().Namespaces().Patch(ctx, resource.Name,
+		patchType, patchData, metaV1.PatchOptions{})
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Patch Namespace error")
+	}
+
+	return ns.Name, nil
+}
+
 // List of existing namespaces hosted in a specific Kubernetes cluster
 // This plugin ignores both gvk and namespace arguments
-func (p namespacePlugin) List(gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+func (p namespacePlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
 	opts := metaV1.ListOptions{
 		Limit: utils.ResourcesListLimit,
 	}
 
-	list, err := client.GetStandardClient().CoreV1().Namespaces().List(context.TODO(), opts)
+	list, err := client.GetStandardClient().CoreV1().Namespaces().List(ctx, opts)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "Get Namespace list error")
 	}
@@ -131,7 +146,7 @@ func (p namespacePlugin) List(gvk schema.GroupVersionKind, namespace string, cli
 	return result, nil
 }
 
-func (p namespacePlugin) Update(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p namespacePlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
 
 	return namespace, nil
 }
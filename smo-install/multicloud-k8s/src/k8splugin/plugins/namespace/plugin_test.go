@@ -14,6 +14,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
@@ -69,7 +70,7 @@ func TestCreateNamespace(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			result, err := namespacePlugin{}.Create("", testCase.input, client)
+			result, err := namespacePlugin{}.Create(context.TODO(), "", testCase.input, client)
 			if err != nil {
 				if testCase.expectedError == "" {
 					t.Fatalf("Create method return an un-expected (%s)", err)
@@ -130,7 +131,7 @@ func TestListNamespace(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			result, err := namespacePlugin{}.List(schema.GroupVersionKind{
+			result, err := namespacePlugin{}.List(context.TODO(), schema.GroupVersionKind{
 				Group:   "",
 				Version: "v1",
 				Kind:    "Namespace",
@@ -170,7 +171,7 @@ func TestDeleteNamespace(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			err := namespacePlugin{}.Delete(helm.KubernetesResource{
+			err := namespacePlugin{}.Delete(context.TODO(), helm.KubernetesResource{
 				GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"},
 				Name: testCase.input["name"],
 			}, testCase.input["namespace"], client)
@@ -214,7 +215,7 @@ func TestGetNamespace(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			result, err := namespacePlugin{}.Get(helm.KubernetesResource{
+			result, err := namespacePlugin{}.Get(context.TODO(), helm.KubernetesResource{
 				GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"},
 				Name: testCase.input["name"],
 			}, testCase.input["namespace"], client)
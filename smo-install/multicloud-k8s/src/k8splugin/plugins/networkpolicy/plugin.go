@@ -0,0 +1,148 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	networkingV1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedNetworkingV1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if networkPolicyPlugin implements the correct interface
+var _ plugin.Reference = networkPolicyPlugin{}
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable networkPolicyPlugin
+
+// networkPolicyPlugin manages NetworkPolicies
+type networkPolicyPlugin struct {
+}
+
+// networkPolicies implements the methods of networkPolicyPlugin shared with the
+// plugins of the other kinds
+var networkPolicies = plugin.TypedResource{
+	GVK: networkingV1.SchemeGroupVersion.WithKind("NetworkPolicy"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return networkPolicyClient{clientSet.NetworkingV1().NetworkPolicies(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeNetworkPolicy(yamlFilePath)
+	},
+}
+
+// WatchUntilReady checks that the NetworkPolicy exists, NetworkPolicies have
+// no readiness
+func (g networkPolicyPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	_, err := networkPolicies.GetObject(ctx, res.Name, ns, clientSet)
+	return err
+}
+
+// Create a networkpolicy object in a specific Kubernetes cluster
+func (p networkPolicyPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return networkPolicies.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing networkpolicies hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to networkpolicies only
+func (p networkPolicyPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return networkPolicies.List(ctx, namespace, client)
+}
+
+// Delete an existing networkpolicy hosted in a specific Kubernetes cluster
+func (p networkPolicyPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return networkPolicies.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing networkpolicy hosted in a specific Kubernetes cluster
+func (p networkPolicyPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return networkPolicies.Get(ctx, resource, namespace, client)
+}
+
+// Update a networkpolicy object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p networkPolicyPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return networkPolicies.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a networkpolicy object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p networkPolicyPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return networkPolicies.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodeNetworkPolicy(yamlFilePath string) (*networkingV1.NetworkPolicy, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode networkpolicy object error")
+	}
+
+	networkPolicy, ok := obj.(*networkingV1.NetworkPolicy)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than NetworkPolicy")
+	}
+	return networkPolicy, nil
+}
+
+// networkPolicyClient adapts the NetworkPolicy client to plugin.TypedClient
+type networkPolicyClient struct {
+	networkPolicies typedNetworkingV1.NetworkPolicyInterface
+}
+
+func (c networkPolicyClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.networkPolicies.Create(ctx, obj.(*networkingV1.NetworkPolicy), opts)
+}
+
+func (c networkPolicyClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.networkPolicies.Get(ctx, name, opts)
+}
+
+func (c networkPolicyClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.networkPolicies.List(ctx, opts)
+}
+
+func (c networkPolicyClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.networkPolicies.Update(ctx, obj.(*networkingV1.NetworkPolicy), opts)
+}
+
+func (c networkPolicyClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.networkPolicies.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c networkPolicyClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.networkPolicies.Delete(ctx, name, opts)
+}
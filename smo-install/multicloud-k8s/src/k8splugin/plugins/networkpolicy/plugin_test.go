@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	pkgerrors "github.com/pkg/errors"
+	networkingV1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateListNetworkPolicy(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	clientSet := fake.NewSimpleClientset(&networkingV1.NetworkPolicy{
+		ObjectMeta: metaV1.ObjectMeta{Name: "other-tenant", Namespace: "tenant2"},
+	})
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet, InstanceID: "inst1"}
+
+	_, err := networkPolicyPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`), "tenant1", client)
+	if err == nil || !strings.Contains(err.Error(), "contains another resource different than NetworkPolicy") {
+		t.Fatalf("Expected a wrong resource type error, got %v", err)
+	}
+
+	name, err := networkPolicyPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-other-tenants
+spec:
+  podSelector:
+    matchLabels:
+      app: ric
+  policyTypes:
+  - Ingress
+  ingress:
+  - from:
+    - podSelector: {}
+`), "tenant1", client)
+	if err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+	if name != "deny-other-tenants" {
+		t.Fatalf("Create method returned %q, expected %q", name, "deny-other-tenants")
+	}
+
+	policy, err := clientSet.NetworkingV1().NetworkPolicies("tenant1").
+		Get(context.TODO(), "deny-other-tenants", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected networkpolicy to be created (%s)", err)
+	}
+	if policy.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected networkpolicy to be labeled with the instance ID, got %v", policy.Labels)
+	}
+	if !reflect.DeepEqual(policy.Spec.PodSelector.MatchLabels, map[string]string{"app": "ric"}) {
+		t.Fatalf("Expected the pod selector to be left untouched, got %v", policy.Spec.PodSelector)
+	}
+
+	gvk := networkingV1.SchemeGroupVersion.WithKind("NetworkPolicy")
+	list, err := networkPolicyPlugin{}.List(context.TODO(), gvk, "tenant1", client)
+	if err != nil {
+		t.Fatalf("List method returned an error (%s)", err)
+	}
+	expected := []helm.KubernetesResource{{GVK: gvk, Name: "deny-other-tenants"}}
+	if !reflect.DeepEqual(list, expected) {
+		t.Fatalf("List method returned %v, expected %v", list, expected)
+	}
+
+	err = networkPolicyPlugin{}.Delete(context.TODO(), expected[0], "tenant1", client)
+	if err != nil {
+		t.Fatalf("Delete method returned an error (%s)", err)
+	}
+	_, err = networkPolicyPlugin{}.Get(context.TODO(), expected[0], "tenant1", client)
+	if !k8serrors.IsNotFound(pkgerrors.Cause(err)) {
+		t.Fatalf("Expected the networkpolicy to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2018 Intel Corporation.
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if pvcPlugin implements the correct interface
+var _ plugin.Reference = pvcPlugin{}
+
+// readyPollInterval is how often a PersistentVolumeClaim is read while
+// waiting for it to be bound
+var readyPollInterval = 2 * time.Second
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable pvcPlugin
+
+type pvcPlugin struct {
+}
+
+// pvcs implements the methods of pvcPlugin shared with the
+// plugins of the other kinds
+var pvcs = plugin.TypedResource{
+	GVK: coreV1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return pvcClient{clientSet.CoreV1().PersistentVolumeClaims(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodePVC(yamlFilePath)
+	},
+	KeepExisting: func(obj, existing plugin.Object) {
+		keepBoundVolume(obj.(*coreV1.PersistentVolumeClaim), existing.(*coreV1.PersistentVolumeClaim))
+	},
+}
+
+// WatchUntilReady waits until the PersistentVolumeClaim is bound to a volume
+func (g pvcPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	return pvcs.WaitUntil(ctx, timeout, readyPollInterval, res.Name, ns, clientSet, func(obj plugin.Object) (bool, error) {
+		return obj.(*coreV1.PersistentVolumeClaim).Status.Phase == coreV1.ClaimBound, nil
+	})
+}
+
+// Create a persistentvolumeclaim object in a specific Kubernetes cluster
+func (p pvcPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return pvcs.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing persistentvolumeclaims hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to persistentvolumeclaims only
+func (p pvcPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return pvcs.List(ctx, namespace, client)
+}
+
+// Delete an existing persistentvolumeclaim hosted in a specific Kubernetes cluster
+func (p pvcPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return pvcs.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing persistentvolumeclaim hosted in a specific Kubernetes cluster
+func (p pvcPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return pvcs.Get(ctx, resource, namespace, client)
+}
+
+// Update a persistentvolumeclaim object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p pvcPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return pvcs.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a persistentvolumeclaim object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p pvcPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return pvcs.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodePVC(yamlFilePath string) (*coreV1.PersistentVolumeClaim, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode persistentvolumeclaim object error")
+	}
+
+	pvc, ok := obj.(*coreV1.PersistentVolumeClaim)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than PersistentVolumeClaim")
+	}
+	return pvc, nil
+}
+
+// keepBoundVolume copies the bound volume and the defaulted storage class
+// of the existing claim, they are immutable once set
+func keepBoundVolume(pvc, existing *coreV1.PersistentVolumeClaim) {
+	if pvc.Spec.VolumeName == "" {
+		pvc.Spec.VolumeName = existing.Spec.VolumeName
+	}
+	if pvc.Spec.StorageClassName == nil {
+		pvc.Spec.StorageClassName = existing.Spec.StorageClassName
+	}
+}
+
+// pvcClient adapts the PersistentVolumeClaim client to plugin.TypedClient
+type pvcClient struct {
+	pvcs typedCoreV1.PersistentVolumeClaimInterface
+}
+
+func (c pvcClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.pvcs.Create(ctx, obj.(*coreV1.PersistentVolumeClaim), opts)
+}
+
+func (c pvcClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.pvcs.Get(ctx, name, opts)
+}
+
+func (c pvcClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.pvcs.List(ctx, opts)
+}
+
+func (c pvcClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.pvcs.Update(ctx, obj.(*coreV1.PersistentVolumeClaim), opts)
+}
+
+func (c pvcClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.pvcs.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c pvcClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.pvcs.Delete(ctx, name, opts)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2018 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const pvcManifest = `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: mock-pvc
+spec:
+  accessModes:
+  - ReadWriteOnce
+  resources:
+    requests:
+      storage: 1Gi
+`
+
+func TestCreatePVC(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create a pvc with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than PersistentVolumeClaim",
+		},
+		{
+			label:    "Successfully create a pvc",
+			manifest: pvcManifest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := pvcPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-pvc" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-pvc")
+			}
+
+			pvc, err := client.GetStandardClient().CoreV1().PersistentVolumeClaims("test1").
+				Get(context.TODO(), "mock-pvc", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected pvc to be created (%s)", err)
+			}
+			if pvc.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected pvc to be labeled with the instance ID, got %v", pvc.Labels)
+			}
+		})
+	}
+}
+
+func TestUpdateBoundPVC(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(&coreV1.PersistentVolumeClaim{
+		ObjectMeta: metaV1.ObjectMeta{Name: "mock-pvc", Namespace: "test1"},
+		Spec:       coreV1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		Status:     coreV1.PersistentVolumeClaimStatus{Phase: coreV1.ClaimBound},
+	})
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet, InstanceID: "inst1"}
+
+	_, err := pvcPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, pvcManifest), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	pvc, err := clientSet.CoreV1().PersistentVolumeClaims("test1").Get(context.TODO(), "mock-pvc", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get pvc (%s)", err)
+	}
+	if pvc.Spec.VolumeName != "pv-1" {
+		t.Fatalf("Expected the bound volume to be kept, got %q", pvc.Spec.VolumeName)
+	}
+}
+
+func TestDeletePVC(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.PersistentVolumeClaim{ObjectMeta: metaV1.ObjectMeta{Name: "data", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	res := helm.KubernetesResource{GVK: coreV1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), Name: "data"}
+
+	plugintest.CheckGetDelete(t, pvcPlugin{}, client, res)
+}
+
+func TestWatchPVCUntilBound(t *testing.T) {
+	oldInterval := readyPollInterval
+	readyPollInterval = 10 * time.Millisecond
+	defer func() {
+		readyPollInterval = oldInterval
+	}()
+
+	pvc := &coreV1.PersistentVolumeClaim{
+		ObjectMeta: metaV1.ObjectMeta{Name: "data", Namespace: "test1"},
+		Status:     coreV1.PersistentVolumeClaimStatus{Phase: coreV1.ClaimPending},
+	}
+
+	// A pending claim times out
+	clientSet := fake.NewSimpleClientset(pvc.DeepCopy())
+	err := pvcPlugin{}.WatchUntilReady(context.TODO(), 50*time.Millisecond, "test1",
+		helm.KubernetesResource{Name: "data"}, nil, nil, nil, clientSet)
+	if err == nil || !strings.Contains(err.Error(), "Waiting for PersistentVolumeClaim data") {
+		t.Fatalf("Expected a wrapped timeout error, got %v", err)
+	}
+
+	// The claim gets bound while waiting
+	clientSet = fake.NewSimpleClientset(pvc.DeepCopy())
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		bound := pvc.DeepCopy()
+		bound.Status.Phase = coreV1.ClaimBound
+		clientSet.CoreV1().PersistentVolumeClaims("test1").UpdateStatus(context.TODO(), bound, metaV1.UpdateOptions{})
+	}()
+	err = pvcPlugin{}.WatchUntilReady(context.TODO(), 5*time.Second, "test1",
+		helm.KubernetesResource{Name: "data"}, nil, nil, nil, clientSet)
+	if err != nil {
+		t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+	}
+}
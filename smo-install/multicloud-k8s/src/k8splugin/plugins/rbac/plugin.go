@@ -0,0 +1,250 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	rbacV1 "k8s.io/api/rbac/v1"
+	rbacV1alpha1 "k8s.io/api/rbac/v1alpha1"
+	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedRbacV1 "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if rbacPlugin implements the correct interface
+var _ plugin.Reference = rbacPlugin{}
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable rbacPlugin
+
+// rbacPlugin manages the namespaced RBAC resources, Roles and RoleBindings.
+// The kind is taken from the decoded manifest or from the resource GVK.
+// Manifests of rbac.authorization.k8s.io/v1beta1 and v1alpha1 are converted
+// to v1.
+type rbacPlugin struct {
+}
+
+// rbacResources implements the methods of rbacPlugin shared with the
+// plugins of the other kinds, by kind
+var rbacResources = map[string]plugin.TypedResource{
+	"Role": {
+		GVK: rbacV1.SchemeGroupVersion.WithKind("Role"),
+		Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+			return roleClient{clientSet.RbacV1().Roles(namespace)}
+		},
+		Decode: decodeRBAC,
+	},
+	"RoleBinding": {
+		GVK: rbacV1.SchemeGroupVersion.WithKind("RoleBinding"),
+		Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+			return roleBindingClient{clientSet.RbacV1().RoleBindings(namespace)}
+		},
+		Decode: decodeRBAC,
+	},
+}
+
+// WatchUntilReady checks that the Role or RoleBinding exists, they have no
+// readiness
+func (g rbacPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	resources, err := resourcesOf(res.GVK.Kind)
+	if err != nil {
+		return err
+	}
+	_, err = resources.GetObject(ctx, res.Name, ns, clientSet)
+	return err
+}
+
+// Create a role or rolebinding object in a specific Kubernetes cluster
+func (p rbacPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	obj, err := decodeRBAC(yamlFilePath)
+	if err != nil {
+		return "", err
+	}
+	resources, err := resourcesOf(obj.GetObjectKind().GroupVersionKind().Kind)
+	if err != nil {
+		return "", err
+	}
+	return resources.CreateObject(ctx, obj, namespace, client)
+}
+
+// List of existing roles or rolebindings, depending on the gvk kind, hosted
+// in a specific Kubernetes cluster
+func (p rbacPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	resources, err := resourcesOf(gvk.Kind)
+	if err != nil {
+		return nil, err
+	}
+	return resources.List(ctx, namespace, client)
+}
+
+// Delete an existing role or rolebinding hosted in a specific Kubernetes cluster
+func (p rbacPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	resources, err := resourcesOf(resource.GVK.Kind)
+	if err != nil {
+		return err
+	}
+	return resources.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing role or rolebinding hosted in a specific Kubernetes cluster
+func (p rbacPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	resources, err := resourcesOf(resource.GVK.Kind)
+	if err != nil {
+		return "", err
+	}
+	return resources.Get(ctx, resource, namespace, client)
+}
+
+// Update a role or rolebinding object in a specific Kubernetes cluster, it
+// is created if it doesn't exist yet. The roleRef of a RoleBinding cannot
+// change, the API server rejects a different one.
+func (p rbacPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	obj, err := decodeRBAC(yamlFilePath)
+	if err != nil {
+		return "", err
+	}
+	resources, err := resourcesOf(obj.GetObjectKind().GroupVersionKind().Kind)
+	if err != nil {
+		return "", err
+	}
+	return resources.UpdateObject(ctx, obj, namespace, client)
+}
+
+// Patch a role or rolebinding object in a specific Kubernetes cluster. The
+// instance label is set again if the patch removed or changed it.
+func (p rbacPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	resources, err := resourcesOf(resource.GVK.Kind)
+	if err != nil {
+		return "", err
+	}
+	return resources.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+// resourcesOf returns the TypedResource of the Roles or of the RoleBindings
+func resourcesOf(kind string) (plugin.TypedResource, error) {
+	resources, ok := rbacResources[kind]
+	if !ok {
+		return plugin.TypedResource{}, pkgerrors.Errorf("Kind %q is not supported by the rbac plugin", kind)
+	}
+	return resources, nil
+}
+
+// decodeRBAC decodes a Role or a RoleBinding. The ones of
+// rbac.authorization.k8s.io/v1beta1 and v1alpha1 are converted to v1, the
+// kinds have the same fields in all these versions. The kind of the
+// returned object is set.
+func decodeRBAC(yamlFilePath string) (plugin.Object, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode rbac object error")
+	}
+
+	var out plugin.Object
+	var kind string
+	switch obj.(type) {
+	case *rbacV1.Role, *rbacV1beta1.Role, *rbacV1alpha1.Role:
+		out, kind = &rbacV1.Role{}, "Role"
+	case *rbacV1.RoleBinding, *rbacV1beta1.RoleBinding, *rbacV1alpha1.RoleBinding:
+		out, kind = &rbacV1.RoleBinding{}, "RoleBinding"
+	default:
+		return nil, pkgerrors.New("Decoded object contains another resource different than Role or RoleBinding")
+	}
+
+	if err := plugin.ConvertVersion(obj, out, rbacV1.SchemeGroupVersion.WithKind(kind)); err != nil {
+		return nil, pkgerrors.Wrap(err, "Convert rbac object error")
+	}
+	return out, nil
+}
+
+// roleClient adapts the Role client to plugin.TypedClient
+type roleClient struct {
+	roles typedRbacV1.RoleInterface
+}
+
+func (c roleClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.roles.Create(ctx, obj.(*rbacV1.Role), opts)
+}
+
+func (c roleClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.roles.Get(ctx, name, opts)
+}
+
+func (c roleClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.roles.List(ctx, opts)
+}
+
+func (c roleClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.roles.Update(ctx, obj.(*rbacV1.Role), opts)
+}
+
+func (c roleClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.roles.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c roleClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.roles.Delete(ctx, name, opts)
+}
+
+// roleBindingClient adapts the RoleBinding client to plugin.TypedClient
+type roleBindingClient struct {
+	roleBindings typedRbacV1.RoleBindingInterface
+}
+
+func (c roleBindingClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.roleBindings.Create(ctx, obj.(*rbacV1.RoleBinding), opts)
+}
+
+func (c roleBindingClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.roleBindings.Get(ctx, name, opts)
+}
+
+func (c roleBindingClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.roleBindings.List(ctx, opts)
+}
+
+func (c roleBindingClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.roleBindings.Update(ctx, obj.(*rbacV1.RoleBinding), opts)
+}
+
+func (c roleBindingClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.roleBindings.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c roleBindingClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.roleBindings.Delete(ctx, name, opts)
+}
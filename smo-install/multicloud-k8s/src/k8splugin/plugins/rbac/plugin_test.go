@@ -0,0 +1,200 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	rbacV1 "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const roleManifest = `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: mock-role
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get", "list"]
+`
+
+const roleBindingManifest = `apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: mock-rolebinding
+subjects:
+- kind: ServiceAccount
+  name: operator
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: mock-role
+`
+
+func TestCreateRBAC(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label            string
+		manifest         string
+		expectedName     string
+		expectedResource string
+		expectedError    string
+	}{
+		{
+			label: "Fail to create an object with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than Role or RoleBinding",
+		},
+		{
+			label:            "Successfully create a role",
+			manifest:         roleManifest,
+			expectedName:     "mock-role",
+			expectedResource: "roles",
+		},
+		{
+			label:            "Successfully create a rolebinding",
+			manifest:         roleBindingManifest,
+			expectedName:     "mock-rolebinding",
+			expectedResource: "rolebindings",
+		},
+		{
+			label:            "Successfully create a v1beta1 role",
+			manifest:         strings.Replace(roleManifest, "rbac.authorization.k8s.io/v1", "rbac.authorization.k8s.io/v1beta1", 1),
+			expectedName:     "mock-role",
+			expectedResource: "roles",
+		},
+		{
+			label:            "Successfully create a v1alpha1 rolebinding",
+			manifest:         strings.Replace(roleBindingManifest, "rbac.authorization.k8s.io/v1", "rbac.authorization.k8s.io/v1alpha1", 1),
+			expectedName:     "mock-rolebinding",
+			expectedResource: "rolebindings",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset()
+			client := plugintest.FakeKubernetesConnector{ClientSet: clientSet, InstanceID: "inst1"}
+			result, err := rbacPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				if len(clientSet.Actions()) != 0 {
+					t.Fatalf("Expected no client call, got %v", clientSet.Actions())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != testCase.expectedName {
+				t.Fatalf("Create method returned %q, expected %q", result, testCase.expectedName)
+			}
+
+			actions := clientSet.Actions()
+			if len(actions) != 1 || !actions[0].Matches("create", testCase.expectedResource) ||
+				actions[0].GetResource().Group != rbacV1.GroupName {
+				t.Fatalf("Expected a single create of %s, got %v", testCase.expectedResource, actions)
+			}
+
+			var labels map[string]string
+			switch testCase.expectedResource {
+			case "roles":
+				role, err := clientSet.RbacV1().Roles("test1").Get(context.TODO(), result, metaV1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Expected role to be created (%s)", err)
+				}
+				labels = role.Labels
+			case "rolebindings":
+				binding, err := clientSet.RbacV1().RoleBindings("test1").Get(context.TODO(), result, metaV1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Expected rolebinding to be created (%s)", err)
+				}
+				if binding.RoleRef.Name != "mock-role" {
+					t.Fatalf("Expected the binding to reference mock-role, got %v", binding.RoleRef)
+				}
+				labels = binding.Labels
+			}
+			if labels[labelName] != "inst1" {
+				t.Fatalf("Expected %s to be labeled with the instance ID, got %v", result, labels)
+			}
+		})
+	}
+}
+
+func TestListGetDeleteRBAC(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&rbacV1.Role{ObjectMeta: metaV1.ObjectMeta{Name: "reader", Namespace: "default"}},
+		&rbacV1.RoleBinding{ObjectMeta: metaV1.ObjectMeta{Name: "reader", Namespace: "default"}},
+		&rbacV1.RoleBinding{ObjectMeta: metaV1.ObjectMeta{Name: "writer", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	roleGVK := rbacV1.SchemeGroupVersion.WithKind("Role")
+	bindingGVK := rbacV1.SchemeGroupVersion.WithKind("RoleBinding")
+
+	plugintest.CheckList(t, rbacPlugin{}, client, bindingGVK, "reader", "writer")
+	// Deleting the role leaves the binding with the same name alone
+	plugintest.CheckGetDelete(t, rbacPlugin{}, client, helm.KubernetesResource{GVK: roleGVK, Name: "reader"})
+
+	err := rbacPlugin{}.WatchUntilReady(context.TODO(), 0, "", helm.KubernetesResource{GVK: bindingGVK, Name: "reader"},
+		nil, nil, nil, clientSet)
+	if err != nil {
+		t.Fatalf("Expected the rolebinding to be kept (%s)", err)
+	}
+
+	_, err = rbacPlugin{}.List(context.TODO(), rbacV1.SchemeGroupVersion.WithKind("ClusterRole"), "", client)
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("Expected an unsupported kind error, got %v", err)
+	}
+}
+
+func TestUpdateRole(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+
+	// The role is created when it doesn't exist
+	_, err := rbacPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, roleManifest), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	_, err = rbacPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, strings.Replace(roleManifest, `"list"`, `"list", "watch"`, 1)), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	role, err := client.GetStandardClient().RbacV1().Roles("test1").Get(context.TODO(), "mock-role", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get role (%s)", err)
+	}
+	if !reflect.DeepEqual(role.Rules[0].Verbs, []string{"get", "list", "watch"}) {
+		t.Fatalf("Expected the updated rules to be stored, got %v", role.Rules)
+	}
+	if role.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected role to be labeled with the instance ID, got %v", role.Labels)
+	}
+}
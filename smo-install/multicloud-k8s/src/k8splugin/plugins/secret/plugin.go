@@ -0,0 +1,150 @@
+/*
+Copyright 2018 Intel Corporation.
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if secretPlugin implements the correct interface
+var _ plugin.Reference = secretPlugin{}
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable secretPlugin
+
+// secretPlugin manages Secrets. The data of the secrets is never logged nor
+// returned, only their names.
+type secretPlugin struct {
+}
+
+// secrets implements the methods of secretPlugin shared with the
+// plugins of the other kinds
+var secrets = plugin.TypedResource{
+	GVK: coreV1.SchemeGroupVersion.WithKind("Secret"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return secretClient{clientSet.CoreV1().Secrets(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeSecret(yamlFilePath)
+	},
+}
+
+// WatchUntilReady checks that the Secret exists, Secrets have no readiness
+func (g secretPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	_, err := secrets.GetObject(ctx, res.Name, ns, clientSet)
+	return err
+}
+
+// Create a secret object in a specific Kubernetes cluster
+func (p secretPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return secrets.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing secrets hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to secrets only
+func (p secretPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return secrets.List(ctx, namespace, client)
+}
+
+// Delete an existing secret hosted in a specific Kubernetes cluster
+func (p secretPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return secrets.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing secret hosted in a specific Kubernetes cluster
+func (p secretPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return secrets.Get(ctx, resource, namespace, client)
+}
+
+// Update a secret object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p secretPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return secrets.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a secret object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p secretPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return secrets.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodeSecret(yamlFilePath string) (*coreV1.Secret, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		// Decoding errors can quote the manifest, and so the secret data
+		return nil, pkgerrors.New("Decode secret object error")
+	}
+
+	secret, ok := obj.(*coreV1.Secret)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than Secret")
+	}
+	return secret, nil
+}
+
+// secretClient adapts the Secret client to plugin.TypedClient
+type secretClient struct {
+	secrets typedCoreV1.SecretInterface
+}
+
+func (c secretClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.secrets.Create(ctx, obj.(*coreV1.Secret), opts)
+}
+
+func (c secretClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.secrets.Get(ctx, name, opts)
+}
+
+func (c secretClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.secrets.List(ctx, opts)
+}
+
+func (c secretClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.secrets.Update(ctx, obj.(*coreV1.Secret), opts)
+}
+
+func (c secretClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.secrets.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c secretClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.secrets.Delete(ctx, name, opts)
+}
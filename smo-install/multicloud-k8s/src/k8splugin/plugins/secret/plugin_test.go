@@ -0,0 +1,122 @@
+/*
+Copyright 2018 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const secretManifest = `apiVersion: v1
+kind: Secret
+metadata:
+  name: mock-secret
+type: Opaque
+data:
+  password: %s
+`
+
+func TestCreateSecret(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+	manifest := strings.Replace(secretManifest, "%s", base64.StdEncoding.EncodeToString([]byte("s3cr3t")), 1)
+
+	result, err := secretPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, manifest), "test1", client)
+	if err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+	if result != "mock-secret" {
+		t.Fatalf("Create method returned %q, expected %q", result, "mock-secret")
+	}
+
+	secret, err := client.GetStandardClient().CoreV1().Secrets("test1").
+		Get(context.TODO(), "mock-secret", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected secret to be created (%s)", err)
+	}
+	if secret.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected secret to be labeled with the instance ID, got %v", secret.Labels)
+	}
+	if string(secret.Data["password"]) != "s3cr3t" {
+		t.Fatalf("Expected secret data to be stored, got %v", secret.Data)
+	}
+}
+
+func TestSecretDataNotLogged(t *testing.T) {
+	password := "n0t-f0r-l0gs"
+	encoded := base64.StdEncoding.EncodeToString([]byte(password))
+
+	// The plugin logs through logrus, the helpers of internal/plugin
+	// through the standard logger
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	oldOutput := logrus.StandardLogger().Out
+	logrus.SetOutput(&logs)
+	defer logrus.SetOutput(oldOutput)
+
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+	res := helm.KubernetesResource{GVK: coreV1.SchemeGroupVersion.WithKind("Secret"), Name: "mock-secret"}
+	manifest := plugintest.WriteManifest(t, strings.Replace(secretManifest, "%s", encoded, 1))
+	// A manifest the deserializer rejects, its error would quote the data
+	invalid := plugintest.WriteManifest(t, strings.Replace(secretManifest, "kind: Secret", "", 1))
+
+	var outputs []string
+	collect := func(result string, err error) {
+		outputs = append(outputs, result)
+		if err != nil {
+			outputs = append(outputs, err.Error())
+		}
+	}
+	collect(secretPlugin{}.Create(context.TODO(), manifest, "test1", client))
+	collect(secretPlugin{}.Update(context.TODO(), manifest, "test1", client))
+	collect(secretPlugin{}.Get(context.TODO(), res, "test1", client))
+	collect(secretPlugin{}.Create(context.TODO(), invalid, "test1", client))
+	collect(secretPlugin{}.Update(context.TODO(), invalid, "test1", client))
+	collect("", secretPlugin{}.Delete(context.TODO(), res, "test1", client))
+	outputs = append(outputs, logs.String())
+
+	for _, output := range outputs {
+		if strings.Contains(output, encoded) || strings.Contains(output, password) {
+			t.Fatalf("Secret data was emitted: %q", output)
+		}
+	}
+	if !strings.Contains(logs.String(), `"msg":"Deleting Secret"`) || !strings.Contains(logs.String(), `"name":"mock-secret"`) {
+		t.Fatalf("Expected the deletion to be logged, got %q", logs.String())
+	}
+}
+
+func TestDeleteSecret(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.Secret{ObjectMeta: metaV1.ObjectMeta{Name: "tls", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	res := helm.KubernetesResource{GVK: coreV1.SchemeGroupVersion.WithKind("Secret"), Name: "tls"}
+
+	plugintest.CheckGetDelete(t, secretPlugin{}, client, res)
+}
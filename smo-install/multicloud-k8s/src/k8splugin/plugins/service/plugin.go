@@ -16,26 +16,56 @@ package main
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	pkgerrors "github.com/pkg/errors"
 	coreV1 "k8s.io/api/core/v1"
+	discoveryV1beta1 "k8s.io/api/discovery/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/metrics"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
 )
 
 // Compile time check to see if servicePlugin implements the correct interface
 var _ plugin.Reference = servicePlugin{}
+var _ plugin.ProgressWatcher = servicePlugin{}
+var _ plugin.BytesCreator = servicePlugin{}
+var _ plugin.DeletionWatcher = servicePlugin{}
+var _ plugin.RawGetter = servicePlugin{}
+
+// endpointSliceControllerName is the managed-by label value of the
+// EndpointSlices maintained by kube-controller-manager
+const endpointSliceControllerName = "endpointslice-controller.k8s.io"
+
+// loadBalancerPollInterval is how often a Service, its events and endpoints
+// are read while waiting for the load balancer to be provisioned and for the
+// endpoints to be ready
+var loadBalancerPollInterval = 2 * time.Second
+
+// serviceGVK is the kind of the objects handled by the plugin
+var serviceGVK = coreV1.SchemeGroupVersion.WithKind("Service")
 
 // ExportedVariable is what we will look for when calling the plugin
 var ExportedVariable servicePlugin
@@ -44,6 +74,7 @@ type servicePlugin struct {
 }
 
 func (g servicePlugin) WatchUntilReady(
+	ctx context.Context,
 	timeout time.Duration,
 	ns string,
 	res helm.KubernetesResource,
@@ -51,148 +82,1191 @@ func (g servicePlugin) WatchUntilReady(
 	restClient rest.Interface,
 	objType runtime.Object,
 	clientSet kubernetes.Interface) error {
-	return pkgerrors.Errorf("This function is not implemented in this plugin")
+	return g.WatchUntilReadyWithProgress(ctx, timeout, ns, res, clientSet, nil)
 }
 
-// Create a service object in a specific Kubernetes cluster
-func (p servicePlugin) Create(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
-	if namespace == "" {
-		namespace = "default"
+// WatchUntilReadyWithProgress waits for a Service to have the configured
+// number of ready endpoint addresses and, for a LoadBalancer Service, to get
+// an ingress address. The events of the Service, such as
+// EnsuringLoadBalancer, are passed to progress as they appear. Headless and
+// ExternalName Services, as well as Services without a selector whose
+// endpoints are not managed by Kubernetes, are ready as soon as they exist.
+func (g servicePlugin) WatchUntilReadyWithProgress(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	clientSet kubernetes.Interface,
+	progress plugin.ProgressFunc) error {
+	if ns == "" {
+		ns = "default"
+	}
+
+	reported := map[string]int32{}
+	condition := func() (bool, error) {
+		service, err := clientSet.CoreV1().Services(ns).Get(ctx, res.Name, metaV1.GetOptions{})
+		if err != nil {
+			return false, pkgerrors.Wrap(err, "Get Service error")
+		}
+
+		if progress != nil {
+			events, err := clientSet.CoreV1().Events(ns).List(ctx, metaV1.ListOptions{
+				FieldSelector: "involvedObject.kind=Service,involvedObject.name=" + res.Name,
+			})
+			if err != nil {
+				fields := logFields("watch", ns, res.Name, nil)
+				fields["error"] = err.Error()
+				logutils.Warn("Unable to list events of service", fields)
+			} else {
+				for _, event := range events.Items {
+					if event.InvolvedObject.Kind != "Service" || event.InvolvedObject.Name != res.Name ||
+						event.InvolvedObject.UID != service.UID {
+						continue
+					}
+					// Repeated events are aggregated by increasing their count
+					if count, ok := reported[event.Name]; ok && count >= event.Count {
+						continue
+					}
+					reported[event.Name] = event.Count
+					progress(event.Reason, event.Message)
+				}
+			}
+		}
+
+		if service.Spec.Type == coreV1.ServiceTypeLoadBalancer && len(service.Status.LoadBalancer.Ingress) == 0 {
+			return false, nil
+		}
+		return endpointsReady(ctx, service, ns, clientSet)
 	}
 
-	obj, err := utils.DecodeYAML(yamlFilePath, nil)
+	var err error
+	if timeout <= 0 {
+		err = wait.PollImmediateInfinite(loadBalancerPollInterval, condition)
+	} else {
+		err = wait.PollImmediate(loadBalancerPollInterval, timeout, condition)
+	}
 	if err != nil {
-		return "", pkgerrors.Wrap(err, "Decode service object error")
+		return pkgerrors.Wrapf(err, "Waiting for Service %s", res.Name)
 	}
+	return nil
+}
 
-	service, ok := obj.(*coreV1.Service)
-	if !ok {
-		return "", pkgerrors.New("Decoded object contains another resource different than Service")
+// endpointsReady reports whether the Service has at least
+// ServiceReadyEndpoints ready endpoints. They are counted across its
+// EndpointSlices, the Endpoints object is only read when the cluster serves
+// no slice for the Service as it is truncated for large Services.
+func endpointsReady(ctx context.Context, service *coreV1.Service, namespace string, clientSet kubernetes.Interface) (bool, error) {
+	minimum := config.GetConfiguration().ServiceReadyEndpoints
+	if minimum <= 0 || service.Spec.Type == coreV1.ServiceTypeExternalName ||
+		service.Spec.ClusterIP == coreV1.ClusterIPNone || len(service.Spec.Selector) == 0 {
+		return true, nil
+	}
+
+	ready, found, err := readyEndpointSliceEndpoints(ctx, service.Name, namespace, clientSet)
+	if err != nil {
+		return false, err
+	}
+	if found {
+		return ready >= minimum, nil
+	}
+
+	endpoints, err := clientSet.CoreV1().Endpoints(namespace).Get(ctx, service.Name, metaV1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Get Endpoints error")
+	}
+
+	ready = 0
+	for _, subset := range endpoints.Subsets {
+		ready += len(subset.Addresses)
+	}
+	return ready >= minimum, nil
+}
+
+// readyEndpointSliceEndpoints counts the ready endpoints of the
+// EndpointSlices of a Service. A pod listed in several slices, e.g. an
+// IPv4 and an IPv6 one, is counted once. found is false when the
+// cluster doesn't serve EndpointSlices or has none for the Service.
+func readyEndpointSliceEndpoints(ctx context.Context, name string, namespace string, clientSet kubernetes.Interface) (ready int, found bool, err error) {
+	opts := metaV1.ListOptions{
+		LabelSelector: discoveryV1beta1.LabelServiceName + "=" + name,
+		Limit:         utils.ResourcesListLimit,
+	}
+	seen := map[string]bool{}
+	for {
+		slices, err := clientSet.DiscoveryV1beta1().EndpointSlices(namespace).List(ctx, opts)
+		if k8serrors.IsNotFound(err) {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, pkgerrors.Wrap(err, "Get EndpointSlice list error")
+		}
+
+		for _, slice := range slices.Items {
+			found = true
+			for _, endpoint := range slice.Endpoints {
+				// A nil condition is to be interpreted as ready
+				if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+					continue
+				}
+				key := string(slice.AddressType) + "/" + strings.Join(endpoint.Addresses, ",")
+				if endpoint.TargetRef != nil && endpoint.TargetRef.UID != "" {
+					key = string(endpoint.TargetRef.UID)
+				}
+				if !seen[key] {
+					seen[key] = true
+					ready++
+				}
+			}
+		}
+
+		if slices.Continue == "" {
+			break
+		}
+		opts.Continue = slices.Continue
+	}
+	return ready, found, nil
+}
+
+// WatchUntilDeleted waits for a Service to be removed after its deletion,
+// which finalizers can delay. The watch is restarted if the apiserver closes
+// it before the timeout.
+func (g servicePlugin) WatchUntilDeleted(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	clientSet kubernetes.Interface) error {
+	if ns == "" {
+		ns = "default"
+	}
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(ctx, timeout)
+	defer cancel()
+
+	services := clientSet.CoreV1().Services(ns)
+	for {
+		service, err := services.Get(ctx, res.Name, metaV1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return pkgerrors.Wrap(err, "Get Service error")
+		}
+
+		watcher, err := services.Watch(ctx, metaV1.ListOptions{
+			FieldSelector:   "metadata.name=" + res.Name,
+			ResourceVersion: service.ResourceVersion,
+		})
+		if err != nil {
+			return pkgerrors.Wrap(err, "Watch Service error")
+		}
+		_, err = watchtools.UntilWithoutRetry(ctx, watcher, func(event watch.Event) (bool, error) {
+			switch event.Type {
+			case watch.Deleted:
+				return true, nil
+			case watch.Error:
+				return false, k8serrors.FromObject(event.Object)
+			}
+			return false, nil
+		})
+		if err == watchtools.ErrWatchClosed {
+			continue
+		}
+		if err != nil {
+			return pkgerrors.Wrapf(err, "Waiting for deletion of Service %s", res.Name)
+		}
+		return nil
+	}
+}
+
+// Create the service objects of a manifest in a specific Kubernetes cluster.
+// The manifest can hold several services separated by "---", the names of
+// the created services are returned separated by commas. Nothing is persisted
+// when the client is a plugin.DryRunConnector asking for a dry run.
+func (p servicePlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	services, err := p.CreateObjects(ctx, yamlFilePath, namespace, client)
+	return serviceNames(services), err
+}
+
+// CreateObjects creates the service objects of a manifest like Create and
+// returns them as populated by the apiserver, e.g. with their clusterIP and
+// node ports. The services created before an error are returned with it.
+func (p servicePlugin) CreateObjects(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (_ []*coreV1.Service, err error) {
+	defer metrics.ObservePluginOperation("create", "Service", time.Now(), &err)
+
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
+	objs, err := utils.DecodeManifestDocuments(yamlFilePath)
+	if err != nil {
+		return nil, plugin.WithKind(pkgerrors.Wrap(err, "Decode service object error"), plugin.ErrDecode)
+	}
+
+	return p.createServices(ctx, objs, namespace, client)
+}
+
+// CreateFromBytes creates the service objects of a manifest held in memory,
+// it accepts the same manifests as Create
+func (p servicePlugin) CreateFromBytes(ctx context.Context, manifest []byte, namespace string, client plugin.KubernetesConnector) (_ string, err error) {
+	defer metrics.ObservePluginOperation("create", "Service", time.Now(), &err)
+
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
+	objs, err := utils.DecodeManifestDocumentsBytes(manifest)
+	if err != nil {
+		return "", plugin.WithKind(pkgerrors.Wrap(err, "Decode service object error"), plugin.ErrDecode)
+	}
+
+	services, err := p.createServices(ctx, objs, namespace, client)
+	return serviceNames(services), err
+}
+
+// serviceNames returns the names of the services separated by commas
+func serviceNames(services []*coreV1.Service) string {
+	names := make([]string, 0, len(services))
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// createServices checks that all the decoded objects are services before
+// creating them one by one
+func (p servicePlugin) createServices(ctx context.Context, objs []runtime.Object, namespace string, client plugin.KubernetesConnector) ([]*coreV1.Service, error) {
+	if len(objs) == 0 {
+		return nil, plugin.WithKind(pkgerrors.New("Decoded manifest contains no Service"), plugin.ErrWrongResourceType)
+	}
+
+	// Check all the documents before creating any service
+	services := make([]*coreV1.Service, 0, len(objs))
+	for index, obj := range objs {
+		if err := plugin.AssertKind(obj, serviceGVK); err != nil {
+			return nil, pkgerrors.Wrapf(err, "Decoded document %d contains another resource different than Service", index)
+		}
+		service, ok := obj.(*coreV1.Service)
+		if !ok {
+			return nil, plugin.WithKind(pkgerrors.Errorf("Decoded document %d is a %T instead of a Service", index, obj),
+				plugin.ErrWrongResourceType)
+		}
+		services = append(services, service)
+	}
+
+	created := make([]*coreV1.Service, 0, len(services))
+	for _, service := range services {
+		result, err := p.createService(ctx, service, namespace, client)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, result)
+	}
+
+	return created, nil
+}
+
+// createService labels a decoded service with the instance ID and the extra
+// labels and creates it
+func (p servicePlugin) createService(ctx context.Context, service *coreV1.Service, namespace string, client plugin.KubernetesConnector) (*coreV1.Service, error) {
+	namespace, err := plugin.ResolveNamespace(service, namespace)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Resolve namespace error")
 	}
-	service.Namespace = namespace
 
-	labels := service.GetLabels()
-	//Check if labels exist for this object
-	if labels == nil {
-		labels = map[string]string{}
+	plugin.SetInstanceLabel(service, client.GetInstanceID())
+	plugin.SetExtraLabels(service)
+	plugin.SetOwnerReference(service, namespace, client)
+	plugin.PromoteAnnotationsToLabels(service)
+	plugin.FilterFinalizers(service)
+
+	if config.GetConfiguration().CreateMissingNamespaces {
+		if err := ensureNamespace(ctx, namespace, client); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.GetConfiguration().ServerSideApply {
+		result, err := applyService(ctx, service, namespace, client)
+		if err != nil {
+			return nil, plugin.WrapAPIError(err, "Apply Service error")
+		}
+		return result, nil
 	}
-	labels[config.GetConfiguration().KubernetesLabelName] = client.GetInstanceID()
-	service.SetLabels(labels)
 
-	result, err := client.GetStandardClient().CoreV1().Services(namespace).Create(context.TODO(), service, metaV1.CreateOptions{})
+	result, err := client.GetStandardClient().CoreV1().Services(namespace).Create(ctx, service, metaV1.CreateOptions{
+		FieldManager: plugin.FieldManager(client),
+		DryRun:       plugin.DryRun(client),
+	})
 	if err != nil {
-		return "", pkgerrors.Wrap(err, "Create Service error")
+		return nil, plugin.WrapAPIError(err, "Create Service error")
 	}
 
-	return result.GetObjectMeta().GetName(), nil
+	return result, nil
+}
+
+// ensureNamespace creates the namespace, labeled with the instance ID, if
+// it doesn't exist. A namespace created concurrently is not an error.
+func ensureNamespace(ctx context.Context, namespace string, client plugin.KubernetesConnector) error {
+	namespaces := client.GetStandardClient().CoreV1().Namespaces()
+	_, err := namespaces.Get(ctx, namespace, metaV1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		return plugin.WrapAPIError(err, "Get Namespace error")
+	}
+
+	namespaceObj := &coreV1.Namespace{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name: namespace,
+			Labels: map[string]string{
+				config.GetConfiguration().KubernetesLabelName: client.GetInstanceID(),
+			},
+		},
+	}
+	_, err = namespaces.Create(ctx, namespaceObj, metaV1.CreateOptions{
+		FieldManager: plugin.FieldManager(client),
+		DryRun:       plugin.DryRun(client),
+	})
+	if k8serrors.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		return plugin.WrapAPIError(err, "Create Namespace error")
+	}
+
+	fields := logFields("create", namespace, namespace, client)
+	fields["kind"] = "Namespace"
+	logutils.Info("Created missing namespace", fields)
+	return nil
 }
 
 // List of existing services hosted in a specific Kubernetes cluster
-// gvk parameter is not used as this plugin is specific to services only
-func (p servicePlugin) List(gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+// gvk parameter can be left empty as this plugin is specific to services
+// only, another kind is rejected with a *plugin.KindMismatchError
+func (p servicePlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) (_ []helm.KubernetesResource, err error) {
+	defer metrics.ObservePluginOperation("list", "Service", time.Now(), &err)
+
+	if !gvk.Empty() && gvk != serviceGVK {
+		return nil, pkgerrors.Wrap(&plugin.KindMismatchError{Expected: serviceGVK, Actual: gvk}, "List services error")
+	}
+
+	return p.ListSelected(ctx, namespace, "", client)
+}
+
+// ListSelected lists the existing services matching the label selector,
+// e.g. the instance label to list the services of an instance. An empty
+// selector lists all the services.
+func (p servicePlugin) ListSelected(ctx context.Context, namespace, labelSelector string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	services, err := p.ListDetailed(ctx, namespace, labelSelector, client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]helm.KubernetesResource, 0, len(services))
+	for _, service := range services {
+		result = append(result, service.KubernetesResource)
+	}
+
+	return result, nil
+}
+
+// ListDetailed lists the existing services like ListSelected, along with
+// their type, cluster IP and ports
+func (p servicePlugin) ListDetailed(ctx context.Context, namespace, labelSelector string, client plugin.KubernetesConnector) ([]plugin.ServiceInfo, error) {
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
 	if namespace == "" {
 		namespace = "default"
 	}
 
 	opts := metaV1.ListOptions{
-		Limit: utils.ResourcesListLimit,
+		LabelSelector: labelSelector,
+		Limit:         utils.ResourcesListLimit,
 	}
 
-	list, err := client.GetStandardClient().CoreV1().Services(namespace).List(context.TODO(), opts)
-	if err != nil {
-		return nil, pkgerrors.Wrap(err, "Get Service list error")
-	}
+	// Follow the continue token until all the pages are read
+	maxListed := config.GetConfiguration().MaxListedResources
+	result := make([]plugin.ServiceInfo, 0, utils.ResourcesListLimit)
+	for {
+		list, err := client.GetStandardClient().CoreV1().Services(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Get Service list error")
+		}
+		if maxListed > 0 && len(result)+len(list.Items) > maxListed {
+			return nil, pkgerrors.Errorf("Get Service list error: more than %d services", maxListed)
+		}
 
-	result := make([]helm.KubernetesResource, 0, utils.ResourcesListLimit)
-	if list != nil {
 		for _, service := range list.Items {
-			log.Printf("%v", service.Name)
+			logutils.Debug("Listed service", logFields("list", namespace, service.Name, client))
 			result = append(result,
-				helm.KubernetesResource{
-					GVK: schema.GroupVersionKind{
-						Group:   "",
-						Version: "v1",
-						Kind:    "Service",
+				plugin.ServiceInfo{
+					KubernetesResource: helm.KubernetesResource{
+						GVK: schema.GroupVersionKind{
+							Group:   "",
+							Version: "v1",
+							Kind:    "Service",
+						},
+						Name: service.GetName(),
 					},
-					Name: service.GetName(),
+					Type:      service.Spec.Type,
+					ClusterIP: service.Spec.ClusterIP,
+					Ports:     service.Spec.Ports,
 				})
 		}
+
+		if list.Continue == "" {
+			break
+		}
+		opts.Continue = list.Continue
 	}
 
+	fields := logFields("list", namespace, "", client)
+	delete(fields, "name")
+	fields["count"] = len(result)
+	logutils.Info("Listed services", fields)
+
 	return result, nil
 }
 
-// Delete an existing service hosted in a specific Kubernetes cluster
-func (p servicePlugin) Delete(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+// Delete an existing service hosted in a specific Kubernetes cluster, with
+// the configured propagation policy
+func (p servicePlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (err error) {
+	defer metrics.ObservePluginOperation("delete", "Service", time.Now(), &err)
+
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
 	if namespace == "" {
 		namespace = "default"
 	}
 
-	deletePolicy := metaV1.DeletePropagationBackground
+	deletePolicy, err := plugin.DeletePropagation()
+	if err != nil {
+		return err
+	}
 	opts := metaV1.DeleteOptions{
 		PropagationPolicy: &deletePolicy,
 	}
 
-	log.Println("Deleting service: " + resource.Name)
-	if err := client.GetStandardClient().CoreV1().Services(namespace).Delete(context.TODO(), resource.Name, opts); err != nil {
-		return pkgerrors.Wrap(err, "Delete service error")
+	logutils.Info("Deleting service", logFields("delete", namespace, resource.Name, client))
+	if err := client.GetStandardClient().CoreV1().Services(namespace).Delete(ctx, resource.Name, opts); err != nil {
+		return plugin.WrapAPIError(err, "Delete service error")
 	}
 
 	return nil
 }
 
+// CreateEach creates the services of each manifest like Create, carrying on
+// after a manifest fails. The result of each manifest is returned, with an
+// error if any of them failed, so that the caller can decide whether to
+// roll back the services that were created. When transactional is set, the
+// first failure stops the batch and the services created so far are
+// deleted again, see rollback.
+func (p servicePlugin) CreateEach(ctx context.Context, yamlFilePaths []string, namespace string, client plugin.KubernetesConnector,
+	transactional bool) ([]plugin.CreateResult, error) {
+	results := make([]plugin.CreateResult, 0, len(yamlFilePaths))
+	// created holds the services created so far and createdBy the index of
+	// the result of their manifest
+	var created []*coreV1.Service
+	var createdBy []int
+	failed := 0
+	for _, yamlFilePath := range yamlFilePaths {
+		services, err := p.CreateObjects(ctx, yamlFilePath, namespace, client)
+		for range services {
+			createdBy = append(createdBy, len(results))
+		}
+		created = append(created, services...)
+
+		result := plugin.CreateResult{
+			Manifest: yamlFilePath,
+			Name:     serviceNames(services),
+			Created:  err == nil,
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		results = append(results, result)
+
+		if err != nil && transactional {
+			return results, p.rollback(ctx, created, createdBy, results, client, err)
+		}
+	}
+
+	if failed > 0 {
+		return results, pkgerrors.Errorf("%d of %d service manifests not created", failed, len(results))
+	}
+	return results, nil
+}
+
+// rollback deletes the created services in reverse order with Delete, so
+// with the configured propagation policy, and marks the results of their
+// manifests as rolled back. Services that are not labeled with the instance
+// ID anymore are left alone. The original error is returned, wrapped with
+// the errors of the deletions that failed.
+func (p servicePlugin) rollback(ctx context.Context, created []*coreV1.Service, createdBy []int, results []plugin.CreateResult,
+	client plugin.KubernetesConnector, original error) error {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	remaining := make(map[int]bool)
+	var cleanupErrors []string
+	for i := len(created) - 1; i >= 0; i-- {
+		service := created[i]
+		if service.Labels[labelName] != client.GetInstanceID() {
+			remaining[createdBy[i]] = true
+			continue
+		}
+		resource := helm.KubernetesResource{GVK: serviceGVK, Name: service.Name}
+		err := p.Delete(ctx, resource, service.Namespace, client)
+		if err != nil && !errors.Is(err, plugin.ErrNotFound) {
+			cleanupErrors = append(cleanupErrors, err.Error())
+			remaining[createdBy[i]] = true
+		}
+	}
+
+	for _, index := range createdBy {
+		if !remaining[index] {
+			results[index].Created = false
+			results[index].RolledBack = true
+		}
+	}
+
+	if len(cleanupErrors) > 0 {
+		return pkgerrors.Wrapf(original, "Rollback errors: %s", strings.Join(cleanupErrors, "; "))
+	}
+	return original
+}
+
+// DeleteEach deletes the services matching the label selector one at a time,
+// unlike DeleteCollection which reports a single outcome. The result of each
+// deletion is returned, with an error if any of them failed.
+func (p servicePlugin) DeleteEach(ctx context.Context, namespace, labelSelector string, client plugin.KubernetesConnector) ([]plugin.DeleteResult, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	results := make([]plugin.DeleteResult, 0)
+	opts := metaV1.ListOptions{
+		LabelSelector: labelSelector,
+		Limit:         utils.ResourcesListLimit,
+	}
+	failed := 0
+	for {
+		list, err := client.GetStandardClient().CoreV1().Services(namespace).List(ctx, opts)
+		if err != nil {
+			return results, pkgerrors.Wrap(err, "Get Service list error")
+		}
+
+		for _, service := range list.Items {
+			result := plugin.DeleteResult{
+				Resource: helm.KubernetesResource{
+					GVK:  serviceGVK,
+					Name: service.Name,
+				},
+			}
+			err = p.Delete(ctx, result.Resource, namespace, client)
+			switch {
+			case err == nil, errors.Is(err, plugin.ErrNotFound):
+				result.Deleted = true
+			default:
+				result.Error = err.Error()
+				failed++
+			}
+			results = append(results, result)
+		}
+
+		if list.Continue == "" {
+			break
+		}
+		opts.Continue = list.Continue
+	}
+
+	if failed > 0 {
+		return results, pkgerrors.Errorf("%d of %d services not deleted", failed, len(results))
+	}
+	return results, nil
+}
+
+// DeleteAll deletes the services labeled with the given instance ID and
+// returns how many were deleted, with an error if any of them was not
+func (p servicePlugin) DeleteAll(ctx context.Context, namespace, instanceID string, client plugin.KubernetesConnector) (int, error) {
+	if instanceID == "" {
+		return 0, pkgerrors.New("Instance ID is required to delete the services of an instance")
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{
+		config.GetConfiguration().KubernetesLabelName: instanceID,
+	})
+	results, err := p.DeleteEach(ctx, namespace, selector.String(), client)
+	deleted := 0
+	for _, result := range results {
+		if result.Deleted {
+			deleted++
+		}
+	}
+	return deleted, err
+}
+
 // Get an existing service hosted in a specific Kubernetes cluster
-func (p servicePlugin) Get(resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+func (p servicePlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (_ string, err error) {
+	defer metrics.ObservePluginOperation("get", "Service", time.Now(), &err)
+
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
 	if namespace == "" {
 		namespace = "default"
 	}
 
 	opts := metaV1.GetOptions{}
-	service, err := client.GetStandardClient().CoreV1().Services(namespace).Get(context.TODO(), resource.Name, opts)
+	service, err := client.GetStandardClient().CoreV1().Services(namespace).Get(ctx, resource.Name, opts)
 	if err != nil {
-		return "", pkgerrors.Wrap(err, "Get Service error")
+		return "", plugin.WrapAPIError(err, "Get Service error")
 	}
 
 	return service.Name, nil
 }
 
-// Update a service object in a specific Kubernetes cluster
-func (p servicePlugin) Update(yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+// GetRaw returns an existing service hosted in a specific Kubernetes cluster
+// encoded in JSON, with its spec and status
+func (p servicePlugin) GetRaw(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (_ []byte, err error) {
+	defer metrics.ObservePluginOperation("get", "Service", time.Now(), &err)
+
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
 	if namespace == "" {
 		namespace = "default"
 	}
 
-	obj, err := utils.DecodeYAML(yamlFilePath, nil)
+	service, err := client.GetStandardClient().CoreV1().Services(namespace).Get(ctx, resource.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, plugin.WrapAPIError(err, "Get Service error")
+	}
+
+	// The typed client leaves the kind empty, set it for the object to be
+	// decodable on its own
+	service.SetGroupVersionKind(serviceGVK)
+	raw, err := json.Marshal(service)
 	if err != nil {
-		return "", pkgerrors.Wrap(err, "Decode service object error")
+		return nil, pkgerrors.Wrap(err, "Encode Service error")
 	}
+	return raw, nil
+}
+
+// Update a service object in a specific Kubernetes cluster, only validated
+// by the apiserver when the client asks for a dry run
+func (p servicePlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (_ string, err error) {
+	defer metrics.ObservePluginOperation("update", "Service", time.Now(), &err)
 
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return "", plugin.WithKind(pkgerrors.Wrap(err, "Decode service object error"), plugin.ErrDecode)
+	}
+
+	if err = plugin.AssertKind(obj, serviceGVK); err != nil {
+		return "", pkgerrors.Wrap(err, "Decoded object contains another resource different than Service")
+	}
 	service, ok := obj.(*coreV1.Service)
 	if !ok {
-		return "", pkgerrors.New("Decoded object contains another resource different than Service")
+		return "", plugin.WithKind(pkgerrors.Errorf("Decoded object is a %T instead of a Service", obj),
+			plugin.ErrWrongResourceType)
+	}
+	namespace, err = plugin.ResolveNamespace(service, namespace)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Resolve namespace error")
 	}
-	service.Namespace = namespace
 
-	existingService, err := client.GetStandardClient().CoreV1().Services(namespace).Get(context.TODO(), service.Name, metaV1.GetOptions{})
-	if err == nil {
+	if config.GetConfiguration().ServerSideApply {
+		// Apply creates the service if needed and leaves the fields owned
+		// by other managers, such as the allocated clusterIP, untouched
+		return p.Create(ctx, yamlFilePath, namespace, client)
+	}
+
+	updateOpts := metaV1.UpdateOptions{
+		FieldManager: plugin.FieldManager(client),
+		DryRun:       plugin.DryRun(client),
+	}
+	// The service is read again when the resourceVersion it was updated
+	// from became stale in between, e.g. because of a controller
+	desired := service
+	var existingService *coreV1.Service
+	var transition *plugin.ClusterIPTransitionError
+	missing := false
+	policy := config.GetConfiguration().UpdateConflictPolicy
+	err = retry.RetryOnConflict(updateBackoff(), func() error {
+		existing, err := client.GetStandardClient().CoreV1().Services(namespace).Get(ctx, desired.Name, metaV1.GetOptions{})
+		if err != nil {
+			missing = true
+			return nil
+		}
+		existingService = existing
+
+		// The clusterIP is kept from the live service below, which would
+		// silently drop a change to or from a headless service
+		if policy != plugin.UpdateConflictSkipImmutable && headlessTransition(desired, existingService) {
+			transition = &plugin.ClusterIPTransitionError{
+				Name: desired.Name,
+				From: existingService.Spec.ClusterIP,
+				To:   desired.Spec.ClusterIP,
+			}
+			return nil
+		}
+
+		service = desired.DeepCopy()
 		service.ResourceVersion = existingService.ResourceVersion
-		service.Spec.ClusterIP = existingService.Spec.ClusterIP
-	} else {
-		return p.Create(yamlFilePath, namespace, client)
+		service.SetAnnotations(mergeAnnotations(existingService.GetAnnotations(), service.GetAnnotations()))
+		if service.Spec.Type == coreV1.ServiceTypeExternalName {
+			preserveExternalName(service, existingService)
+		} else {
+			preserveClusterIPs(service, existingService)
+			preserveNodePorts(service, existingService)
+			preserveTrafficSettings(service, existingService)
+		}
+
+		plugin.SetInstanceLabel(service, client.GetInstanceID())
+		plugin.SetExtraLabels(service)
+		plugin.SetOwnerReference(service, namespace, client)
+
+		_, err = client.GetStandardClient().CoreV1().Services(namespace).Update(ctx, service, updateOpts)
+		return err
+	})
+	if missing {
+		return p.Create(ctx, yamlFilePath, namespace, client)
+	}
+	paths := plugin.ImmutableFieldPaths(err)
+	if transition != nil {
+		if policy != plugin.UpdateConflictRecreate {
+			return "", transition
+		}
+		paths = []string{"spec.clusterIP"}
+	}
+	if len(paths) > 0 {
+		switch policy {
+		case plugin.UpdateConflictSkipImmutable:
+			fields := logFields("update", namespace, service.Name, client)
+			fields["fields"] = paths
+			logutils.Info("Keeping immutable fields of service", fields)
+			err = plugin.CopyFieldPaths(service, existingService, paths)
+			if err != nil {
+				return "", pkgerrors.Wrap(err, "Skip immutable fields error")
+			}
+			_, err = client.GetStandardClient().CoreV1().Services(namespace).Update(ctx, service, updateOpts)
+		case plugin.UpdateConflictRecreate:
+			fields := logFields("update", namespace, service.Name, client)
+			fields["fields"] = paths
+			if plugin.DryRun(client) != nil {
+				logutils.Info("Dry run: service would be recreated to change immutable fields", fields)
+				return service.Name, nil
+			}
+			logutils.Info("Recreating service to change immutable fields", fields)
+			err = p.Delete(ctx, helm.KubernetesResource{Name: service.Name}, namespace, client)
+			if err != nil {
+				return "", pkgerrors.Wrap(err, "Recreate service error")
+			}
+			if _, err = p.Create(ctx, yamlFilePath, namespace, client); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err != nil {
+		return "", plugin.WrapAPIError(err, "Update object error")
+	}
+
+	// A dry run leaves the EndpointSlices alone
+	cleanup := config.GetConfiguration().CleanupOrphanedEndpointSlices && plugin.DryRun(client) == nil
+	orphans, err := orphanedEndpointSlices(ctx, service.Name, namespace, cleanup, client)
+	if err != nil {
+		fields := logFields("update", namespace, service.Name, client)
+		fields["error"] = err.Error()
+		logutils.Warn("Unable to check EndpointSlices of service", fields)
+	} else if len(orphans) > 0 {
+		fields := logFields("update", namespace, service.Name, client)
+		fields["endpointSlices"] = orphans
+		fields["removed"] = cleanup
+		logutils.Warn("Service has orphaned EndpointSlices", fields)
+	}
+
+	return service.Name, nil
+}
+
+// headlessTransition reports whether the update of a service turns it into
+// a headless one or a headless one into one with a clusterIP. ExternalName
+// services have no clusterIP and can become headless.
+func headlessTransition(desired, existing *coreV1.Service) bool {
+	if desired.Spec.Type == coreV1.ServiceTypeExternalName || existing.Spec.Type == coreV1.ServiceTypeExternalName {
+		return false
+	}
+	if existing.Spec.ClusterIP == "" {
+		return false
+	}
+	return (desired.Spec.ClusterIP == coreV1.ClusterIPNone) != (existing.Spec.ClusterIP == coreV1.ClusterIPNone)
+}
+
+// updateBackoff returns the backoff between the attempts to update a service
+// after a conflict, it allows the configured number of retries
+func updateBackoff() wait.Backoff {
+	backoff := retry.DefaultRetry
+	backoff.Steps = 1
+	if retries := config.GetConfiguration().UpdateRetries; retries > 0 {
+		backoff.Steps += retries
+	}
+	return backoff
+}
+
+// mergeAnnotations returns the annotations of the live service, such as
+// those added by cloud controllers, overridden by the ones of the manifest
+func mergeAnnotations(existing, desired map[string]string) map[string]string {
+	if len(existing) == 0 {
+		return desired
+	}
+	merged := make(map[string]string, len(existing)+len(desired))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+	return merged
+}
+
+// preserveNodePorts copies the node ports allocated to the live service to
+// the ports of the manifest that leave them unset, so that they are not
+// reallocated by the update. Ports are matched by name, or by port and
+// protocol when unnamed.
+func preserveNodePorts(desired, existing *coreV1.Service) {
+	if desired.Spec.Type != coreV1.ServiceTypeNodePort && desired.Spec.Type != coreV1.ServiceTypeLoadBalancer {
+		return
+	}
+
+	for i := range desired.Spec.Ports {
+		port := &desired.Spec.Ports[i]
+		if port.NodePort != 0 {
+			continue
+		}
+		for _, live := range existing.Spec.Ports {
+			if samePort(*port, live) {
+				port.NodePort = live.NodePort
+				break
+			}
+		}
+	}
+}
+
+// preserveClusterIPs copies the cluster IPs allocated to the live service
+// to the manifest, with its IP families and family policy when the manifest
+// leaves them unset. The primary family matches the primary cluster IP and
+// cannot change, and a dual-stack service keeps its secondary cluster IP
+// unless the manifest switches it to single-stack.
+func preserveClusterIPs(desired, existing *coreV1.Service) {
+	desired.Spec.ClusterIP = existing.Spec.ClusterIP
+	desired.Spec.ClusterIPs = existing.Spec.ClusterIPs
+	if len(desired.Spec.IPFamilies) == 0 {
+		desired.Spec.IPFamilies = existing.Spec.IPFamilies
+	}
+	if desired.Spec.IPFamilyPolicy == nil {
+		desired.Spec.IPFamilyPolicy = existing.Spec.IPFamilyPolicy
+	}
+
+	policy := desired.Spec.IPFamilyPolicy
+	if policy != nil && *policy == coreV1.IPFamilyPolicySingleStack {
+		if len(desired.Spec.ClusterIPs) > 1 {
+			desired.Spec.ClusterIPs = desired.Spec.ClusterIPs[:1]
+		}
+		if len(desired.Spec.IPFamilies) > 1 {
+			desired.Spec.IPFamilies = desired.Spec.IPFamilies[:1]
+		}
+	}
+}
+
+// preserveExternalName keeps the external name of the live service when the
+// manifest leaves it unset. ExternalName services have no cluster IP, IP
+// family or traffic settings, none of them are copied from the live service,
+// which may have had another type.
+func preserveExternalName(desired, existing *coreV1.Service) {
+	if desired.Spec.ExternalName == "" && existing.Spec.Type == coreV1.ServiceTypeExternalName {
+		desired.Spec.ExternalName = existing.Spec.ExternalName
+	}
+}
+
+// preserveTrafficSettings copies the traffic policy and session affinity of
+// the live service to the manifest when it leaves them unset. The health
+// check node port is only kept while the external traffic policy is Local,
+// as the apiserver rejects it otherwise.
+func preserveTrafficSettings(desired, existing *coreV1.Service) {
+	if desired.Spec.ExternalTrafficPolicy == "" {
+		desired.Spec.ExternalTrafficPolicy = existing.Spec.ExternalTrafficPolicy
+	}
+	if desired.Spec.SessionAffinity == "" {
+		desired.Spec.SessionAffinity = existing.Spec.SessionAffinity
+	}
+	if desired.Spec.SessionAffinityConfig == nil && desired.Spec.SessionAffinity == existing.Spec.SessionAffinity {
+		desired.Spec.SessionAffinityConfig = existing.Spec.SessionAffinityConfig
+	}
+	if desired.Spec.HealthCheckNodePort == 0 &&
+		desired.Spec.ExternalTrafficPolicy == coreV1.ServiceExternalTrafficPolicyTypeLocal {
+		desired.Spec.HealthCheckNodePort = existing.Spec.HealthCheckNodePort
+	}
+}
+
+func samePort(a, b coreV1.ServicePort) bool {
+	if a.Name != "" || b.Name != "" {
+		return a.Name == b.Name
 	}
-	labels := service.GetLabels()
-	//Check if labels exist for this object
-	if labels == nil {
-		labels = map[string]string{}
+	protocol := func(p coreV1.Protocol) coreV1.Protocol {
+		if p == "" {
+			return coreV1.ProtocolTCP
+		}
+		return p
 	}
-	labels[config.GetConfiguration().KubernetesLabelName] = client.GetInstanceID()
-	service.SetLabels(labels)
+	return a.Port == b.Port && protocol(a.Protocol) == protocol(b.Protocol)
+}
+
+// Patch a service object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p servicePlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (_ string, err error) {
+	defer metrics.ObservePluginOperation("patch", "Service", time.Now(), &err)
 
-	_, err = client.GetStandardClient().CoreV1().Services(namespace).Update(context.TODO(), service, metaV1.UpdateOptions{})
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
 
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	services := client.GetStandardClient().CoreV1().Services(namespace)
+	opts := metaV1.PatchOptions{
+		FieldManager: plugin.FieldManager(client),
+	}
+	service, err := services.Patch(ctx, resource.Name, patchType, patchData, opts)
 	if err != nil {
-		return "", pkgerrors.Wrap(err, "Update object error")
+		return "", pkgerrors.Wrap(err, "Patch Service error")
+	}
+
+	if service.Labels[config.GetConfiguration().KubernetesLabelName] != client.GetInstanceID() {
+		labelPatch, err := plugin.InstanceLabelPatch(client)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Marshal label patch error")
+		}
+		service, err = services.Patch(ctx, resource.Name, types.MergePatchType, labelPatch, opts)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "Restore instance label error")
+		}
 	}
 
 	return service.Name, nil
 }
+
+// Reconcile re-applies the fields of the live service that drifted from the
+// manifest, e.g. after a manual edit, and reports whether a change was made.
+// Fields managed by the apiserver, such as the clusterIP, node ports and
+// resourceVersion, are taken from the live service as Update does and are
+// not drift. Only the drifted fields are sent, as a strategic merge patch.
+// A missing service is created.
+func (p servicePlugin) Reconcile(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (_ bool, err error) {
+	defer metrics.ObservePluginOperation("reconcile", "Service", time.Now(), &err)
+
+	ctx, cancel := plugin.WithRequestTimeout(ctx)
+	defer cancel()
+
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return false, plugin.WithKind(pkgerrors.Wrap(err, "Decode service object error"), plugin.ErrDecode)
+	}
+
+	if err = plugin.AssertKind(obj, serviceGVK); err != nil {
+		return false, pkgerrors.Wrap(err, "Decoded object contains another resource different than Service")
+	}
+	desired, ok := obj.(*coreV1.Service)
+	if !ok {
+		return false, plugin.WithKind(pkgerrors.Errorf("Decoded object is a %T instead of a Service", obj),
+			plugin.ErrWrongResourceType)
+	}
+	namespace, err = plugin.ResolveNamespace(desired, namespace)
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Resolve namespace error")
+	}
+
+	services := client.GetStandardClient().CoreV1().Services(namespace)
+	live, err := services.Get(ctx, desired.Name, metaV1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		if _, err = p.Create(ctx, yamlFilePath, namespace, client); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, plugin.WrapAPIError(err, "Get Service error")
+	}
+
+	reconciled := reconciledService(desired, live)
+	plugin.SetInstanceLabel(reconciled, client.GetInstanceID())
+	plugin.SetExtraLabels(reconciled)
+	plugin.SetOwnerReference(reconciled, namespace, client)
+
+	original, err := json.Marshal(live)
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Marshal service error")
+	}
+	modified, err := json.Marshal(reconciled)
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Marshal service error")
+	}
+	patch, err := strategicpatch.CreateTwoWayMergePatch(original, modified, coreV1.Service{})
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Compute service drift error")
+	}
+	if string(patch) == "{}" {
+		return false, nil
+	}
+
+	fields := logFields("reconcile", namespace, desired.Name, client)
+	fields["patch"] = string(patch)
+	logutils.Info("Reconciling drifted service", fields)
+	opts := metaV1.PatchOptions{
+		FieldManager: plugin.FieldManager(client),
+		DryRun:       plugin.DryRun(client),
+	}
+	_, err = services.Patch(ctx, desired.Name, types.StrategicMergePatchType, patch, opts)
+	if err != nil {
+		return false, plugin.WrapAPIError(err, "Reconcile service error")
+	}
+
+	return true, nil
+}
+
+// reconciledService returns the live service with the labels, annotations
+// and spec of the manifest. The fields left to the apiserver are kept from
+// the live service, and the defaults it applies are set where the manifest
+// leaves them unset so that they don't show as drift.
+func reconciledService(desired, live *coreV1.Service) *coreV1.Service {
+	spec := desired.Spec.DeepCopy()
+	if spec.Type == "" {
+		spec.Type = coreV1.ServiceTypeClusterIP
+	}
+	for i := range spec.Ports {
+		port := &spec.Ports[i]
+		if port.Protocol == "" {
+			port.Protocol = coreV1.ProtocolTCP
+		}
+		if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal == 0 {
+			port.TargetPort = intstr.FromInt(int(port.Port))
+		}
+	}
+
+	service := live.DeepCopy()
+	service.Spec = *spec
+	// Labels added to the live service are kept like annotations
+	service.SetLabels(mergeAnnotations(live.GetLabels(), desired.GetLabels()))
+	service.SetAnnotations(mergeAnnotations(live.GetAnnotations(), desired.GetAnnotations()))
+	if service.Spec.Type == coreV1.ServiceTypeExternalName {
+		preserveExternalName(service, live)
+		return service
+	}
+	preserveClusterIPs(service, live)
+	preserveNodePorts(service, live)
+	preserveTrafficSettings(service, live)
+	return service
+}
+
+// orphanedEndpointSlices returns the names of the EndpointSlices of a
+// service that are managed by the EndpointSlice controller but not owned
+// by the live service object, e.g. left behind when the service was
+// recreated. The orphaned slices are deleted when cleanup is set.
+func orphanedEndpointSlices(ctx context.Context, name string, namespace string, cleanup bool, client plugin.KubernetesConnector) ([]string, error) {
+	service, err := client.GetStandardClient().CoreV1().Services(namespace).Get(ctx, name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Get Service error")
+	}
+
+	opts := metaV1.ListOptions{
+		LabelSelector: discoveryV1beta1.LabelServiceName + "=" + name,
+	}
+	sliceClient := client.GetStandardClient().DiscoveryV1beta1().EndpointSlices(namespace)
+	list, err := sliceClient.List(ctx, opts)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Get EndpointSlice list error")
+	}
+
+	var orphans []string
+	for _, slice := range list.Items {
+		if slice.Labels[discoveryV1beta1.LabelManagedBy] != endpointSliceControllerName {
+			continue
+		}
+		owned := false
+		for _, ref := range slice.OwnerReferences {
+			if ref.Kind == "Service" && ref.UID == service.UID {
+				owned = true
+				break
+			}
+		}
+		if owned {
+			continue
+		}
+
+		orphans = append(orphans, slice.Name)
+		if cleanup {
+			err = sliceClient.Delete(ctx, slice.Name, metaV1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return orphans, pkgerrors.Wrap(err, "Delete EndpointSlice error")
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+// applyService creates or updates the service with Server-Side Apply.
+// When other managers own some of the applied fields, the ownership is
+// taken over if ForceApply is configured, otherwise the conflict is returned.
+func applyService(ctx context.Context, service *coreV1.Service, namespace string, client plugin.KubernetesConnector) (*coreV1.Service, error) {
+	service.APIVersion = "v1"
+	service.Kind = "Service"
+	data, err := json.Marshal(service)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Marshal service error")
+	}
+
+	force := false
+	opts := metaV1.PatchOptions{
+		FieldManager: plugin.FieldManager(client),
+		Force:        &force,
+		DryRun:       plugin.DryRun(client),
+	}
+	services := client.GetStandardClient().CoreV1().Services(namespace)
+	result, err := services.Patch(ctx, service.Name, types.ApplyPatchType, data, opts)
+	if k8serrors.IsConflict(err) && config.GetConfiguration().ForceApply {
+		fields := logFields("apply", namespace, service.Name, client)
+		fields["error"] = err.Error()
+		logutils.Warn("Taking over the ownership of fields of service", fields)
+		force = true
+		result, err = services.Patch(ctx, service.Name, types.ApplyPatchType, data, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// logFields returns the fields identifying a service operation in the logs.
+// client is nil when the operation is not bound to an instance.
+func logFields(operation, namespace, name string, client plugin.KubernetesConnector) logutils.Fields {
+	fields := logutils.Fields{
+		"kind":      "Service",
+		"operation": operation,
+		"namespace": namespace,
+		"name":      name,
+	}
+	if client != nil {
+		fields["instance"] = client.GetInstanceID()
+	}
+	return fields
+}
@@ -14,20 +14,49 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
 	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/logutils"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/metrics"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
 
+	pkgerrors "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	coreV1 "k8s.io/api/core/v1"
+	discoveryV1beta1 "k8s.io/api/discovery/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 type TestKubernetesConnector struct {
@@ -50,6 +79,29 @@ func (t TestKubernetesConnector) GetInstanceID() string {
 	return ""
 }
 
+// fakeKubernetesConnector keeps the same clientset across calls so that
+// objects created by one plugin call are visible to the next ones
+type fakeKubernetesConnector struct {
+	clientSet  kubernetes.Interface
+	instanceID string
+}
+
+func (t fakeKubernetesConnector) GetMapper() meta.RESTMapper {
+	return nil
+}
+
+func (t fakeKubernetesConnector) GetDynamicClient() dynamic.Interface {
+	return nil
+}
+
+func (t fakeKubernetesConnector) GetStandardClient() kubernetes.Interface {
+	return t.clientSet
+}
+
+func (t fakeKubernetesConnector) GetInstanceID() string {
+	return t.instanceID
+}
+
 func TestCreateService(t *testing.T) {
 	name := "mock-service"
 	testCases := []struct {
@@ -79,7 +131,7 @@ func TestCreateService(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			result, err := servicePlugin{}.Create(testCase.input, testCase.namespace, client)
+			result, err := servicePlugin{}.Create(context.TODO(), testCase.input, testCase.namespace, client)
 			if err != nil {
 				if testCase.expectedError == "" {
 					t.Fatalf("Create method return an un-expected (%s)", err)
@@ -103,6 +155,638 @@ func TestCreateService(t *testing.T) {
 	}
 }
 
+func TestServiceKindMismatch(t *testing.T) {
+	client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+	expected := plugin.KindMismatchError{
+		Expected: coreV1.SchemeGroupVersion.WithKind("Service"),
+		Actual:   schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+
+	for operation, call := range map[string]func(string) (string, error){
+		"Create": func(path string) (string, error) {
+			return servicePlugin{}.Create(context.TODO(), path, "test1", client)
+		},
+		"Update": func(path string) (string, error) {
+			return servicePlugin{}.Update(context.TODO(), path, "test1", client)
+		},
+	} {
+		t.Run(operation, func(t *testing.T) {
+			_, err := call("../../mock_files/mock_yamls/deployment.yaml")
+			var mismatch *plugin.KindMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("Expected a kind mismatch error, got %v", err)
+			}
+			if *mismatch != expected {
+				t.Fatalf("Expected %+v, got %+v", expected, *mismatch)
+			}
+			if !errors.Is(err, plugin.ErrWrongResourceType) {
+				t.Fatalf("Expected the error to match ErrWrongResourceType, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateMultipleServices(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	manifest := plugintest.WriteManifest(t, `# Services of the frontend
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc-a
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc-b
+  labels:
+    app: frontend
+spec:
+  ports:
+  - port: 443
+---
+`)
+	client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+
+	result, err := servicePlugin{}.Create(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+	if result != "svc-a,svc-b" {
+		t.Fatalf("Create method returned %q, expected %q", result, "svc-a,svc-b")
+	}
+	for _, name := range []string{"svc-a", "svc-b"} {
+		service, err := client.GetStandardClient().CoreV1().Services("test1").
+			Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected service %s to be created (%s)", name, err)
+		}
+		if service.Labels[labelName] != "inst1" {
+			t.Fatalf("Expected service %s to be labeled with the instance ID, got %v", name, service.Labels)
+		}
+	}
+
+	mixed := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-c
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm-a
+`)
+	_, err = servicePlugin{}.Create(context.TODO(), mixed, "test1", client)
+	if err == nil || !strings.Contains(err.Error(), "document 1 contains another resource") {
+		t.Fatalf("Expected an error naming document 1, got %v", err)
+	}
+	_, err = client.GetStandardClient().CoreV1().Services("test1").
+		Get(context.TODO(), "svc-c", metaV1.GetOptions{})
+	if !k8serrors.IsNotFound(err) {
+		t.Fatalf("Expected no service to be created from an invalid manifest, got %v", err)
+	}
+}
+
+func TestCreateServiceObjects(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+	// The apiserver allocates the clusterIP and node ports of new services
+	clientSet.PrependReactor("create", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		service := action.(k8stesting.CreateAction).GetObject().(*coreV1.Service)
+		service.Spec.ClusterIP = "10.96.0.10"
+		for i := range service.Spec.Ports {
+			service.Spec.Ports[i].NodePort = 30080 + int32(i)
+		}
+		return false, nil, nil
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	services, err := servicePlugin{}.CreateObjects(context.TODO(), plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-a
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+`), "test1", client)
+	if err != nil {
+		t.Fatalf("CreateObjects method returned an error (%s)", err)
+	}
+	if len(services) != 1 || services[0].Name != "svc-a" {
+		t.Fatalf("CreateObjects method returned %v, expected svc-a", services)
+	}
+	if services[0].Spec.ClusterIP != "10.96.0.10" || services[0].Spec.Ports[0].NodePort != 30080 {
+		t.Fatalf("Expected the allocated clusterIP and node port, got %+v", services[0].Spec)
+	}
+	if services[0].Labels[config.GetConfiguration().KubernetesLabelName] != "inst1" {
+		t.Fatalf("Expected the service to be labeled with the instance ID, got %v", services[0].Labels)
+	}
+}
+
+func TestCreateServiceMissingNamespace(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	conf := config.GetConfiguration()
+	oldCreate := conf.CreateMissingNamespaces
+	defer func() {
+		conf.CreateMissingNamespaces = oldCreate
+	}()
+
+	for _, create := range []bool{false, true} {
+		t.Run(fmt.Sprintf("create-missing-namespaces=%t", create), func(t *testing.T) {
+			conf.CreateMissingNamespaces = create
+
+			// The fake clientset doesn't check namespaces, reject services
+			// created in a namespace that doesn't exist like the apiserver
+			clientSet := fake.NewSimpleClientset()
+			clientSet.PrependReactor("create", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				namespaces := coreV1.SchemeGroupVersion.WithResource("namespaces")
+				_, err := clientSet.Tracker().Get(namespaces, "", action.GetNamespace())
+				return err != nil, nil, err
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Create(context.TODO(), "../../mock_files/mock_yamls/service.yaml", "new-ns", client)
+			if !create {
+				if !k8serrors.IsNotFound(pkgerrors.Cause(err)) {
+					t.Fatalf("Expected a namespace not found error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+
+			ns, err := clientSet.CoreV1().Namespaces().Get(context.TODO(), "new-ns", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the namespace to be created (%s)", err)
+			}
+			if ns.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected the namespace to be labeled with the instance ID, got %v", ns.Labels)
+			}
+		})
+	}
+}
+
+func TestCreateServiceFromBytes(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+
+	result, err := servicePlugin{}.CreateFromBytes(context.TODO(), []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: svc-a
+spec:
+  ports:
+  - port: 80
+`), "test1", client)
+	if err != nil {
+		t.Fatalf("CreateFromBytes method returned an error (%s)", err)
+	}
+	if result != "svc-a" {
+		t.Fatalf("CreateFromBytes method returned %q, expected %q", result, "svc-a")
+	}
+	service, err := client.GetStandardClient().CoreV1().Services("test1").
+		Get(context.TODO(), "svc-a", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected service svc-a to be created (%s)", err)
+	}
+	if service.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected service svc-a to be labeled with the instance ID, got %v", service.Labels)
+	}
+
+	_, err = servicePlugin{}.CreateFromBytes(context.TODO(), []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm-a"}}`),
+		"test1", client)
+	if !errors.Is(err, plugin.ErrWrongResourceType) {
+		t.Fatalf("Expected a wrong resource type error, got %v", err)
+	}
+
+	_, err = servicePlugin{}.CreateFromBytes(context.TODO(), []byte("kind: [Service"), "test1", client)
+	if !errors.Is(err, plugin.ErrDecode) {
+		t.Fatalf("Expected a decode error, got %v", err)
+	}
+}
+
+func TestServiceOperationMetrics(t *testing.T) {
+	// scrape returns the value of the create counter of services served on
+	// the metrics endpoint, 0 before the first operation
+	scrape := func(result string) float64 {
+		recorder := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		prefix := fmt.Sprintf(`k8splugin_plugin_operations_total{kind="Service",operation="create",result="%s"} `, result)
+		for _, line := range strings.Split(recorder.Body.String(), "\n") {
+			if strings.HasPrefix(line, prefix) {
+				var value float64
+				fmt.Sscan(strings.TrimPrefix(line, prefix), &value)
+				return value
+			}
+		}
+		return 0
+	}
+
+	client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+	successes, failures := scrape("success"), scrape("error")
+
+	_, err := servicePlugin{}.CreateFromBytes(context.TODO(), []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: svc-metrics
+`), "test1", client)
+	if err != nil {
+		t.Fatalf("CreateFromBytes method returned an error (%s)", err)
+	}
+	if value := scrape("success"); value != successes+1 {
+		t.Fatalf("Expected %v successful creates, got %v", successes+1, value)
+	}
+
+	_, err = servicePlugin{}.Create(context.TODO(), "../../mock_files/mock_yamls/deployment.yaml", "test1", client)
+	if err == nil {
+		t.Fatal("Expected Create to fail for a deployment")
+	}
+	if value := scrape("error"); value != failures+1 {
+		t.Fatalf("Expected %v failed creates, got %v", failures+1, value)
+	}
+}
+
+// captureLogs redirects the structured logs to the returned buffer at the
+// given level until the end of the test
+func captureLogs(t *testing.T, level string) *bytes.Buffer {
+	var buf bytes.Buffer
+	oldOutput, oldLevel := logrus.StandardLogger().Out, logrus.GetLevel()
+	logrus.SetOutput(&buf)
+	if err := logutils.SetLevel(level); err != nil {
+		t.Fatalf("Unable to set the log level (%s)", err)
+	}
+	t.Cleanup(func() {
+		logrus.SetOutput(oldOutput)
+		logrus.SetLevel(oldLevel)
+	})
+	return &buf
+}
+
+// logEntries decodes the JSON log lines written to buf
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unable to decode log line %q (%s)", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestServiceStructuredLogs(t *testing.T) {
+	buf := captureLogs(t, "debug")
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "svc-logs", Namespace: "test1"},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	if _, err := (servicePlugin{}).List(context.TODO(), schema.GroupVersionKind{}, "test1", client); err != nil {
+		t.Fatalf("List method returned an error (%s)", err)
+	}
+	err := servicePlugin{}.Delete(context.TODO(), helm.KubernetesResource{Name: "svc-logs"}, "test1", client)
+	if err != nil {
+		t.Fatalf("Delete method returned an error (%s)", err)
+	}
+
+	expected := map[string]string{"list": "debug", "delete": "info"}
+	for _, entry := range logEntries(t, buf) {
+		operation, _ := entry["operation"].(string)
+		level, ok := expected[operation]
+		if !ok {
+			continue
+		}
+		if entry["level"] != level {
+			t.Fatalf("Expected %s to be logged at %s level, got %v", operation, level, entry["level"])
+		}
+		for key, value := range map[string]string{
+			"instance": "inst1", "namespace": "test1", "kind": "Service", "name": "svc-logs",
+		} {
+			if entry[key] != value {
+				t.Fatalf("Expected field %s=%s in %v", key, value, entry)
+			}
+		}
+		delete(expected, operation)
+	}
+	if len(expected) > 0 {
+		t.Fatalf("Expected logs for operations %v, got %s", expected, buf.String())
+	}
+}
+
+func TestGetRawService(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "svc-raw", Namespace: "test1"},
+		Spec: coreV1.ServiceSpec{
+			Ports: []coreV1.ServicePort{{Name: "http", Port: 80}},
+		},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	raw, err := servicePlugin{}.GetRaw(context.TODO(), helm.KubernetesResource{Name: "svc-raw"}, "test1", client)
+	if err != nil {
+		t.Fatalf("GetRaw method returned an error (%s)", err)
+	}
+	service := coreV1.Service{}
+	if err = json.Unmarshal(raw, &service); err != nil {
+		t.Fatalf("Unable to unmarshal the returned object (%s): %s", err, raw)
+	}
+	if service.Name != "svc-raw" || service.Kind != "Service" || service.APIVersion != "v1" {
+		t.Fatalf("Expected the v1 Service svc-raw, got %s %s %s", service.APIVersion, service.Kind, service.Name)
+	}
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != 80 {
+		t.Fatalf("Expected the spec of the service to be returned, got %+v", service.Spec)
+	}
+
+	_, err = servicePlugin{}.GetRaw(context.TODO(), helm.KubernetesResource{Name: "missing"}, "test1", client)
+	if !errors.Is(err, plugin.ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestServiceTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	plugin.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer plugin.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	traced := plugin.WithTracing("Service", servicePlugin{})
+	if _, ok := plugin.Unwrap(traced).(servicePlugin); !ok {
+		t.Fatalf("Expected Unwrap to return the service plugin, got %T", plugin.Unwrap(traced))
+	}
+
+	testCases := []struct {
+		label          string
+		input          string
+		expectedStatus codes.Code
+	}{
+		{
+			label:          "Create a service",
+			input:          "../../mock_files/mock_yamls/service.yaml",
+			expectedStatus: codes.Unset,
+		},
+		{
+			label:          "Fail to create a service with invalid type",
+			input:          "../../mock_files/mock_yamls/deployment.yaml",
+			expectedStatus: codes.Error,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			exporter.Reset()
+			client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+			traced.Create(context.TODO(), testCase.input, "test1", client)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("Expected one span, got %d", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "Create Service" {
+				t.Fatalf("Expected a span named Create Service, got %s", span.Name)
+			}
+			attributes := map[string]string{}
+			for _, attr := range span.Attributes {
+				attributes[string(attr.Key)] = attr.Value.Emit()
+			}
+			for key, value := range map[string]string{"k8s.namespace.name": "test1", "k8splugin.instance.id": "inst1"} {
+				if attributes[key] != value {
+					t.Fatalf("Expected attribute %s=%s, got %v", key, value, attributes)
+				}
+			}
+			if span.Status.Code != testCase.expectedStatus {
+				t.Fatalf("Expected span status %v, got %v", testCase.expectedStatus, span.Status.Code)
+			}
+			if testCase.expectedStatus == codes.Error && len(span.Events) == 0 {
+				t.Fatalf("Expected the error to be recorded on the span")
+			}
+		})
+	}
+}
+
+func TestServiceTracingOptionalInterfaces(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	plugin.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer plugin.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	traced := plugin.WithTracing("Service", servicePlugin{})
+	res := helm.KubernetesResource{Name: "svc-traced"}
+	newClient := func() fakeKubernetesConnector {
+		clientSet := fake.NewSimpleClientset(&coreV1.Service{
+			ObjectMeta: metaV1.ObjectMeta{Name: "svc-traced", Namespace: "test1"},
+		})
+		return fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+	}
+
+	testCases := []struct {
+		label        string
+		expectedSpan string
+		call         func(t *testing.T) error
+	}{
+		{
+			label:        "ProgressWatcher",
+			expectedSpan: "WatchUntilReady Service",
+			call: func(t *testing.T) error {
+				watcher, ok := plugin.AsProgressWatcher(traced)
+				if !ok {
+					t.Fatalf("Expected the traced plugin to report progress")
+				}
+				return watcher.WatchUntilReadyWithProgress(context.TODO(), time.Second, "test1", res,
+					newClient().clientSet, nil)
+			},
+		},
+		{
+			label:        "DeletionWatcher",
+			expectedSpan: "WatchUntilDeleted Service",
+			call: func(t *testing.T) error {
+				watcher, ok := plugin.AsDeletionWatcher(traced)
+				if !ok {
+					t.Fatalf("Expected the traced plugin to watch deletions")
+				}
+				clientSet := fake.NewSimpleClientset()
+				return watcher.WatchUntilDeleted(context.TODO(), time.Second, "test1", res, clientSet)
+			},
+		},
+		{
+			label:        "BytesCreator",
+			expectedSpan: "Create Service",
+			call: func(t *testing.T) error {
+				creator, ok := plugin.AsBytesCreator(traced)
+				if !ok {
+					t.Fatalf("Expected the traced plugin to create from bytes")
+				}
+				_, err := creator.CreateFromBytes(context.TODO(), []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: svc-bytes
+`), "test1", newClient())
+				return err
+			},
+		},
+		{
+			label:        "RawGetter",
+			expectedSpan: "GetRaw Service",
+			call: func(t *testing.T) error {
+				getter, ok := plugin.AsRawGetter(traced)
+				if !ok {
+					t.Fatalf("Expected the traced plugin to return whole objects")
+				}
+				_, err := getter.GetRaw(context.TODO(), res, "test1", newClient())
+				return err
+			},
+		},
+		{
+			label:        "OwnerConnector",
+			expectedSpan: "Create Service",
+			call: func(t *testing.T) error {
+				owner := metaV1.OwnerReference{APIVersion: "k8splugin.io/v1alpha1", Kind: "ResourceBundleState",
+					Name: "bundle", UID: "bundle-uid"}
+				client := ownerConnector{fakeKubernetesConnector: newClient(), owner: &owner, ownerNamespace: "test1"}
+				if _, err := traced.Create(context.TODO(), "../../mock_files/mock_yamls/service.yaml", "test1", client); err != nil {
+					return err
+				}
+				service, err := client.clientSet.CoreV1().Services("test1").
+					Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if !reflect.DeepEqual(service.OwnerReferences, []metaV1.OwnerReference{owner}) {
+					t.Fatalf("Expected the owner of the connector, got %+v", service.OwnerReferences)
+				}
+				return nil
+			},
+		},
+		{
+			label:        "DryRunConnector",
+			expectedSpan: "Create Service",
+			call: func(t *testing.T) error {
+				var dryRun string
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					if r.Method == http.MethodGet {
+						w.WriteHeader(http.StatusNotFound)
+						json.NewEncoder(w).Encode(k8serrors.NewNotFound(coreV1.Resource("services"), "mock-service").Status())
+						return
+					}
+					dryRun = r.URL.Query().Get("dryRun")
+					io.Copy(w, r.Body)
+				}))
+				defer server.Close()
+				clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+				if err != nil {
+					t.Fatalf("Unable to create client (%s)", err)
+				}
+				client := dryRunConnector{fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}}
+				if _, err = traced.Create(context.TODO(), "../../mock_files/mock_yamls/service.yaml", "test1", client); err != nil {
+					return err
+				}
+				if dryRun != metaV1.DryRunAll {
+					t.Fatalf("Expected a dry run create, got dryRun=%q", dryRun)
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			exporter.Reset()
+			if err := testCase.call(t); err != nil {
+				t.Fatalf("The traced call returned an error (%s)", err)
+			}
+			var names []string
+			for _, span := range exporter.GetSpans() {
+				names = append(names, span.Name)
+			}
+			if len(names) != 1 || names[0] != testCase.expectedSpan {
+				t.Fatalf("Expected a span named %s, got %v", testCase.expectedSpan, names)
+			}
+		})
+	}
+}
+
+func TestListServiceLogsSummary(t *testing.T) {
+	buf := captureLogs(t, "info")
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-a", Namespace: "test1"}},
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-b", Namespace: "test1"}},
+	)
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	if _, err := (servicePlugin{}).List(context.TODO(), schema.GroupVersionKind{}, "test1", client); err != nil {
+		t.Fatalf("List method returned an error (%s)", err)
+	}
+
+	entries := logEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("Expected a single summary line, got %s", buf.String())
+	}
+	if entries[0]["count"] != float64(2) || entries[0]["level"] != "info" {
+		t.Fatalf("Expected an info summary with a count of 2, got %v", entries[0])
+	}
+	if strings.Contains(buf.String(), "svc-a") || strings.Contains(buf.String(), "svc-b") {
+		t.Fatalf("Expected the service names not to be logged, got %s", buf.String())
+	}
+}
+
+func TestListServiceGVK(t *testing.T) {
+	testCases := []struct {
+		label         string
+		gvk           schema.GroupVersionKind
+		expectedError bool
+	}{
+		{
+			label: "Empty GVK",
+			gvk:   schema.GroupVersionKind{},
+		},
+		{
+			label: "Service GVK",
+			gvk:   schema.GroupVersionKind{Version: "v1", Kind: "Service"},
+		},
+		{
+			label:         "Deployment GVK",
+			gvk:           schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(
+				&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-a", Namespace: "test1"}},
+			)
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			result, err := servicePlugin{}.List(context.TODO(), testCase.gvk, "test1", client)
+			if testCase.expectedError {
+				var mismatch *plugin.KindMismatchError
+				if !errors.As(err, &mismatch) || !errors.Is(err, plugin.ErrWrongResourceType) {
+					t.Fatalf("List method returned %v, expected a kind mismatch error", err)
+				}
+				if mismatch.Actual != testCase.gvk {
+					t.Fatalf("Expected the mismatch to report %v, got %v", testCase.gvk, mismatch.Actual)
+				}
+				if len(clientSet.Actions()) != 0 {
+					t.Fatalf("Expected no service to be listed, got %v", clientSet.Actions())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List method returned an error (%s)", err)
+			}
+			if len(result) != 1 || result[0].Name != "svc-a" {
+				t.Fatalf("Expected the service to be listed, got %v", result)
+			}
+		})
+	}
+}
+
 func TestListService(t *testing.T) {
 	testCases := []struct {
 		label          string
@@ -167,7 +851,7 @@ func TestListService(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			result, err := servicePlugin{}.List(schema.GroupVersionKind{
+			result, err := servicePlugin{}.List(context.TODO(), schema.GroupVersionKind{
 				Group:   "",
 				Version: "v1",
 				Kind:    "Service"}, testCase.namespace, client)
@@ -186,41 +870,254 @@ func TestListService(t *testing.T) {
 	}
 }
 
-func TestDeleteService(t *testing.T) {
-	testCases := []struct {
-		label  string
-		input  map[string]string
-		object *coreV1.Service
-	}{
+func TestListSelectedService(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	newService := func(name, instanceID string) *coreV1.Service {
+		return &coreV1.Service{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      name,
+				Namespace: "test1",
+				Labels:    map[string]string{labelName: instanceID},
+			},
+		}
+	}
+	clientSet := fake.NewSimpleClientset(newService("svc-a", "inst1"), newService("svc-b", "inst2"))
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	result, err := servicePlugin{}.ListSelected(context.TODO(), "test1", labelName+"="+client.GetInstanceID(), client)
+	if err != nil {
+		t.Fatalf("ListSelected method returned an error (%s)", err)
+	}
+	expected := []helm.KubernetesResource{
 		{
-			label: "Sucessfully to delete an existing service",
-			input: map[string]string{"name": "test-service", "namespace": "test-namespace"},
-			object: &coreV1.Service{
-				ObjectMeta: metaV1.ObjectMeta{
-					Name:      "test-service",
-					Namespace: "test-namespace",
-				},
+			Name: "svc-a",
+			GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("ListSelected method returned: \n%v\n and it was expected: \n%v", result, expected)
+	}
+}
+
+func TestListServicePages(t *testing.T) {
+	pages := []*coreV1.ServiceList{
+		{
+			ListMeta: metaV1.ListMeta{Continue: "page-2"},
+			Items: []coreV1.Service{
+				{ObjectMeta: metaV1.ObjectMeta{Name: "svc-a", Namespace: "test1"}},
+				{ObjectMeta: metaV1.ObjectMeta{Name: "svc-b", Namespace: "test1"}},
 			},
 		},
 		{
-			label: "Sucessfully delete an existing service in default namespace",
-			input: map[string]string{"name": "test-service", "namespace": ""},
-			object: &coreV1.Service{
-				ObjectMeta: metaV1.ObjectMeta{
-					Name:      "test-service",
-					Namespace: "default",
-				},
+			ListMeta: metaV1.ListMeta{Continue: "page-3"},
+			Items: []coreV1.Service{
+				{ObjectMeta: metaV1.ObjectMeta{Name: "svc-c", Namespace: "test1"}},
+			},
+		},
+		{
+			Items: []coreV1.Service{
+				{ObjectMeta: metaV1.ObjectMeta{Name: "svc-d", Namespace: "test1"}},
 			},
 		},
 	}
 
+	conf := config.GetConfiguration()
+	oldMax := conf.MaxListedResources
+	defer func() {
+		conf.MaxListedResources = oldMax
+	}()
+
+	testCases := []struct {
+		label         string
+		maxListed     int
+		expectedNames []string
+		expectedError string
+	}{
+		{
+			label:         "Collect all the pages",
+			expectedNames: []string{"svc-a", "svc-b", "svc-c", "svc-d"},
+		},
+		{
+			label:         "Stop at the cap",
+			maxListed:     3,
+			expectedError: "more than 3 services",
+		},
+	}
+
 	for _, testCase := range testCases {
-		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			err := servicePlugin{}.Delete(helm.KubernetesResource{
-				GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
-				Name: testCase.input["name"],
-			}, testCase.input["namespace"], client)
+			conf.MaxListedResources = testCase.maxListed
+			// The fake clientset doesn't paginate, serve a page per call
+			clientSet := fake.NewSimpleClientset()
+			calls := 0
+			clientSet.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				page := pages[calls]
+				calls++
+				return true, page, nil
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet}
+
+			result, err := servicePlugin{}.List(context.TODO(), schema.GroupVersionKind{}, "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List method returned an error (%s)", err)
+			}
+			var names []string
+			for _, res := range result {
+				names = append(names, res.Name)
+			}
+			if !reflect.DeepEqual(names, testCase.expectedNames) {
+				t.Fatalf("List method returned %v, expected %v", names, testCase.expectedNames)
+			}
+		})
+	}
+}
+
+func TestListDetailedService(t *testing.T) {
+	ports := []coreV1.ServicePort{
+		{Name: "http", Port: 80, Protocol: coreV1.ProtocolTCP},
+		{Name: "dns", Port: 53, Protocol: coreV1.ProtocolUDP},
+	}
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "test", Namespace: "test1"},
+		Spec: coreV1.ServiceSpec{
+			Type:      coreV1.ServiceTypeNodePort,
+			ClusterIP: "10.96.0.10",
+			Ports:     ports,
+		},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet}
+
+	result, err := servicePlugin{}.ListDetailed(context.TODO(), "test1", "", client)
+	if err != nil {
+		t.Fatalf("ListDetailed method returned an error (%s)", err)
+	}
+	expected := []plugin.ServiceInfo{
+		{
+			KubernetesResource: helm.KubernetesResource{
+				Name: "test",
+				GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
+			},
+			Type:      coreV1.ServiceTypeNodePort,
+			ClusterIP: "10.96.0.10",
+			Ports:     ports,
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("ListDetailed method returned: \n%v\n and it was expected: \n%v", result, expected)
+	}
+}
+
+func TestDeleteServicePropagationPolicy(t *testing.T) {
+	oldPolicy := config.GetConfiguration().DeletePropagationPolicy
+	defer func() {
+		config.GetConfiguration().DeletePropagationPolicy = oldPolicy
+	}()
+
+	testCases := []struct {
+		label         string
+		policy        string
+		expected      metaV1.DeletionPropagation
+		expectedError string
+	}{
+		{
+			label:    "Default to background propagation",
+			expected: metaV1.DeletePropagationBackground,
+		},
+		{
+			label:    "Delete dependents first with foreground propagation",
+			policy:   "Foreground",
+			expected: metaV1.DeletePropagationForeground,
+		},
+		{
+			label:         "Reject an invalid policy",
+			policy:        "Cascade",
+			expectedError: "Invalid delete propagation policy \"Cascade\"",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			config.GetConfiguration().DeletePropagationPolicy = testCase.policy
+			// The fake clientset drops the delete options, read them from the request
+			var propagation *metaV1.DeletionPropagation
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				opts := metaV1.DeleteOptions{}
+				if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+					t.Errorf("Unable to decode the delete options (%s)", err)
+				}
+				propagation = opts.PropagationPolicy
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"kind": "Status", "apiVersion": "v1", "status": "Success"}`))
+			}))
+			defer server.Close()
+
+			clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatalf("Unable to create client (%s)", err)
+			}
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			err = servicePlugin{}.Delete(context.TODO(), helm.KubernetesResource{Name: "test-service"}, "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				if propagation != nil {
+					t.Fatalf("Expected the service not to be deleted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Delete method returned an error (%s)", err)
+			}
+			if propagation == nil || *propagation != testCase.expected {
+				t.Fatalf("Expected %s propagation, got %v", testCase.expected, propagation)
+			}
+		})
+	}
+}
+
+func TestDeleteService(t *testing.T) {
+	testCases := []struct {
+		label  string
+		input  map[string]string
+		object *coreV1.Service
+	}{
+		{
+			label: "Sucessfully to delete an existing service",
+			input: map[string]string{"name": "test-service", "namespace": "test-namespace"},
+			object: &coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "test-namespace",
+				},
+			},
+		},
+		{
+			label: "Sucessfully delete an existing service in default namespace",
+			input: map[string]string{"name": "test-service", "namespace": ""},
+			object: &coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "default",
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		client := TestKubernetesConnector{testCase.object}
+		t.Run(testCase.label, func(t *testing.T) {
+			err := servicePlugin{}.Delete(context.TODO(), helm.KubernetesResource{
+				GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
+				Name: testCase.input["name"],
+			}, testCase.input["namespace"], client)
 			if err != nil {
 				t.Fatalf("Delete method returned an error (%s)", err)
 			}
@@ -274,7 +1171,7 @@ func TestGetService(t *testing.T) {
 	for _, testCase := range testCases {
 		client := TestKubernetesConnector{testCase.object}
 		t.Run(testCase.label, func(t *testing.T) {
-			result, err := servicePlugin{}.Get(helm.KubernetesResource{
+			result, err := servicePlugin{}.Get(context.TODO(), helm.KubernetesResource{
 				GVK:  schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
 				Name: testCase.input["name"],
 			}, testCase.input["namespace"], client)
@@ -300,3 +1197,2061 @@ func TestGetService(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateServicePromotesAnnotations(t *testing.T) {
+	conf := config.GetConfiguration()
+	oldMapping := conf.AnnotationsToLabels
+	defer func() {
+		conf.AnnotationsToLabels = oldMapping
+	}()
+	conf.AnnotationsToLabels = map[string]string{
+		"policy.oran.io/tier": "tier",
+	}
+
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: annotated-service
+  annotations:
+    policy.oran.io/tier: gold
+spec:
+  ports:
+  - port: 80
+`)
+
+	client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+	_, err := servicePlugin{}.Create(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+
+	service, err := client.GetStandardClient().CoreV1().Services("test1").Get(context.TODO(), "annotated-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get created service (%s)", err)
+	}
+	if service.Labels["tier"] != "gold" {
+		t.Fatalf("Expected label tier=gold, got labels %v", service.Labels)
+	}
+	if service.Labels[conf.KubernetesLabelName] != "inst1" {
+		t.Fatalf("Expected instance label to be kept, got labels %v", service.Labels)
+	}
+}
+
+func TestUpdateServiceConflictPolicy(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ipFamilies:
+  - IPv6
+  ports:
+  - port: 8080
+`)
+
+	testCases := []struct {
+		policy        string
+		expectedError string
+		expectedPort  int32
+		expectedIPFam coreV1.IPFamily
+	}{
+		{
+			policy:        "Fail",
+			expectedError: "field is immutable",
+			expectedPort:  80,
+			expectedIPFam: coreV1.IPv4Protocol,
+		},
+		{
+			policy:        "SkipImmutable",
+			expectedPort:  8080,
+			expectedIPFam: coreV1.IPv4Protocol,
+		},
+		{
+			policy:        "Recreate",
+			expectedPort:  8080,
+			expectedIPFam: coreV1.IPv6Protocol,
+		},
+	}
+
+	conf := config.GetConfiguration()
+	oldPolicy := conf.UpdateConflictPolicy
+	defer func() {
+		conf.UpdateConflictPolicy = oldPolicy
+	}()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.policy, func(t *testing.T) {
+			conf.UpdateConflictPolicy = testCase.policy
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec: coreV1.ServiceSpec{
+					IPFamilies: []coreV1.IPFamily{coreV1.IPv4Protocol},
+					Ports:      []coreV1.ServicePort{{Port: 80}},
+				},
+			})
+			// Reject changes of the primary IP family the way the apiserver does
+			clientSet.PrependReactor("update", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				desired := action.(k8stesting.UpdateAction).GetObject().(*coreV1.Service)
+				live, err := clientSet.Tracker().Get(coreV1.SchemeGroupVersion.WithResource("services"), desired.Namespace, desired.Name)
+				if err != nil {
+					return true, nil, err
+				}
+				liveFamilies := live.(*coreV1.Service).Spec.IPFamilies
+				if len(desired.Spec.IPFamilies) > 0 && len(liveFamilies) > 0 && desired.Spec.IPFamilies[0] != liveFamilies[0] {
+					return true, nil, k8serrors.NewInvalid(schema.GroupKind{Kind: "Service"}, desired.Name, field.ErrorList{
+						field.Invalid(field.NewPath("spec", "ipFamilies").Index(0), desired.Spec.IPFamilies[0], "field is immutable"),
+					})
+				}
+				return false, nil, nil
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Update method returned %v, expected error containing '%s'", err, testCase.expectedError)
+				}
+			} else if err != nil {
+				t.Fatalf("Update method returned an error (%s)", err)
+			}
+
+			service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get updated service (%s)", err)
+			}
+			if service.Spec.Ports[0].Port != testCase.expectedPort {
+				t.Fatalf("Expected port %d, got %d", testCase.expectedPort, service.Spec.Ports[0].Port)
+			}
+			if service.Spec.IPFamilies[0] != testCase.expectedIPFam {
+				t.Fatalf("Expected IP family %s, got %s", testCase.expectedIPFam, service.Spec.IPFamilies[0])
+			}
+		})
+	}
+}
+
+func TestUpdateServiceHeadlessTransition(t *testing.T) {
+	newManifest := func(clusterIP string) string {
+		return plugintest.WriteManifest(t, fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  clusterIP: "%s"
+  ports:
+  - port: 80
+`, clusterIP))
+	}
+
+	testCases := []struct {
+		label             string
+		policy            string
+		liveClusterIP     string
+		manifest          string
+		expectedError     bool
+		expectedClusterIP string
+	}{
+		{
+			label:             "Fail to become headless",
+			policy:            "Fail",
+			liveClusterIP:     "10.0.0.10",
+			manifest:          newManifest("None"),
+			expectedError:     true,
+			expectedClusterIP: "10.0.0.10",
+		},
+		{
+			label:             "Fail to leave headless",
+			policy:            "Fail",
+			liveClusterIP:     "None",
+			manifest:          newManifest(""),
+			expectedError:     true,
+			expectedClusterIP: "None",
+		},
+		{
+			label:             "Recreate to become headless",
+			policy:            "Recreate",
+			liveClusterIP:     "10.0.0.10",
+			manifest:          newManifest("None"),
+			expectedClusterIP: "None",
+		},
+	}
+
+	conf := config.GetConfiguration()
+	oldPolicy := conf.UpdateConflictPolicy
+	defer func() {
+		conf.UpdateConflictPolicy = oldPolicy
+	}()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			conf.UpdateConflictPolicy = testCase.policy
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec: coreV1.ServiceSpec{
+					ClusterIP: testCase.liveClusterIP,
+					Ports:     []coreV1.ServicePort{{Port: 80}},
+				},
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Update(context.TODO(), testCase.manifest, "test1", client)
+			if testCase.expectedError {
+				var transition *plugin.ClusterIPTransitionError
+				if !errors.As(err, &transition) || !errors.Is(err, plugin.ErrImmutableField) {
+					t.Fatalf("Update method returned %v, expected a clusterIP transition error", err)
+				}
+				if transition.From != testCase.liveClusterIP || !strings.Contains(err.Error(), "delete and recreate") {
+					t.Fatalf("Unexpected clusterIP transition error %+v (%s)", transition, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Update method returned an error (%s)", err)
+			}
+
+			service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get updated service (%s)", err)
+			}
+			if service.Spec.ClusterIP != testCase.expectedClusterIP {
+				t.Fatalf("Expected clusterIP %s, got %s", testCase.expectedClusterIP, service.Spec.ClusterIP)
+			}
+		})
+	}
+}
+
+func TestUpdateServiceRetriesOnConflict(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - port: 8080
+`)
+
+	conf := config.GetConfiguration()
+	oldRetries := conf.UpdateRetries
+	defer func() {
+		conf.UpdateRetries = oldRetries
+	}()
+
+	testCases := []struct {
+		label           string
+		retries         int
+		expectedUpdates int
+		expectedError   string
+	}{
+		{
+			label:           "Retry after a conflict",
+			retries:         2,
+			expectedUpdates: 2,
+		},
+		{
+			label:           "Give up when retries are disabled",
+			retries:         0,
+			expectedUpdates: 1,
+			expectedError:   "the object has been modified",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			conf.UpdateRetries = testCase.retries
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1", ResourceVersion: "1"},
+				Spec:       coreV1.ServiceSpec{Ports: []coreV1.ServicePort{{Port: 80}}},
+			})
+			// A controller updating the service between the read and the
+			// first update of the plugin
+			updates := 0
+			clientSet.PrependReactor("update", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				updates++
+				if updates == 1 {
+					return true, nil, k8serrors.NewConflict(coreV1.Resource("services"), "mock-service",
+						fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+				}
+				return false, nil, nil
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+			if updates != testCase.expectedUpdates {
+				t.Fatalf("Expected %d updates, got %d", testCase.expectedUpdates, updates)
+			}
+			if testCase.expectedError != "" {
+				if !k8serrors.IsConflict(pkgerrors.Cause(err)) || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected a conflict error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update method returned an error (%s)", err)
+			}
+			service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get service (%s)", err)
+			}
+			if service.Spec.Ports[0].Port != 8080 {
+				t.Fatalf("Expected port 8080 after the retry, got %d", service.Spec.Ports[0].Port)
+			}
+		})
+	}
+}
+
+func TestUpdateServiceKeepsAnnotations(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+  annotations:
+    example.com/owner: team-b
+    example.com/tier: frontend
+spec:
+  ports:
+  - port: 80
+`)
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      "mock-service",
+			Namespace: "test1",
+			Annotations: map[string]string{
+				"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+				"example.com/owner": "team-a",
+			},
+		},
+		Spec: coreV1.ServiceSpec{Ports: []coreV1.ServicePort{{Port: 80}}},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get service (%s)", err)
+	}
+	expected := map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+		"example.com/owner": "team-b",
+		"example.com/tier":  "frontend",
+	}
+	if !reflect.DeepEqual(service.Annotations, expected) {
+		t.Fatalf("Expected annotations %v, got %v", expected, service.Annotations)
+	}
+}
+
+func TestUpdateServiceKeepsNodePorts(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  type: NodePort
+  ports:
+  - name: http
+    port: 80
+  - name: https
+    port: 443
+    nodePort: 30443
+  - name: metrics
+    port: 9090
+`)
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+		Spec: coreV1.ServiceSpec{
+			Type: coreV1.ServiceTypeNodePort,
+			Ports: []coreV1.ServicePort{
+				{Name: "http", Port: 80, NodePort: 30080},
+				{Name: "https", Port: 443, NodePort: 30444},
+			},
+		},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get service (%s)", err)
+	}
+	expected := map[string]int32{
+		// kept from the live service
+		"http": 30080,
+		// set explicitly in the manifest
+		"https": 30443,
+		// new port, left to the apiserver
+		"metrics": 0,
+	}
+	for _, port := range service.Spec.Ports {
+		if port.NodePort != expected[port.Name] {
+			t.Fatalf("Expected node port %d for port %s, got %d", expected[port.Name], port.Name, port.NodePort)
+		}
+	}
+}
+
+func TestUpdateServiceKeepsTrafficSettings(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  type: LoadBalancer
+  ports:
+  - name: http
+    port: 80
+`)
+	timeout := int32(600)
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+		Spec: coreV1.ServiceSpec{
+			Type:                  coreV1.ServiceTypeLoadBalancer,
+			Ports:                 []coreV1.ServicePort{{Name: "http", Port: 80, NodePort: 30080}},
+			ExternalTrafficPolicy: coreV1.ServiceExternalTrafficPolicyTypeLocal,
+			HealthCheckNodePort:   32000,
+			SessionAffinity:       coreV1.ServiceAffinityClientIP,
+			SessionAffinityConfig: &coreV1.SessionAffinityConfig{
+				ClientIP: &coreV1.ClientIPConfig{TimeoutSeconds: &timeout},
+			},
+		},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get service (%s)", err)
+	}
+	if service.Spec.ExternalTrafficPolicy != coreV1.ServiceExternalTrafficPolicyTypeLocal {
+		t.Fatalf("Expected externalTrafficPolicy Local, got %q", service.Spec.ExternalTrafficPolicy)
+	}
+	if service.Spec.HealthCheckNodePort != 32000 {
+		t.Fatalf("Expected healthCheckNodePort 32000, got %d", service.Spec.HealthCheckNodePort)
+	}
+	if service.Spec.SessionAffinity != coreV1.ServiceAffinityClientIP {
+		t.Fatalf("Expected sessionAffinity ClientIP, got %q", service.Spec.SessionAffinity)
+	}
+	affinity := service.Spec.SessionAffinityConfig
+	if affinity == nil || affinity.ClientIP == nil || *affinity.ClientIP.TimeoutSeconds != timeout {
+		t.Fatalf("Expected sessionAffinityConfig to be kept, got %v", affinity)
+	}
+}
+
+func TestUpdateServiceKeepsIPFamily(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - name: http
+    port: 80
+`)
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+		Spec: coreV1.ServiceSpec{
+			Ports:      []coreV1.ServicePort{{Name: "http", Port: 80}},
+			ClusterIP:  "fd00::10",
+			ClusterIPs: []string{"fd00::10"},
+			IPFamilies: []coreV1.IPFamily{coreV1.IPv6Protocol},
+		},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get service (%s)", err)
+	}
+	if service.Spec.ClusterIP != "fd00::10" {
+		t.Fatalf("Expected clusterIP fd00::10, got %q", service.Spec.ClusterIP)
+	}
+	if len(service.Spec.IPFamilies) != 1 || service.Spec.IPFamilies[0] != coreV1.IPv6Protocol {
+		t.Fatalf("Expected ipFamilies [IPv6], got %v", service.Spec.IPFamilies)
+	}
+}
+
+func TestUpdateServiceKeepsDualStackClusterIPs(t *testing.T) {
+	requireDualStack := coreV1.IPFamilyPolicyRequireDualStack
+	testCases := []struct {
+		label              string
+		manifest           string
+		expectedClusterIPs []string
+		expectedFamilies   []coreV1.IPFamily
+		expectedPolicy     coreV1.IPFamilyPolicyType
+	}{
+		{
+			label: "Keep both cluster IPs",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - port: 80
+`,
+			expectedClusterIPs: []string{"10.96.0.10", "fd00::10"},
+			expectedFamilies:   []coreV1.IPFamily{coreV1.IPv4Protocol, coreV1.IPv6Protocol},
+			expectedPolicy:     coreV1.IPFamilyPolicyRequireDualStack,
+		},
+		{
+			label: "Drop the secondary cluster IP when switched to single-stack",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ipFamilyPolicy: SingleStack
+  ports:
+  - port: 80
+`,
+			expectedClusterIPs: []string{"10.96.0.10"},
+			expectedFamilies:   []coreV1.IPFamily{coreV1.IPv4Protocol},
+			expectedPolicy:     coreV1.IPFamilyPolicySingleStack,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec: coreV1.ServiceSpec{
+					Ports:          []coreV1.ServicePort{{Port: 80}},
+					ClusterIP:      "10.96.0.10",
+					ClusterIPs:     []string{"10.96.0.10", "fd00::10"},
+					IPFamilies:     []coreV1.IPFamily{coreV1.IPv4Protocol, coreV1.IPv6Protocol},
+					IPFamilyPolicy: &requireDualStack,
+				},
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if err != nil {
+				t.Fatalf("Update method returned an error (%s)", err)
+			}
+			service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get service (%s)", err)
+			}
+			if !reflect.DeepEqual(service.Spec.ClusterIPs, testCase.expectedClusterIPs) {
+				t.Fatalf("Expected clusterIPs %v, got %v", testCase.expectedClusterIPs, service.Spec.ClusterIPs)
+			}
+			if !reflect.DeepEqual(service.Spec.IPFamilies, testCase.expectedFamilies) {
+				t.Fatalf("Expected ipFamilies %v, got %v", testCase.expectedFamilies, service.Spec.IPFamilies)
+			}
+			if service.Spec.IPFamilyPolicy == nil || *service.Spec.IPFamilyPolicy != testCase.expectedPolicy {
+				t.Fatalf("Expected ipFamilyPolicy %s, got %v", testCase.expectedPolicy, service.Spec.IPFamilyPolicy)
+			}
+		})
+	}
+}
+
+func TestUpdateExternalNameService(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  type: ExternalName
+  externalName: db.new.example.com
+`)
+	testCases := []struct {
+		label    string
+		existing coreV1.ServiceSpec
+	}{
+		{
+			label: "Change the external name",
+			existing: coreV1.ServiceSpec{
+				Type:         coreV1.ServiceTypeExternalName,
+				ExternalName: "db.old.example.com",
+			},
+		},
+		{
+			label: "Change a ClusterIP service to ExternalName",
+			existing: coreV1.ServiceSpec{
+				Type:                  coreV1.ServiceTypeClusterIP,
+				ClusterIP:             "10.96.0.10",
+				IPFamilies:            []coreV1.IPFamily{coreV1.IPv4Protocol},
+				SessionAffinity:       coreV1.ServiceAffinityNone,
+				ExternalTrafficPolicy: coreV1.ServiceExternalTrafficPolicyTypeCluster,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec:       testCase.existing,
+			})
+			// Validate the updated ExternalName service like the apiserver
+			clientSet.PrependReactor("update", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				service := action.(k8stesting.UpdateAction).GetObject().(*coreV1.Service)
+				if service.Spec.ClusterIP != "" || len(service.Spec.IPFamilies) > 0 || service.Spec.ExternalTrafficPolicy != "" {
+					return true, nil, k8serrors.NewInvalid(coreV1.SchemeGroupVersion.WithKind("Service").GroupKind(), service.Name,
+						field.ErrorList{field.Forbidden(field.NewPath("spec", "clusterIP"), "may not be set for ExternalName services")})
+				}
+				return false, nil, nil
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+			if err != nil {
+				t.Fatalf("Update method returned an error (%s)", err)
+			}
+			service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get service (%s)", err)
+			}
+			if service.Spec.Type != coreV1.ServiceTypeExternalName || service.Spec.ExternalName != "db.new.example.com" {
+				t.Fatalf("Expected ExternalName db.new.example.com, got %s %q", service.Spec.Type, service.Spec.ExternalName)
+			}
+		})
+	}
+}
+
+func TestServiceErrorKinds(t *testing.T) {
+	serviceManifest := `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+	missing := helm.KubernetesResource{GVK: coreV1.SchemeGroupVersion.WithKind("Service"), Name: "missing"}
+
+	testCases := []struct {
+		label    string
+		call     func() error
+		expected error
+	}{
+		{
+			label: "Create from an invalid manifest",
+			call: func() error {
+				_, err := servicePlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, "kind: [Service"), "test1", client)
+				return err
+			},
+			expected: plugin.ErrDecode,
+		},
+		{
+			label: "Update from a manifest of another kind",
+			call: func() error {
+				_, err := servicePlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, strings.Replace(serviceManifest, "Service", "ConfigMap", 1)),
+					"test1", client)
+				return err
+			},
+			expected: plugin.ErrWrongResourceType,
+		},
+		{
+			label: "Create an existing service",
+			call: func() error {
+				_, err := servicePlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, serviceManifest), "test1", client)
+				return err
+			},
+			expected: plugin.ErrAlreadyExists,
+		},
+		{
+			label: "Get a missing service",
+			call: func() error {
+				_, err := servicePlugin{}.Get(context.TODO(), missing, "test1", client)
+				return err
+			},
+			expected: plugin.ErrNotFound,
+		},
+		{
+			label: "Delete a missing service",
+			call: func() error {
+				return servicePlugin{}.Delete(context.TODO(), missing, "test1", client)
+			},
+			expected: plugin.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			err := testCase.call()
+			if !errors.Is(err, testCase.expected) {
+				t.Fatalf("Expected error matching %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+
+	// The Kubernetes error is still reachable for existing callers
+	_, err := servicePlugin{}.Get(context.TODO(), missing, "test1", client)
+	if !k8serrors.IsNotFound(pkgerrors.Cause(err)) {
+		t.Fatalf("Expected the cause to be a not found error, got %v", err)
+	}
+}
+
+func TestPatchService(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label             string
+		patchType         types.PatchType
+		patch             string
+		expectedSelector  map[string]string
+		expectedAnnotated string
+	}{
+		{
+			label:     "JSON merge patch",
+			patchType: types.MergePatchType,
+			// Also removes the instance label
+			patch: `{"metadata":{"labels":{"` + labelName + `":null},` +
+				`"annotations":{"example.com/tier":"backend"}}}`,
+			expectedSelector:  map[string]string{"app": "old"},
+			expectedAnnotated: "backend",
+		},
+		{
+			label:             "Strategic merge patch",
+			patchType:         types.StrategicMergePatchType,
+			patch:             `{"spec":{"selector":{"app":"new"}}}`,
+			expectedSelector:  map[string]string{"app": "new"},
+			expectedAnnotated: "frontend",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{
+					Name:        "mock-service",
+					Namespace:   "default",
+					Labels:      map[string]string{labelName: "inst1"},
+					Annotations: map[string]string{"example.com/tier": "frontend"},
+				},
+				Spec: coreV1.ServiceSpec{
+					Selector: map[string]string{"app": "old"},
+					Ports:    []coreV1.ServicePort{{Port: 80}},
+				},
+			})
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			name, err := servicePlugin{}.Patch(context.TODO(), helm.KubernetesResource{Name: "mock-service"},
+				[]byte(testCase.patch), testCase.patchType, "", client)
+			if err != nil {
+				t.Fatalf("Patch method returned an error (%s)", err)
+			}
+			if name != "mock-service" {
+				t.Fatalf("Patch method returned %q, expected %q", name, "mock-service")
+			}
+
+			service, err := clientSet.CoreV1().Services("default").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get service (%s)", err)
+			}
+			if !reflect.DeepEqual(service.Spec.Selector, testCase.expectedSelector) {
+				t.Fatalf("Expected selector %v, got %v", testCase.expectedSelector, service.Spec.Selector)
+			}
+			if service.Annotations["example.com/tier"] != testCase.expectedAnnotated {
+				t.Fatalf("Expected annotation %q, got %v", testCase.expectedAnnotated, service.Annotations)
+			}
+			if service.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected the instance label to be kept, got %v", service.Labels)
+			}
+		})
+	}
+}
+
+func TestServiceFieldManager(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - port: 80
+`)
+
+	// Minimal apiserver recording the field manager of each write and
+	// storing it in the managedFields of the object, as the real one does
+	stored := map[string]*coreV1.Service{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			service, ok := stored[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(k8serrors.NewNotFound(coreV1.Resource("services"), name).Status())
+				return
+			}
+			json.NewEncoder(w).Encode(service)
+		case http.MethodPost, http.MethodPut:
+			service := &coreV1.Service{}
+			if err := json.NewDecoder(r.Body).Decode(service); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			service.ManagedFields = []metaV1.ManagedFieldsEntry{{
+				Manager:   r.URL.Query().Get("fieldManager"),
+				Operation: metaV1.ManagedFieldsOperationUpdate,
+			}}
+			stored[service.Name] = service
+			json.NewEncoder(w).Encode(service)
+		}
+	}))
+	defer server.Close()
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unable to create client (%s)", err)
+	}
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	conf := config.GetConfiguration()
+	oldManager := conf.FieldManager
+	defer func() {
+		conf.FieldManager = oldManager
+	}()
+
+	conf.FieldManager = ""
+	if _, err := (servicePlugin{}).Create(context.TODO(), manifest, "test1", client); err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+	if manager := stored["mock-service"].ManagedFields[0].Manager; manager != "k8splugin-inst1" {
+		t.Fatalf("Expected default field manager k8splugin-inst1, got %s", manager)
+	}
+
+	conf.FieldManager = "oran-operator"
+	if _, err := (servicePlugin{}).Update(context.TODO(), manifest, "test1", client); err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	if manager := stored["mock-service"].ManagedFields[0].Manager; manager != "oran-operator" {
+		t.Fatalf("Expected configured field manager oran-operator, got %s", manager)
+	}
+}
+
+// dryRunConnector is a fakeKubernetesConnector asking for dry runs
+type dryRunConnector struct {
+	fakeKubernetesConnector
+}
+
+func (t dryRunConnector) DryRun() bool {
+	return true
+}
+
+// ownerConnector is a connector whose resources are owned by owner, in
+// ownerNamespace
+type ownerConnector struct {
+	fakeKubernetesConnector
+	owner          *metaV1.OwnerReference
+	ownerNamespace string
+}
+
+func (t ownerConnector) GetOwnerReference() *metaV1.OwnerReference {
+	return t.owner
+}
+
+func (t ownerConnector) GetOwnerNamespace() string {
+	return t.ownerNamespace
+}
+
+func TestCreateServiceExtraLabels(t *testing.T) {
+	conf := config.GetConfiguration()
+	oldExtraLabels := conf.ExtraLabels
+	defer func() {
+		conf.ExtraLabels = oldExtraLabels
+	}()
+	conf.ExtraLabels = map[string]string{
+		"tenant":             "tenant-a",
+		"example.com/domain": "ran",
+		// Never replaces the instance label
+		conf.KubernetesLabelName: "other",
+	}
+
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+  labels:
+    app: mock
+spec:
+  ports:
+  - port: 80
+`)
+	clientSet := fake.NewSimpleClientset()
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	if _, err := (servicePlugin{}).Create(context.TODO(), manifest, "test1", client); err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+
+	service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get created service (%s)", err)
+	}
+	expected := map[string]string{
+		"app":                    "mock",
+		"tenant":                 "tenant-a",
+		"example.com/domain":     "ran",
+		conf.KubernetesLabelName: "inst1",
+	}
+	if !reflect.DeepEqual(service.Labels, expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, service.Labels)
+	}
+}
+
+func TestCreateServiceOwnerReference(t *testing.T) {
+	controller := true
+	owner := metaV1.OwnerReference{
+		APIVersion: "k8splugin.io/v1alpha1",
+		Kind:       "ResourceBundleState",
+		Name:       "inst1-rbs",
+		UID:        types.UID("5c2a1f3e-7d4b-4e0f-9a61-2b8d3c4e5f60"),
+		Controller: &controller,
+	}
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+  ownerReferences:
+  - apiVersion: v1
+    kind: ConfigMap
+    name: settings
+    uid: 0b5f2d1c-8e3a-4c7b-9d2e-1f4a6b8c0d2e
+spec:
+  ports:
+  - port: 80
+`)
+
+	testCases := []struct {
+		label          string
+		owner          *metaV1.OwnerReference
+		ownerNamespace string
+		expected       []metaV1.OwnerReference
+	}{
+		{
+			label:          "Add the owner of the connector",
+			owner:          &owner,
+			ownerNamespace: "test1",
+			expected: []metaV1.OwnerReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", UID: "0b5f2d1c-8e3a-4c7b-9d2e-1f4a6b8c0d2e"},
+				owner,
+			},
+		},
+		{
+			label:          "Skip the owner of another namespace",
+			owner:          &owner,
+			ownerNamespace: "test2",
+			expected: []metaV1.OwnerReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", UID: "0b5f2d1c-8e3a-4c7b-9d2e-1f4a6b8c0d2e"},
+			},
+		},
+		{
+			label: "Keep the owners of the manifest without a connector owner",
+			expected: []metaV1.OwnerReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", UID: "0b5f2d1c-8e3a-4c7b-9d2e-1f4a6b8c0d2e"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := ownerConnector{
+				fakeKubernetesConnector: fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"},
+				owner:                   testCase.owner,
+				ownerNamespace:          testCase.ownerNamespace,
+			}
+			if _, err := (servicePlugin{}).Create(context.TODO(), manifest, "test1", client); err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+
+			service, err := client.GetStandardClient().CoreV1().Services("test1").
+				Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the service to be created (%s)", err)
+			}
+			if !reflect.DeepEqual(service.OwnerReferences, testCase.expected) {
+				t.Fatalf("Expected owner references %+v, got %+v", testCase.expected, service.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestServiceDryRun(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - port: 80
+`)
+
+	// Minimal apiserver that only stores the writes which are not dry runs,
+	// the fake clientset ignores the request options
+	stored := map[string]*coreV1.Service{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			service, ok := stored[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(k8serrors.NewNotFound(coreV1.Resource("services"), name).Status())
+				return
+			}
+			json.NewEncoder(w).Encode(service)
+		case http.MethodPost, http.MethodPut:
+			service := &coreV1.Service{}
+			if err := json.NewDecoder(r.Body).Decode(service); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.URL.Query().Get("dryRun") != metaV1.DryRunAll {
+				stored[service.Name] = service
+			}
+			json.NewEncoder(w).Encode(service)
+		}
+	}))
+	defer server.Close()
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unable to create client (%s)", err)
+	}
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+	dryRunClient := dryRunConnector{client}
+
+	name, err := servicePlugin{}.Create(context.TODO(), manifest, "test1", dryRunClient)
+	if err != nil || name != "mock-service" {
+		t.Fatalf("Create method returned %q, %v", name, err)
+	}
+	if _, ok := stored["mock-service"]; ok {
+		t.Fatal("Expected the service not to be stored by a dry run")
+	}
+
+	if _, err = (servicePlugin{}).Create(context.TODO(), manifest, "test1", client); err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+	if _, ok := stored["mock-service"]; !ok {
+		t.Fatal("Expected the service to be stored")
+	}
+
+	updated := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - port: 8080
+`)
+	name, err = servicePlugin{}.Update(context.TODO(), updated, "test1", dryRunClient)
+	if err != nil || name != "mock-service" {
+		t.Fatalf("Update method returned %q, %v", name, err)
+	}
+	if port := stored["mock-service"].Spec.Ports[0].Port; port != 80 {
+		t.Fatalf("Expected the dry run to leave port 80, got %d", port)
+	}
+}
+
+func TestServiceContextCancelled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unable to create client (%s)", err)
+	}
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = servicePlugin{}.Create(ctx, "../../mock_files/mock_yamls/service.yaml", "test1", client)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected Create to stop with the cancelled context, got %v", err)
+	}
+	_, err = servicePlugin{}.Get(ctx, helm.KubernetesResource{Name: "mock-service"}, "test1", client)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected Get to stop with the cancelled context, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("Expected no request to reach the apiserver, got %d", requests)
+	}
+}
+
+func TestOrphanedEndpointSlices(t *testing.T) {
+	newSlice := func(name, ownerUID string) *discoveryV1beta1.EndpointSlice {
+		return &discoveryV1beta1.EndpointSlice{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      name,
+				Namespace: "test1",
+				Labels: map[string]string{
+					discoveryV1beta1.LabelServiceName: "mock-service",
+					discoveryV1beta1.LabelManagedBy:   endpointSliceControllerName,
+				},
+				OwnerReferences: []metaV1.OwnerReference{
+					{APIVersion: "v1", Kind: "Service", Name: "mock-service", UID: types.UID(ownerUID)},
+				},
+			},
+		}
+	}
+
+	for _, cleanup := range []bool{false, true} {
+		t.Run(fmt.Sprintf("cleanup=%t", cleanup), func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(
+				&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1", UID: "uid-2"}},
+				newSlice("mock-service-current", "uid-2"),
+				newSlice("mock-service-stale", "uid-1"),
+			)
+			client := fakeKubernetesConnector{clientSet: clientSet}
+
+			orphans, err := orphanedEndpointSlices(context.TODO(), "mock-service", "test1", cleanup, client)
+			if err != nil {
+				t.Fatalf("orphanedEndpointSlices returned an error (%s)", err)
+			}
+			if !reflect.DeepEqual(orphans, []string{"mock-service-stale"}) {
+				t.Fatalf("orphanedEndpointSlices returned %v, expected [mock-service-stale]", orphans)
+			}
+
+			_, err = clientSet.DiscoveryV1beta1().EndpointSlices("test1").Get(context.TODO(), "mock-service-stale", metaV1.GetOptions{})
+			if cleanup && !k8serrors.IsNotFound(err) {
+				t.Fatalf("Expected orphaned EndpointSlice to be removed, got %v", err)
+			}
+			if !cleanup && err != nil {
+				t.Fatalf("Expected orphaned EndpointSlice to be kept, got %s", err)
+			}
+			_, err = clientSet.DiscoveryV1beta1().EndpointSlices("test1").Get(context.TODO(), "mock-service-current", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected current EndpointSlice to be kept, got %s", err)
+			}
+		})
+	}
+}
+
+func TestApplyServiceForceOwnership(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  selector:
+    app: new
+  ports:
+  - port: 80
+`)
+
+	conf := config.GetConfiguration()
+	oldSSA, oldForce := conf.ServerSideApply, conf.ForceApply
+	defer func() {
+		conf.ServerSideApply, conf.ForceApply = oldSSA, oldForce
+	}()
+	conf.ServerSideApply = true
+
+	for _, force := range []bool{false, true} {
+		t.Run(fmt.Sprintf("force=%t", force), func(t *testing.T) {
+			conf.ForceApply = force
+
+			// Minimal apiserver tracking the manager of spec.selector and
+			// rejecting applies changing it from another manager unless forced
+			stored := &coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec:       coreV1.ServiceSpec{Selector: map[string]string{"app": "old"}},
+			}
+			selectorOwner := "other-controller"
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method != http.MethodPatch || r.Header.Get("Content-Type") != string(types.ApplyPatchType) {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				applied := &coreV1.Service{}
+				if err := json.NewDecoder(r.Body).Decode(applied); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				manager := r.URL.Query().Get("fieldManager")
+				if manager != selectorOwner && !reflect.DeepEqual(applied.Spec.Selector, stored.Spec.Selector) &&
+					r.URL.Query().Get("force") != "true" {
+					status := k8serrors.NewConflict(coreV1.Resource("services"), applied.Name,
+						fmt.Errorf("conflict with %q: .spec.selector", selectorOwner)).Status()
+					w.WriteHeader(http.StatusConflict)
+					json.NewEncoder(w).Encode(&status)
+					return
+				}
+				selectorOwner = manager
+				stored.Spec = applied.Spec
+				stored.Labels = applied.Labels
+				json.NewEncoder(w).Encode(stored)
+			}))
+			defer server.Close()
+
+			clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatalf("Unable to create client (%s)", err)
+			}
+			client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+			_, err = servicePlugin{}.Update(context.TODO(), manifest, "test1", client)
+			if !force {
+				if !k8serrors.IsConflict(pkgerrors.Cause(err)) {
+					t.Fatalf("Expected a conflict error, got %v", err)
+				}
+				if stored.Spec.Selector["app"] != "old" || selectorOwner != "other-controller" {
+					t.Fatalf("Contested field changed without force: %v owned by %s", stored.Spec.Selector, selectorOwner)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update method returned an error (%s)", err)
+			}
+			if stored.Spec.Selector["app"] != "new" || selectorOwner != "k8splugin-inst1" {
+				t.Fatalf("Contested field not taken over: %v owned by %s", stored.Spec.Selector, selectorOwner)
+			}
+		})
+	}
+}
+
+func TestApplyServiceTwice(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+spec:
+  ports:
+  - port: 80
+`)
+
+	conf := config.GetConfiguration()
+	oldSSA, oldForce, oldManager := conf.ServerSideApply, conf.ForceApply, conf.FieldManager
+	defer func() {
+		conf.ServerSideApply, conf.ForceApply, conf.FieldManager = oldSSA, oldForce, oldManager
+	}()
+	conf.ServerSideApply, conf.ForceApply, conf.FieldManager = true, false, "oran-operator"
+
+	// Minimal apiserver accepting only applies and recording their manager
+	// in the managedFields of the object, as the real one does
+	var stored *coreV1.Service
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPatch || r.Header.Get("Content-Type") != string(types.ApplyPatchType) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		applied := &coreV1.Service{}
+		if err := json.NewDecoder(r.Body).Decode(applied); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if stored != nil && stored.ManagedFields[0].Manager != r.URL.Query().Get("fieldManager") {
+			status := k8serrors.NewConflict(coreV1.Resource("services"), applied.Name,
+				fmt.Errorf("conflict with %q", stored.ManagedFields[0].Manager)).Status()
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(&status)
+			return
+		}
+		applied.ManagedFields = []metaV1.ManagedFieldsEntry{{
+			Manager:   r.URL.Query().Get("fieldManager"),
+			Operation: metaV1.ManagedFieldsOperationApply,
+		}}
+		stored = applied
+		json.NewEncoder(w).Encode(stored)
+	}))
+	defer server.Close()
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unable to create client (%s)", err)
+	}
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	if _, err = (servicePlugin{}).Create(context.TODO(), manifest, "test1", client); err != nil {
+		t.Fatalf("Create method returned an error (%s)", err)
+	}
+	first := stored.ManagedFields
+	if _, err = (servicePlugin{}).Update(context.TODO(), manifest, "test1", client); err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	expected := []metaV1.ManagedFieldsEntry{{Manager: "oran-operator", Operation: metaV1.ManagedFieldsOperationApply}}
+	if !reflect.DeepEqual(first, expected) || !reflect.DeepEqual(stored.ManagedFields, expected) {
+		t.Fatalf("Expected the ownership to stay %v, got %v then %v", expected, first, stored.ManagedFields)
+	}
+}
+
+func TestWatchLoadBalancerProgress(t *testing.T) {
+	oldInterval := loadBalancerPollInterval
+	defer func() {
+		loadBalancerPollInterval = oldInterval
+	}()
+	loadBalancerPollInterval = time.Millisecond
+
+	newEvent := func(name, service, reason string) *coreV1.Event {
+		return &coreV1.Event{
+			ObjectMeta:     metaV1.ObjectMeta{Name: name, Namespace: "test1"},
+			InvolvedObject: coreV1.ObjectReference{Kind: "Service", Name: service, Namespace: "test1"},
+			Reason:         reason,
+			Message:        reason + " for " + service,
+			Count:          1,
+		}
+	}
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.Service{
+			ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+			Spec:       coreV1.ServiceSpec{Type: coreV1.ServiceTypeLoadBalancer},
+		},
+		newEvent("mock-service.1", "mock-service", "EnsuringLoadBalancer"),
+		newEvent("other-service.1", "other-service", "SyncLoadBalancerFailed"),
+	)
+
+	// Each reported step drives the provisioning to its next step
+	var reasons []string
+	progress := func(reason, message string) {
+		reasons = append(reasons, reason)
+		switch reason {
+		case "EnsuringLoadBalancer":
+			clientSet.CoreV1().Events("test1").Create(context.TODO(),
+				newEvent("mock-service.2", "mock-service", "EnsuredLoadBalancer"), metaV1.CreateOptions{})
+		case "EnsuredLoadBalancer":
+			service, _ := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			service.Status.LoadBalancer.Ingress = []coreV1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+			clientSet.CoreV1().Services("test1").UpdateStatus(context.TODO(), service, metaV1.UpdateOptions{})
+		}
+	}
+
+	res := helm.KubernetesResource{
+		GVK:  coreV1.SchemeGroupVersion.WithKind("Service"),
+		Name: "mock-service",
+	}
+	err := servicePlugin{}.WatchUntilReadyWithProgress(context.TODO(), 5*time.Second, "test1", res, clientSet, progress)
+	if err != nil {
+		t.Fatalf("WatchUntilReadyWithProgress method returned an error (%s)", err)
+	}
+
+	expected := []string{"EnsuringLoadBalancer", "EnsuredLoadBalancer"}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Fatalf("Progress reported %v, expected %v", reasons, expected)
+	}
+}
+
+func TestWatchServiceUntilEndpointsReady(t *testing.T) {
+	oldInterval := loadBalancerPollInterval
+	defer func() {
+		loadBalancerPollInterval = oldInterval
+	}()
+	loadBalancerPollInterval = 10 * time.Millisecond
+
+	conf := config.GetConfiguration()
+	oldMinimum := conf.ServiceReadyEndpoints
+	defer func() {
+		conf.ServiceReadyEndpoints = oldMinimum
+	}()
+
+	selector := map[string]string{"app": "db"}
+	newEndpoints := func(addresses ...string) *coreV1.Endpoints {
+		subset := coreV1.EndpointSubset{}
+		for _, ip := range addresses {
+			subset.Addresses = append(subset.Addresses, coreV1.EndpointAddress{IP: ip})
+		}
+		subset.NotReadyAddresses = []coreV1.EndpointAddress{{IP: "10.1.0.9"}}
+		return &coreV1.Endpoints{
+			ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+			Subsets:    []coreV1.EndpointSubset{subset},
+		}
+	}
+	testCases := []struct {
+		label         string
+		minimum       int
+		spec          coreV1.ServiceSpec
+		endpoints     []*coreV1.Endpoints
+		expectedError string
+	}{
+		{
+			label:     "Endpoints appear after a delay",
+			minimum:   1,
+			spec:      coreV1.ServiceSpec{Selector: selector},
+			endpoints: []*coreV1.Endpoints{nil, newEndpoints(), newEndpoints("10.1.0.1")},
+		},
+		{
+			label:     "Wait for the minimum endpoint count",
+			minimum:   2,
+			spec:      coreV1.ServiceSpec{Selector: selector},
+			endpoints: []*coreV1.Endpoints{newEndpoints("10.1.0.1"), newEndpoints("10.1.0.1", "10.1.0.2")},
+		},
+		{
+			label:         "Time out without ready endpoints",
+			minimum:       1,
+			spec:          coreV1.ServiceSpec{Selector: selector},
+			endpoints:     []*coreV1.Endpoints{newEndpoints()},
+			expectedError: "timed out",
+		},
+		{
+			label:   "Headless service is ready immediately",
+			minimum: 1,
+			spec:    coreV1.ServiceSpec{Selector: selector, ClusterIP: coreV1.ClusterIPNone},
+		},
+		{
+			label:   "ExternalName service is ready immediately",
+			minimum: 1,
+			spec:    coreV1.ServiceSpec{Type: coreV1.ServiceTypeExternalName, ExternalName: "db.example.com"},
+		},
+		{
+			label:   "Endpoints are not waited for when disabled",
+			minimum: 0,
+			spec:    coreV1.ServiceSpec{Selector: selector},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			conf.ServiceReadyEndpoints = testCase.minimum
+			clientSet := fake.NewSimpleClientset(&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec:       testCase.spec,
+			})
+			// Each read of the endpoints returns the next step, the last
+			// one is kept
+			reads := 0
+			clientSet.PrependReactor("get", "endpoints", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if len(testCase.endpoints) == 0 {
+					t.Fatal("Expected the endpoints not to be read")
+				}
+				step := testCase.endpoints[len(testCase.endpoints)-1]
+				if reads < len(testCase.endpoints) {
+					step = testCase.endpoints[reads]
+				}
+				reads++
+				if step == nil {
+					return true, nil, k8serrors.NewNotFound(coreV1.Resource("endpoints"), "mock-service")
+				}
+				return true, step, nil
+			})
+
+			res := helm.KubernetesResource{
+				GVK:  coreV1.SchemeGroupVersion.WithKind("Service"),
+				Name: "mock-service",
+			}
+			err := servicePlugin{}.WatchUntilReady(context.TODO(), 200*time.Millisecond, "test1", res, nil, nil, nil, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+			}
+			if reads < len(testCase.endpoints) {
+				t.Fatalf("Expected the endpoints to be read %d times, got %d", len(testCase.endpoints), reads)
+			}
+		})
+	}
+}
+
+func TestWatchServiceUntilEndpointSlicesReady(t *testing.T) {
+	oldInterval := loadBalancerPollInterval
+	defer func() {
+		loadBalancerPollInterval = oldInterval
+	}()
+	loadBalancerPollInterval = 10 * time.Millisecond
+
+	conf := config.GetConfiguration()
+	oldMinimum := conf.ServiceReadyEndpoints
+	defer func() {
+		conf.ServiceReadyEndpoints = oldMinimum
+	}()
+
+	notReady := false
+	newEndpoint := func(pod string, ready *bool, address string) discoveryV1beta1.Endpoint {
+		return discoveryV1beta1.Endpoint{
+			Addresses:  []string{address},
+			Conditions: discoveryV1beta1.EndpointConditions{Ready: ready},
+			TargetRef:  &coreV1.ObjectReference{Kind: "Pod", Name: pod, UID: types.UID(pod + "-uid")},
+		}
+	}
+	newSlice := func(name string, addressType discoveryV1beta1.AddressType, endpoints ...discoveryV1beta1.Endpoint) *discoveryV1beta1.EndpointSlice {
+		return &discoveryV1beta1.EndpointSlice{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      name,
+				Namespace: "test1",
+				Labels:    map[string]string{discoveryV1beta1.LabelServiceName: "mock-service"},
+			},
+			AddressType: addressType,
+			Endpoints:   endpoints,
+		}
+	}
+	// Three ready pods across both slices, db-1 is in both of them
+	slices := []runtime.Object{
+		newSlice("mock-service-ipv4", discoveryV1beta1.AddressTypeIPv4,
+			newEndpoint("db-0", nil, "10.1.0.1"),
+			newEndpoint("db-1", nil, "10.1.0.2"),
+			newEndpoint("db-2", &notReady, "10.1.0.3")),
+		newSlice("mock-service-ipv6", discoveryV1beta1.AddressTypeIPv6,
+			newEndpoint("db-1", nil, "fd00::2"),
+			newEndpoint("db-3", nil, "fd00::4")),
+	}
+
+	testCases := []struct {
+		label         string
+		minimum       int
+		expectedError string
+	}{
+		{
+			label:   "Slices together reach the minimum",
+			minimum: 3,
+		},
+		{
+			label:         "Time out below the minimum",
+			minimum:       4,
+			expectedError: "timed out",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			conf.ServiceReadyEndpoints = testCase.minimum
+			objects := append([]runtime.Object{&coreV1.Service{
+				ObjectMeta: metaV1.ObjectMeta{Name: "mock-service", Namespace: "test1"},
+				Spec:       coreV1.ServiceSpec{Selector: map[string]string{"app": "db"}},
+			}}, slices...)
+			clientSet := fake.NewSimpleClientset(objects...)
+			clientSet.PrependReactor("get", "endpoints", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				t.Fatal("Expected the endpoints not to be read when the service has EndpointSlices")
+				return true, nil, nil
+			})
+
+			res := helm.KubernetesResource{
+				GVK:  coreV1.SchemeGroupVersion.WithKind("Service"),
+				Name: "mock-service",
+			}
+			err := servicePlugin{}.WatchUntilReady(context.TODO(), 200*time.Millisecond, "test1", res, nil, nil, nil, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestWatchServiceUntilDeleted(t *testing.T) {
+	res := helm.KubernetesResource{
+		GVK:  coreV1.SchemeGroupVersion.WithKind("Service"),
+		Name: "mock-service",
+	}
+	newService := func() *coreV1.Service {
+		return &coreV1.Service{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:       "mock-service",
+				Namespace:  "test1",
+				Finalizers: []string{"service.kubernetes.io/load-balancer-cleanup"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		label         string
+		objects       []runtime.Object
+		deleted       bool
+		expectedError string
+	}{
+		{
+			label: "The service is already removed",
+		},
+		{
+			label:   "The service is removed once its finalizers ran",
+			objects: []runtime.Object{newService()},
+			deleted: true,
+		},
+		{
+			label:         "Time out while the service is being finalized",
+			objects:       []runtime.Object{newService()},
+			expectedError: "timed out",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(testCase.objects...)
+			watcher := watch.NewFake()
+			clientSet.PrependWatchReactor("services", k8stesting.DefaultWatchReactor(watcher, nil))
+			if testCase.deleted {
+				go watcher.Delete(newService())
+			}
+
+			err := servicePlugin{}.WatchUntilDeleted(context.TODO(), 100*time.Millisecond, "test1", res, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilDeleted method returned an error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestCreateServiceNamespaceConflict(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+  namespace: manifest-ns
+spec:
+  ports:
+  - port: 80
+`)
+
+	conf := config.GetConfiguration()
+	oldPolicy := conf.NamespaceConflictPolicy
+	defer func() {
+		conf.NamespaceConflictPolicy = oldPolicy
+	}()
+
+	testCases := []struct {
+		policy            string
+		namespace         string
+		expectedNamespace string
+		expectedError     string
+	}{
+		{
+			policy:        "Error",
+			namespace:     "arg-ns",
+			expectedError: "conflicting with namespace arg-ns",
+		},
+		{
+			policy:            "PreferManifest",
+			namespace:         "arg-ns",
+			expectedNamespace: "manifest-ns",
+		},
+		{
+			policy:            "PreferArgument",
+			namespace:         "arg-ns",
+			expectedNamespace: "arg-ns",
+		},
+		{
+			policy:            "Error",
+			namespace:         "",
+			expectedNamespace: "manifest-ns",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.policy+"/"+testCase.namespace, func(t *testing.T) {
+			conf.NamespaceConflictPolicy = testCase.policy
+			client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Create(context.TODO(), manifest, testCase.namespace, client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				list, _ := client.GetStandardClient().CoreV1().Services("").List(context.TODO(), metaV1.ListOptions{})
+				if len(list.Items) != 0 {
+					t.Fatalf("Expected no service to be created, got %d", len(list.Items))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			_, err = client.GetStandardClient().CoreV1().Services(testCase.expectedNamespace).
+				Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected service in namespace %s (%s)", testCase.expectedNamespace, err)
+			}
+		})
+	}
+}
+
+func TestCreateServiceFinalizerPolicy(t *testing.T) {
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+  finalizers:
+  - example.com/cleanup
+  - example.com/never-cleared
+spec:
+  ports:
+  - port: 80
+`)
+
+	conf := config.GetConfiguration()
+	oldPolicy, oldAllowed := conf.FinalizerPolicy, conf.AllowedFinalizers
+	defer func() {
+		conf.FinalizerPolicy, conf.AllowedFinalizers = oldPolicy, oldAllowed
+	}()
+
+	testCases := []struct {
+		policy             string
+		allowed            []string
+		expectedFinalizers []string
+	}{
+		{
+			policy:             "Allow",
+			expectedFinalizers: []string{"example.com/cleanup", "example.com/never-cleared"},
+		},
+		{
+			policy: "Strip",
+		},
+		{
+			policy:             "Allowlist",
+			allowed:            []string{"example.com/cleanup"},
+			expectedFinalizers: []string{"example.com/cleanup"},
+		},
+		{
+			policy: "Allowlist",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.policy, func(t *testing.T) {
+			conf.FinalizerPolicy, conf.AllowedFinalizers = testCase.policy, testCase.allowed
+			client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+
+			_, err := servicePlugin{}.Create(context.TODO(), manifest, "test1", client)
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			service, err := client.GetStandardClient().CoreV1().Services("test1").
+				Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected service to be created (%s)", err)
+			}
+			if !reflect.DeepEqual(service.Finalizers, testCase.expectedFinalizers) {
+				t.Fatalf("Expected finalizers %v, got %v", testCase.expectedFinalizers, service.Finalizers)
+			}
+		})
+	}
+}
+
+func TestReconcileService(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	manifest := plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+  labels:
+    app: mock
+spec:
+  selector:
+    app: mock
+  ports:
+  - name: http
+    port: 80
+`)
+	// The live service was edited to listen on another port, the other
+	// differences are fields set by the apiserver and a controller
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:            "mock-service",
+			Namespace:       "test1",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"app": "mock", labelName: "inst1"},
+			Annotations:     map[string]string{"controller": "annotation"},
+		},
+		Spec: coreV1.ServiceSpec{
+			Type:            coreV1.ServiceTypeClusterIP,
+			ClusterIP:       "10.0.0.10",
+			Selector:        map[string]string{"app": "mock"},
+			SessionAffinity: coreV1.ServiceAffinityNone,
+			Ports: []coreV1.ServicePort{
+				{Name: "http", Port: 8080, Protocol: coreV1.ProtocolTCP, TargetPort: intstr.FromInt(8080)},
+			},
+		},
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	changed, err := servicePlugin{}.Reconcile(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Reconcile returned an error (%s)", err)
+	}
+	if !changed {
+		t.Fatalf("Expected Reconcile to report the drifted port as a change")
+	}
+
+	service, err := clientSet.CoreV1().Services("test1").Get(context.TODO(), "mock-service", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get service returned an error (%s)", err)
+	}
+	expectedPorts := []coreV1.ServicePort{
+		{Name: "http", Port: 80, Protocol: coreV1.ProtocolTCP, TargetPort: intstr.FromInt(80)},
+	}
+	if !reflect.DeepEqual(service.Spec.Ports, expectedPorts) {
+		t.Fatalf("Expected the ports to be reconciled to %+v, got %+v", expectedPorts, service.Spec.Ports)
+	}
+	if service.Spec.ClusterIP != "10.0.0.10" || service.Annotations["controller"] != "annotation" {
+		t.Fatalf("Expected the fields set outside the manifest to be kept, got %+v", service)
+	}
+
+	// The reconciled service has no drift left
+	changed, err = servicePlugin{}.Reconcile(context.TODO(), manifest, "test1", client)
+	if err != nil {
+		t.Fatalf("Reconcile returned an error (%s)", err)
+	}
+	if changed {
+		t.Fatalf("Expected Reconcile to make no change to a service matching the manifest")
+	}
+}
+
+func TestCreateEachService(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	newManifest := func(name string) string {
+		return plugintest.WriteManifest(t, fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: %s
+spec:
+  ports:
+  - port: 80
+`, name))
+	}
+	manifests := []string{
+		newManifest("svc-a"),
+		plugintest.WriteManifest(t, "kind: [Service"),
+		newManifest("svc-c"),
+	}
+	client := fakeKubernetesConnector{clientSet: fake.NewSimpleClientset(), instanceID: "inst1"}
+
+	results, err := servicePlugin{}.CreateEach(context.TODO(), manifests, "test1", client, false)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 service manifests not created") {
+		t.Fatalf("Expected an error reporting the failed manifest, got %v", err)
+	}
+
+	expected := []plugin.CreateResult{
+		{Manifest: manifests[0], Name: "svc-a", Created: true},
+		{Manifest: manifests[1]},
+		{Manifest: manifests[2], Name: "svc-c", Created: true},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+	if results[1].Error == "" {
+		t.Fatalf("Expected the invalid manifest to report its error, got %+v", results[1])
+	}
+	results[1].Error = ""
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("CreateEach returned %+v, expected %+v", results, expected)
+	}
+
+	for _, name := range []string{"svc-a", "svc-c"} {
+		service, err := client.GetStandardClient().CoreV1().Services("test1").Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected service %s to be created (%s)", name, err)
+		}
+		if service.Labels[labelName] != "inst1" {
+			t.Fatalf("Expected service %s to be labeled with the instance ID, got %v", name, service.Labels)
+		}
+	}
+}
+
+func TestCreateEachServiceTransactional(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	manifests := []string{
+		plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-a
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc-b
+spec:
+  ports:
+  - port: 80
+`),
+		plugintest.WriteManifest(t, "kind: [Service"),
+		plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-c
+spec:
+  ports:
+  - port: 80
+`),
+	}
+	clientSet := fake.NewSimpleClientset(&coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{Name: "svc-other", Namespace: "test1", Labels: map[string]string{labelName: "inst2"}},
+	})
+	var deleted []string
+	clientSet.PrependReactor("delete", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleted = append(deleted, action.(k8stesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	results, err := servicePlugin{}.CreateEach(context.TODO(), manifests, "test1", client, true)
+	if err == nil || results[1].Error == "" || err.Error() != results[1].Error {
+		t.Fatalf("Expected the error of the invalid manifest, got %v", err)
+	}
+
+	expected := []plugin.CreateResult{
+		{Manifest: manifests[0], Name: "svc-a,svc-b", RolledBack: true},
+		{Manifest: manifests[1], Error: results[1].Error},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("CreateEach returned %+v, expected %+v", results, expected)
+	}
+	if !reflect.DeepEqual(deleted, []string{"svc-b", "svc-a"}) {
+		t.Fatalf("Expected the services to be deleted in reverse order, got %v", deleted)
+	}
+
+	list, err := clientSet.CoreV1().Services("test1").List(context.TODO(), metaV1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Listing services returned an error (%s)", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "svc-other" {
+		t.Fatalf("Expected only the service of the other instance to be left, got %+v", list.Items)
+	}
+}
+
+func TestDeleteEachService(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	newService := func(name string, labels map[string]string) *coreV1.Service {
+		return &coreV1.Service{
+			ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: "test1", Labels: labels},
+		}
+	}
+	clientSet := fake.NewSimpleClientset(
+		newService("svc-a", map[string]string{labelName: "inst1"}),
+		newService("svc-b", map[string]string{labelName: "inst1"}),
+		newService("svc-c", map[string]string{labelName: "inst1"}),
+		newService("svc-other", map[string]string{labelName: "inst2"}),
+	)
+	// A webhook rejecting the deletion of one of the services
+	clientSet.PrependReactor("delete", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.DeleteAction).GetName() == "svc-b" {
+			return true, nil, k8serrors.NewForbidden(coreV1.Resource("services"), "svc-b",
+				fmt.Errorf("admission webhook denied the request"))
+		}
+		return false, nil, nil
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet}
+
+	results, err := servicePlugin{}.DeleteEach(context.TODO(), "test1", labelName+"=inst1", client)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 services not deleted") {
+		t.Fatalf("Expected an error reporting the failed deletion, got %v", err)
+	}
+
+	deleted := map[string]bool{}
+	for _, result := range results {
+		deleted[result.Resource.Name] = result.Deleted
+		if result.Deleted == (result.Error != "") {
+			t.Fatalf("Inconsistent result %+v", result)
+		}
+	}
+	expected := map[string]bool{"svc-a": true, "svc-b": false, "svc-c": true}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Fatalf("DeleteEach returned %v, expected %v", deleted, expected)
+	}
+
+	list, err := clientSet.CoreV1().Services("test1").List(context.TODO(), metaV1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unable to list services (%s)", err)
+	}
+	var remaining []string
+	for _, service := range list.Items {
+		remaining = append(remaining, service.Name)
+	}
+	sort.Strings(remaining)
+	if !reflect.DeepEqual(remaining, []string{"svc-b", "svc-other"}) {
+		t.Fatalf("Expected svc-b and svc-other to remain, got %v", remaining)
+	}
+}
+
+func TestDeleteEachServiceAlreadyDeleted(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-a", Namespace: "test1",
+			Labels: map[string]string{labelName: "inst1"}}},
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-b", Namespace: "test1",
+			Labels: map[string]string{labelName: "inst1"}}},
+	)
+	// svc-a and svc-c are deleted by someone else before their deletion
+	clientSet.PrependReactor("delete", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if name := action.(k8stesting.DeleteAction).GetName(); name == "svc-a" || name == "svc-c" {
+			return true, nil, k8serrors.NewNotFound(coreV1.Resource("services"), name)
+		}
+		return false, nil, nil
+	})
+	client := fakeKubernetesConnector{clientSet: clientSet, instanceID: "inst1"}
+
+	results, err := servicePlugin{}.DeleteEach(context.TODO(), "test1", labelName+"=inst1", client)
+	if err != nil {
+		t.Fatalf("DeleteEach method returned an error (%s)", err)
+	}
+	expected := []plugin.DeleteResult{
+		{Resource: helm.KubernetesResource{GVK: serviceGVK, Name: "svc-a"}, Deleted: true},
+		{Resource: helm.KubernetesResource{GVK: serviceGVK, Name: "svc-b"}, Deleted: true},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("DeleteEach returned %+v, expected %+v", results, expected)
+	}
+
+	// The rollback of a batch ignores the services that are already gone too
+	manifests := []string{
+		plugintest.WriteManifest(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-c
+spec:
+  ports:
+  - port: 80
+`),
+		plugintest.WriteManifest(t, "kind: [Service"),
+	}
+	created, err := servicePlugin{}.CreateEach(context.TODO(), manifests, "test1", client, true)
+	if err == nil || err.Error() != created[1].Error {
+		t.Fatalf("Expected only the error of the invalid manifest, got %v", err)
+	}
+	if !created[0].RolledBack {
+		t.Fatalf("Expected the service already gone to be rolled back, got %+v", created[0])
+	}
+}
+
+func TestDeleteAllServices(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-a", Namespace: "test1",
+			Labels: map[string]string{labelName: "inst1"}}},
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-b", Namespace: "test1",
+			Labels: map[string]string{labelName: "inst1", "app": "b"}}},
+		&coreV1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "svc-other", Namespace: "test1",
+			Labels: map[string]string{labelName: "inst2"}}},
+	)
+	client := fakeKubernetesConnector{clientSet: clientSet}
+
+	deleted, err := servicePlugin{}.DeleteAll(context.TODO(), "test1", "inst1", client)
+	if err != nil {
+		t.Fatalf("DeleteAll method returned an error (%s)", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("DeleteAll method deleted %d services, expected 2", deleted)
+	}
+
+	list, err := clientSet.CoreV1().Services("test1").List(context.TODO(), metaV1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unable to list services (%s)", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "svc-other" {
+		t.Fatalf("Expected only svc-other to remain, got %v", list.Items)
+	}
+
+	if _, err = (servicePlugin{}).DeleteAll(context.TODO(), "test1", "", client); err == nil {
+		t.Fatal("Expected DeleteAll to require an instance ID")
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if serviceAccountPlugin implements the correct interface
+var _ plugin.Reference = serviceAccountPlugin{}
+
+// readyPollInterval is how often a ServiceAccount is read while waiting for
+// its token secret
+var readyPollInterval = 2 * time.Second
+
+// tokenRequestVersion is the first Kubernetes version that no longer
+// generates token secrets for ServiceAccounts
+var tokenRequestVersion = version.MustParseGeneric("v1.24.0")
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable serviceAccountPlugin
+
+// serviceAccountPlugin manages ServiceAccounts
+type serviceAccountPlugin struct {
+}
+
+// serviceAccounts implements the methods of serviceAccountPlugin shared with the
+// plugins of the other kinds
+var serviceAccounts = plugin.TypedResource{
+	GVK: coreV1.SchemeGroupVersion.WithKind("ServiceAccount"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return serviceAccountClient{clientSet.CoreV1().ServiceAccounts(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeServiceAccount(yamlFilePath)
+	},
+	KeepExisting: func(obj, existing plugin.Object) {
+		keepTokenSecrets(obj.(*coreV1.ServiceAccount), existing.(*coreV1.ServiceAccount))
+	},
+}
+
+// WatchUntilReady waits until the ServiceAccount exists. On clusters older
+// than tokenRequestVersion it also waits for the token secret generated by
+// the token controller, newer clusters use the TokenRequest API instead.
+func (g serviceAccountPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	legacyTokens, err := usesTokenSecrets(clientSet)
+	if err != nil {
+		return err
+	}
+
+	return serviceAccounts.WaitUntil(ctx, timeout, readyPollInterval, res.Name, ns, clientSet, func(obj plugin.Object) (bool, error) {
+		if !legacyTokens {
+			return true, nil
+		}
+		return hasTokenSecret(ctx, obj.(*coreV1.ServiceAccount), clientSet)
+	})
+}
+
+// usesTokenSecrets reports whether the cluster generates a token secret for
+// each ServiceAccount
+func usesTokenSecrets(clientSet kubernetes.Interface) (bool, error) {
+	info, err := clientSet.Discovery().ServerVersion()
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Get server version error")
+	}
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, pkgerrors.Wrap(err, "Parse server version error")
+	}
+	return serverVersion.LessThan(tokenRequestVersion), nil
+}
+
+// hasTokenSecret reports whether one of the secrets referenced by the
+// ServiceAccount is its token secret
+func hasTokenSecret(ctx context.Context, serviceAccount *coreV1.ServiceAccount, clientSet kubernetes.Interface) (bool, error) {
+	for _, ref := range serviceAccount.Secrets {
+		secret, err := clientSet.CoreV1().Secrets(serviceAccount.Namespace).Get(ctx, ref.Name, metaV1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, pkgerrors.Wrap(err, "Get token Secret error")
+		}
+		if secret.Type == coreV1.SecretTypeServiceAccountToken &&
+			secret.Annotations[coreV1.ServiceAccountNameKey] == serviceAccount.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Create a serviceaccount object in a specific Kubernetes cluster
+func (p serviceAccountPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return serviceAccounts.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing serviceaccounts hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to serviceaccounts only
+func (p serviceAccountPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return serviceAccounts.List(ctx, namespace, client)
+}
+
+// Delete an existing serviceaccount hosted in a specific Kubernetes cluster
+func (p serviceAccountPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return serviceAccounts.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing serviceaccount hosted in a specific Kubernetes cluster
+func (p serviceAccountPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return serviceAccounts.Get(ctx, resource, namespace, client)
+}
+
+// Update a serviceaccount object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p serviceAccountPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return serviceAccounts.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a serviceaccount object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p serviceAccountPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return serviceAccounts.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodeServiceAccount(yamlFilePath string) (*coreV1.ServiceAccount, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode serviceaccount object error")
+	}
+
+	serviceAccount, ok := obj.(*coreV1.ServiceAccount)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than ServiceAccount")
+	}
+	return serviceAccount, nil
+}
+
+// keepTokenSecrets keeps the token secret references of the existing
+// ServiceAccount, they are added by the token controller
+func keepTokenSecrets(serviceAccount, existing *coreV1.ServiceAccount) {
+	if len(serviceAccount.Secrets) == 0 {
+		serviceAccount.Secrets = existing.Secrets
+	}
+}
+
+// serviceAccountClient adapts the ServiceAccount client to plugin.TypedClient
+type serviceAccountClient struct {
+	serviceAccounts typedCoreV1.ServiceAccountInterface
+}
+
+func (c serviceAccountClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.serviceAccounts.Create(ctx, obj.(*coreV1.ServiceAccount), opts)
+}
+
+func (c serviceAccountClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.serviceAccounts.Get(ctx, name, opts)
+}
+
+func (c serviceAccountClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.serviceAccounts.List(ctx, opts)
+}
+
+func (c serviceAccountClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.serviceAccounts.Update(ctx, obj.(*coreV1.ServiceAccount), opts)
+}
+
+func (c serviceAccountClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.serviceAccounts.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c serviceAccountClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.serviceAccounts.Delete(ctx, name, opts)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const serviceAccountManifest = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: mock-serviceaccount
+automountServiceAccountToken: %t
+`
+
+func TestCreateServiceAccount(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create a serviceaccount with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than ServiceAccount",
+		},
+		{
+			label:    "Successfully create a serviceaccount",
+			manifest: fmt.Sprintf(serviceAccountManifest, true),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := serviceAccountPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-serviceaccount" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-serviceaccount")
+			}
+
+			serviceAccount, err := client.GetStandardClient().CoreV1().ServiceAccounts("test1").
+				Get(context.TODO(), "mock-serviceaccount", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected serviceaccount to be created (%s)", err)
+			}
+			if serviceAccount.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected serviceaccount to be labeled with the instance ID, got %v", serviceAccount.Labels)
+			}
+		})
+	}
+}
+
+func TestListGetDeleteServiceAccount(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&coreV1.ServiceAccount{ObjectMeta: metaV1.ObjectMeta{Name: "operator", Namespace: "default"}},
+		&coreV1.ServiceAccount{ObjectMeta: metaV1.ObjectMeta{Name: "monitor", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	gvk := coreV1.SchemeGroupVersion.WithKind("ServiceAccount")
+
+	plugintest.CheckList(t, serviceAccountPlugin{}, client, gvk, "monitor", "operator")
+	plugintest.CheckGetDelete(t, serviceAccountPlugin{}, client, helm.KubernetesResource{GVK: gvk, Name: "operator"})
+}
+
+func TestUpdateServiceAccount(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+	serviceAccounts := client.GetStandardClient().CoreV1().ServiceAccounts("test1")
+
+	// The serviceaccount is created when it doesn't exist
+	_, err := serviceAccountPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(serviceAccountManifest, true)), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	// Reference a token secret like the token controller does
+	serviceAccount, err := serviceAccounts.Get(context.TODO(), "mock-serviceaccount", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected serviceaccount to be created (%s)", err)
+	}
+	serviceAccount.Secrets = []coreV1.ObjectReference{{Name: "mock-serviceaccount-token-abcde"}}
+	if _, err = serviceAccounts.Update(context.TODO(), serviceAccount, metaV1.UpdateOptions{}); err != nil {
+		t.Fatalf("Unable to update serviceaccount (%s)", err)
+	}
+
+	_, err = serviceAccountPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(serviceAccountManifest, false)), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	serviceAccount, err = serviceAccounts.Get(context.TODO(), "mock-serviceaccount", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get serviceaccount (%s)", err)
+	}
+	if automount := serviceAccount.AutomountServiceAccountToken; automount == nil || *automount {
+		t.Fatalf("Expected automountServiceAccountToken to be updated to false, got %v", automount)
+	}
+	if len(serviceAccount.Secrets) != 1 || serviceAccount.Secrets[0].Name != "mock-serviceaccount-token-abcde" {
+		t.Fatalf("Expected the token secret reference to be kept, got %v", serviceAccount.Secrets)
+	}
+	if serviceAccount.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected serviceaccount to be labeled with the instance ID, got %v", serviceAccount.Labels)
+	}
+}
+
+func TestWatchServiceAccountUntilReady(t *testing.T) {
+	oldInterval := readyPollInterval
+	readyPollInterval = 10 * time.Millisecond
+	defer func() {
+		readyPollInterval = oldInterval
+	}()
+
+	serviceAccount := &coreV1.ServiceAccount{
+		ObjectMeta: metaV1.ObjectMeta{Name: "operator", Namespace: "test1"},
+		Secrets:    []coreV1.ObjectReference{{Name: "operator-token-abcde"}},
+	}
+	tokenSecret := &coreV1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        "operator-token-abcde",
+			Namespace:   "test1",
+			Annotations: map[string]string{coreV1.ServiceAccountNameKey: "operator"},
+		},
+		Type: coreV1.SecretTypeServiceAccountToken,
+	}
+	testCases := []struct {
+		label         string
+		serverVersion string
+		objects       []runtime.Object
+		expectedError string
+	}{
+		{
+			label:         "Token secret is present on an older cluster",
+			serverVersion: "v1.19.4",
+			objects:       []runtime.Object{serviceAccount, tokenSecret},
+		},
+		{
+			label:         "Time out while the token secret is missing on an older cluster",
+			serverVersion: "v1.19.4",
+			objects:       []runtime.Object{serviceAccount},
+			expectedError: "timed out",
+		},
+		{
+			label:         "No token secret is needed on a token request cluster",
+			serverVersion: "v1.24.0",
+			objects:       []runtime.Object{&coreV1.ServiceAccount{ObjectMeta: metaV1.ObjectMeta{Name: "operator", Namespace: "test1"}}},
+		},
+		{
+			label:         "Fail when the serviceaccount is missing",
+			serverVersion: "v1.24.0",
+			expectedError: "not found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(testCase.objects...)
+			clientSet.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+				GitVersion: testCase.serverVersion,
+			}
+
+			err := serviceAccountPlugin{}.WatchUntilReady(context.TODO(), 50*time.Millisecond, "test1",
+				helm.KubernetesResource{Name: "operator"}, nil, nil, nil, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2018 Intel Corporation.
+Copyright © 2021 Nokia Bell Labs.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	appsV1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedAppsV1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/utils"
+)
+
+// Compile time check to see if statefulSetPlugin implements the correct interface
+var _ plugin.Reference = statefulSetPlugin{}
+
+// readyPollInterval is how often a StatefulSet is read while waiting for
+// its replicas to become ready
+var readyPollInterval = 2 * time.Second
+
+// ExportedVariable is what we will look for when calling the plugin
+var ExportedVariable statefulSetPlugin
+
+type statefulSetPlugin struct {
+}
+
+// statefulSets implements the methods of statefulSetPlugin shared with the
+// plugins of the other kinds
+var statefulSets = plugin.TypedResource{
+	GVK: appsV1.SchemeGroupVersion.WithKind("StatefulSet"),
+	Client: func(clientSet kubernetes.Interface, namespace string) plugin.TypedClient {
+		return statefulSetClient{clientSet.AppsV1().StatefulSets(namespace)}
+	},
+	Decode: func(yamlFilePath string) (plugin.Object, error) {
+		return decodeStatefulSet(yamlFilePath)
+	},
+	SetInstanceLabel: func(obj plugin.Object, instanceID string) {
+		setInstanceLabel(obj.(*appsV1.StatefulSet), instanceID)
+	},
+}
+
+// WatchUntilReady waits until all the replicas of the StatefulSet are ready
+func (g statefulSetPlugin) WatchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	ns string,
+	res helm.KubernetesResource,
+	mapper meta.RESTMapper,
+	restClient rest.Interface,
+	objType runtime.Object,
+	clientSet kubernetes.Interface) error {
+	return statefulSets.WaitUntil(ctx, timeout, readyPollInterval, res.Name, ns, clientSet, func(obj plugin.Object) (bool, error) {
+		statefulSet := obj.(*appsV1.StatefulSet)
+		// spec.replicas defaults to 1
+		replicas := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			replicas = *statefulSet.Spec.Replicas
+		}
+		return statefulSet.Status.ReadyReplicas == replicas, nil
+	})
+}
+
+// Create a statefulset object in a specific Kubernetes cluster
+func (p statefulSetPlugin) Create(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return statefulSets.Create(ctx, yamlFilePath, namespace, client)
+}
+
+// List of existing statefulsets hosted in a specific Kubernetes cluster
+// gvk parameter is not used as this plugin is specific to statefulsets only
+func (p statefulSetPlugin) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string, client plugin.KubernetesConnector) ([]helm.KubernetesResource, error) {
+	return statefulSets.List(ctx, namespace, client)
+}
+
+// Delete an existing statefulset hosted in a specific Kubernetes cluster
+func (p statefulSetPlugin) Delete(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) error {
+	return statefulSets.Delete(ctx, resource, namespace, client)
+}
+
+// Get an existing statefulset hosted in a specific Kubernetes cluster
+func (p statefulSetPlugin) Get(ctx context.Context, resource helm.KubernetesResource, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return statefulSets.Get(ctx, resource, namespace, client)
+}
+
+// Update a statefulset object in a specific Kubernetes cluster, it is
+// created if it doesn't exist yet
+func (p statefulSetPlugin) Update(ctx context.Context, yamlFilePath string, namespace string, client plugin.KubernetesConnector) (string, error) {
+	return statefulSets.Update(ctx, yamlFilePath, namespace, client)
+}
+
+// Patch a statefulset object in a specific Kubernetes cluster. The instance
+// label is set again if the patch removed or changed it.
+func (p statefulSetPlugin) Patch(ctx context.Context, resource helm.KubernetesResource, patchData []byte, patchType types.PatchType,
+	namespace string, client plugin.KubernetesConnector) (string, error) {
+	return statefulSets.Patch(ctx, resource, patchData, patchType, namespace, client)
+}
+
+func decodeStatefulSet(yamlFilePath string) (*appsV1.StatefulSet, error) {
+	obj, err := utils.DecodeManifest(yamlFilePath, nil)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Decode statefulset object error")
+	}
+
+	statefulSet, ok := obj.(*appsV1.StatefulSet)
+	if !ok {
+		return nil, pkgerrors.New("Decoded object contains another resource different than StatefulSet")
+	}
+	return statefulSet, nil
+}
+
+// setInstanceLabel adds the instance label to the statefulset and to the
+// pods it creates
+func setInstanceLabel(statefulSet *appsV1.StatefulSet, instanceID string) {
+	plugin.SetInstanceLabel(statefulSet, instanceID)
+	plugin.SetInstanceLabel(&statefulSet.Spec.Template, instanceID)
+}
+
+// statefulSetClient adapts the StatefulSet client to plugin.TypedClient
+type statefulSetClient struct {
+	statefulSets typedAppsV1.StatefulSetInterface
+}
+
+func (c statefulSetClient) Create(ctx context.Context, obj plugin.Object, opts metaV1.CreateOptions) (plugin.Object, error) {
+	return c.statefulSets.Create(ctx, obj.(*appsV1.StatefulSet), opts)
+}
+
+func (c statefulSetClient) Get(ctx context.Context, name string, opts metaV1.GetOptions) (plugin.Object, error) {
+	return c.statefulSets.Get(ctx, name, opts)
+}
+
+func (c statefulSetClient) List(ctx context.Context, opts metaV1.ListOptions) (runtime.Object, error) {
+	return c.statefulSets.List(ctx, opts)
+}
+
+func (c statefulSetClient) Update(ctx context.Context, obj plugin.Object, opts metaV1.UpdateOptions) (plugin.Object, error) {
+	return c.statefulSets.Update(ctx, obj.(*appsV1.StatefulSet), opts)
+}
+
+func (c statefulSetClient) Patch(ctx context.Context, name string, patchType types.PatchType, data []byte,
+	opts metaV1.PatchOptions) (plugin.Object, error) {
+	return c.statefulSets.Patch(ctx, name, patchType, data, opts)
+}
+
+func (c statefulSetClient) Delete(ctx context.Context, name string, opts metaV1.DeleteOptions) error {
+	return c.statefulSets.Delete(ctx, name, opts)
+}
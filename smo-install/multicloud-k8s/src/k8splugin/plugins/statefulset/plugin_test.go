@@ -0,0 +1,190 @@
+/*
+Copyright 2018 Intel Corporation.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/config"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/helm"
+	"github.com/onap/multicloud-k8s/src/k8splugin/internal/plugin/plugintest"
+
+	appsV1 "k8s.io/api/apps/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const statefulSetManifest = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: mock-statefulset
+spec:
+  serviceName: mock-service
+  replicas: 2
+  selector:
+    matchLabels:
+      app: db
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+      - name: db
+        image: %s
+`
+
+func TestCreateStatefulSet(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	testCases := []struct {
+		label         string
+		manifest      string
+		expectedError string
+	}{
+		{
+			label: "Fail to create a statefulset with invalid type",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: mock-service
+`,
+			expectedError: "contains another resource different than StatefulSet",
+		},
+		{
+			label:    "Successfully create a statefulset",
+			manifest: fmt.Sprintf(statefulSetManifest, "postgres:13"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+			result, err := statefulSetPlugin{}.Create(context.TODO(), plugintest.WriteManifest(t, testCase.manifest), "test1", client)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create method returned an error (%s)", err)
+			}
+			if result != "mock-statefulset" {
+				t.Fatalf("Create method returned %q, expected %q", result, "mock-statefulset")
+			}
+
+			statefulSet, err := client.GetStandardClient().AppsV1().StatefulSets("test1").
+				Get(context.TODO(), "mock-statefulset", metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected statefulset to be created (%s)", err)
+			}
+			if statefulSet.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected statefulset to be labeled with the instance ID, got %v", statefulSet.Labels)
+			}
+			if statefulSet.Spec.Template.Labels[labelName] != "inst1" {
+				t.Fatalf("Expected pods to be labeled with the instance ID, got %v", statefulSet.Spec.Template.Labels)
+			}
+		})
+	}
+}
+
+func TestListGetDeleteStatefulSet(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		&appsV1.StatefulSet{ObjectMeta: metaV1.ObjectMeta{Name: "db", Namespace: "default"}},
+		&appsV1.StatefulSet{ObjectMeta: metaV1.ObjectMeta{Name: "cache", Namespace: "default"}},
+	)
+	client := plugintest.FakeKubernetesConnector{ClientSet: clientSet}
+	gvk := appsV1.SchemeGroupVersion.WithKind("StatefulSet")
+
+	plugintest.CheckList(t, statefulSetPlugin{}, client, gvk, "cache", "db")
+	plugintest.CheckGetDelete(t, statefulSetPlugin{}, client, helm.KubernetesResource{GVK: gvk, Name: "db"})
+}
+
+func TestUpdateStatefulSet(t *testing.T) {
+	labelName := config.GetConfiguration().KubernetesLabelName
+	client := plugintest.FakeKubernetesConnector{ClientSet: fake.NewSimpleClientset(), InstanceID: "inst1"}
+
+	// The statefulset is created when it doesn't exist
+	_, err := statefulSetPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(statefulSetManifest, "postgres:13")), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+	_, err = statefulSetPlugin{}.Update(context.TODO(), plugintest.WriteManifest(t, fmt.Sprintf(statefulSetManifest, "postgres:14")), "test1", client)
+	if err != nil {
+		t.Fatalf("Update method returned an error (%s)", err)
+	}
+
+	statefulSet, err := client.GetStandardClient().AppsV1().StatefulSets("test1").
+		Get(context.TODO(), "mock-statefulset", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unable to get statefulset (%s)", err)
+	}
+	if image := statefulSet.Spec.Template.Spec.Containers[0].Image; image != "postgres:14" {
+		t.Fatalf("Expected image postgres:14, got %s", image)
+	}
+	if statefulSet.Labels[labelName] != "inst1" {
+		t.Fatalf("Expected statefulset to be labeled with the instance ID, got %v", statefulSet.Labels)
+	}
+}
+
+func TestWatchStatefulSetUntilReady(t *testing.T) {
+	oldInterval := readyPollInterval
+	readyPollInterval = 10 * time.Millisecond
+	defer func() {
+		readyPollInterval = oldInterval
+	}()
+
+	replicas := int32(2)
+	testCases := []struct {
+		label         string
+		readyReplicas int32
+		expectedError string
+	}{
+		{
+			label:         "All the replicas are ready",
+			readyReplicas: 2,
+		},
+		{
+			label:         "Time out while replicas are not ready",
+			readyReplicas: 1,
+			expectedError: "timed out",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			clientSet := fake.NewSimpleClientset(&appsV1.StatefulSet{
+				ObjectMeta: metaV1.ObjectMeta{Name: "db", Namespace: "test1"},
+				Spec:       appsV1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsV1.StatefulSetStatus{ReadyReplicas: testCase.readyReplicas},
+			})
+
+			err := statefulSetPlugin{}.WatchUntilReady(context.TODO(), 50*time.Millisecond, "test1",
+				helm.KubernetesResource{Name: "db"}, nil, nil, nil, clientSet)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WatchUntilReady method returned an error (%s)", err)
+			}
+		})
+	}
+}
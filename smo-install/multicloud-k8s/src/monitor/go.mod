@@ -7,6 +7,7 @@ require (
 	github.com/operator-framework/operator-sdk v0.19.0
 	github.com/operator-framework/operator-sdk-samples v0.0.0-20190529081445-bd30254f3a7e
 	github.com/phpdave11/gofpdi v1.0.8 // indirect
+	github.com/prometheus/client_golang v1.5.1
 	github.com/rogpeppe/go-charset v0.0.0-20190617161244-0dc95cdf6f31 // indirect
 	github.com/safchain/ethtool v0.0.0-20190326074333-42ed695e3de8 // indirect
 	github.com/sirupsen/logrus v1.5.0
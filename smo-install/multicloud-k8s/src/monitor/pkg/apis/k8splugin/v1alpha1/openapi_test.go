@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"k8s.io/kube-openapi/pkg/common"
+)
+
+func TestResourceBundleStatusOpenAPI(t *testing.T) {
+	defs := GetOpenAPIDefinitions(func(path string) spec.Ref {
+		return spec.MustCreateRef("#/definitions/" + path)
+	})
+	def, ok := defs["./pkg/apis/k8splugin/v1alpha1.ResourceBundleStatus"]
+	if !ok {
+		t.Fatalf("No OpenAPI definition for ResourceBundleStatus")
+	}
+
+	testCases := []struct {
+		property string
+		ref      string
+	}{
+		{property: "ingressStatuses", ref: "k8s.io/api/extensions/v1beta1.Ingress"},
+		{property: "daemonSetStatuses", ref: "k8s.io/api/apps/v1.DaemonSet"},
+		{property: "persistentVolumeClaimStatuses", ref: "k8s.io/api/core/v1.PersistentVolumeClaim"},
+		{property: "readyPods"},
+		{property: "totalPods"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.property, func(t *testing.T) {
+			prop, ok := def.Schema.Properties[testCase.property]
+			if !ok {
+				t.Fatalf("No %s property in the ResourceBundleStatus schema", testCase.property)
+			}
+			if testCase.ref == "" {
+				return
+			}
+			if prop.Items == nil || prop.Items.Schema == nil ||
+				prop.Items.Schema.Ref.String() != "#/definitions/"+testCase.ref {
+				t.Fatalf("Expected %s items to reference %s, got %+v", testCase.property, testCase.ref, prop.Items)
+			}
+			if !hasDependency(def, testCase.ref) {
+				t.Fatalf("Expected %s in the dependencies, got %v", testCase.ref, def.Dependencies)
+			}
+		})
+	}
+}
+
+func hasDependency(def common.OpenAPIDefinition, ref string) bool {
+	for _, dep := range def.Dependencies {
+		if dep == ref {
+			return true
+		}
+	}
+	return false
+}
@@ -44,6 +44,46 @@ type ResourceBundleStatus struct {
 	JobStatuses         []v1.Job                             `json:"jobStatuses" protobuf:"varint,12,opt,name=jobStatuses"`
 	StatefulSetStatuses []appsv1.StatefulSet                 `json:"statefulSetStatuses" protobuf:"varint,13,opt,name=statefulSetStatuses"`
 	CsrStatuses         []certsapi.CertificateSigningRequest `json:"csrStatuses" protobuf:"varint,3,opt,name=csrStatuses"`
+	ReadinessReasons    []ResourceReadiness                  `json:"readinessReasons,omitempty" protobuf:"varint,14,opt,name=readinessReasons"`
+	Conditions          []ResourceBundleCondition            `json:"conditions,omitempty" protobuf:"bytes,15,rep,name=conditions"`
+
+	// PersistentVolumeClaimStatuses is optional, it was added after the
+	// other statuses and is absent from the CRs created before
+	PersistentVolumeClaimStatuses []corev1.PersistentVolumeClaim `json:"persistentVolumeClaimStatuses,omitempty" protobuf:"varint,16,opt,name=persistentVolumeClaimStatuses"`
+
+	// ReadyPods of TotalPods tracked pods are ready, Ready is only set
+	// when all of them are and the other tracked resources are ready too
+	ReadyPods int32 `json:"readyPods" protobuf:"varint,17,opt,name=readyPods"`
+	TotalPods int32 `json:"totalPods" protobuf:"varint,18,opt,name=totalPods"`
+}
+
+// ResourceBundleConditionReady is the condition type aggregating the
+// readiness of all the tracked resources
+const ResourceBundleConditionReady = "Ready"
+
+// ResourceBundleCondition is an aggregate state of the bundle, following
+// the Kubernetes condition conventions
+// +k8s:openapi-gen=true
+type ResourceBundleCondition struct {
+	Type   string                 `json:"type" protobuf:"bytes,1,opt,name=type"`
+	Status corev1.ConditionStatus `json:"status" protobuf:"bytes,2,opt,name=status,casttype=k8s.io/api/core/v1.ConditionStatus"`
+	// Reason is a machine readable reason, e.g. PodsNotReady
+	Reason string `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
+	// Message is a human readable explanation of the reason
+	Message            string      `json:"message,omitempty" protobuf:"bytes,4,opt,name=message"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,5,opt,name=lastTransitionTime"`
+}
+
+// ResourceReadiness explains the readiness of a tracked resource
+// +k8s:openapi-gen=true
+type ResourceReadiness struct {
+	Kind  string `json:"kind" protobuf:"bytes,1,opt,name=kind"`
+	Name  string `json:"name" protobuf:"bytes,2,opt,name=name"`
+	Ready bool   `json:"ready" protobuf:"varint,3,opt,name=ready"`
+	// Code is a machine readable reason, e.g. ImagePullBackOff or PendingLB
+	Code string `json:"code,omitempty" protobuf:"bytes,4,opt,name=code"`
+	// Reason is a human readable explanation of the code
+	Reason string `json:"reason,omitempty" protobuf:"bytes,5,opt,name=reason"`
 }
 
 // PodStatus defines the observed state of ResourceBundleState
@@ -33,6 +33,23 @@ func (in *PodStatus) DeepCopy() *PodStatus {
 }
 */
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceBundleCondition) DeepCopyInto(out *ResourceBundleCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceBundleCondition.
+func (in *ResourceBundleCondition) DeepCopy() *ResourceBundleCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceBundleCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceBundleState) DeepCopyInto(out *ResourceBundleState) {
 	*out = *in
@@ -181,6 +198,25 @@ func (in *ResourceBundleStatus) DeepCopyInto(out *ResourceBundleStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PersistentVolumeClaimStatuses != nil {
+		in, out := &in.PersistentVolumeClaimStatuses, &out.PersistentVolumeClaimStatuses
+		*out = make([]corev1.PersistentVolumeClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadinessReasons != nil {
+		in, out := &in.ReadinessReasons, &out.ReadinessReasons
+		*out = make([]ResourceReadiness, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ResourceBundleCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -193,3 +229,19 @@ func (in *ResourceBundleStatus) DeepCopy() *ResourceBundleStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceReadiness) DeepCopyInto(out *ResourceReadiness) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReadiness.
+func (in *ResourceReadiness) DeepCopy() *ResourceReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -12,9 +12,11 @@ import (
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
 		"./pkg/apis/k8splugin/v1alpha1.PodStatus":               schema_pkg_apis_k8splugin_v1alpha1_PodStatus(ref),
+		"./pkg/apis/k8splugin/v1alpha1.ResourceBundleCondition": schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleCondition(ref),
 		"./pkg/apis/k8splugin/v1alpha1.ResourceBundleState":     schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleState(ref),
 		"./pkg/apis/k8splugin/v1alpha1.ResourceBundleStateSpec": schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleStateSpec(ref),
 		"./pkg/apis/k8splugin/v1alpha1.ResourceBundleStatus":    schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleStatus(ref),
+		"./pkg/apis/k8splugin/v1alpha1.ResourceReadiness":       schema_pkg_apis_k8splugin_v1alpha1_ResourceReadiness(ref),
 	}
 }
 
@@ -50,6 +52,53 @@ func schema_pkg_apis_k8splugin_v1alpha1_PodStatus(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceBundleCondition is an aggregate state of the bundle, following the Kubernetes condition conventions",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a machine readable reason, e.g. PodsNotReady",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable explanation of the reason",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleState(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -134,6 +183,19 @@ func schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleStatus(ref common.Referenc
 							Format: "int32",
 						},
 					},
+					"readyPods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadyPods of TotalPods tracked pods are ready, Ready is only set when all of them are and the other tracked resources are ready too",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"totalPods": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
 					"podStatuses": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
@@ -170,11 +232,130 @@ func schema_pkg_apis_k8splugin_v1alpha1_ResourceBundleStatus(ref common.Referenc
 							},
 						},
 					},
+					"deploymentStatuses": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/apps/v1.Deployment"),
+									},
+								},
+							},
+						},
+					},
+					"daemonSetStatuses": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/apps/v1.DaemonSet"),
+									},
+								},
+							},
+						},
+					},
+					"ingressStatuses": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/extensions/v1beta1.Ingress"),
+									},
+								},
+							},
+						},
+					},
+					"persistentVolumeClaimStatuses": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PersistentVolumeClaimStatuses is optional, it was added after the other statuses and is absent from the CRs created before",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.PersistentVolumeClaim"),
+									},
+								},
+							},
+						},
+					},
+					"readinessReasons": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("./pkg/apis/k8splugin/v1alpha1.ResourceReadiness"),
+									},
+								},
+							},
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("./pkg/apis/k8splugin/v1alpha1.ResourceBundleCondition"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"ready", "resourceCount", "podStatuses", "serviceStatuses"},
 			},
 		},
 		Dependencies: []string{
-			"./pkg/apis/k8splugin/v1alpha1.PodStatus", "k8s.io/api/core/v1.ConfigMap", "k8s.io/api/core/v1.Service"},
+			"./pkg/apis/k8splugin/v1alpha1.PodStatus", "./pkg/apis/k8splugin/v1alpha1.ResourceBundleCondition", "./pkg/apis/k8splugin/v1alpha1.ResourceReadiness", "k8s.io/api/apps/v1.DaemonSet", "k8s.io/api/apps/v1.Deployment", "k8s.io/api/core/v1.ConfigMap", "k8s.io/api/core/v1.PersistentVolumeClaim", "k8s.io/api/core/v1.Service", "k8s.io/api/extensions/v1beta1.Ingress"},
+	}
+}
+
+func schema_pkg_apis_k8splugin_v1alpha1_ResourceReadiness(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceReadiness explains the readiness of a tracked resource",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"ready": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"code": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Code is a machine readable reason, e.g. ImagePullBackOff or PendingLB",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a human readable explanation of the code",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"kind", "name", "ready"},
+			},
+		},
 	}
 }
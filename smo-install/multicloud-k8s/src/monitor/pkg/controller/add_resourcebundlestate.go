@@ -16,4 +16,5 @@ func init() {
 	AddToManagerFuncs = append(AddToManagerFuncs, resourcebundlestate.AddJobController)
 	AddToManagerFuncs = append(AddToManagerFuncs, resourcebundlestate.AddStatefulSetController)
 	AddToManagerFuncs = append(AddToManagerFuncs, resourcebundlestate.AddCsrController)
+	AddToManagerFuncs = append(AddToManagerFuncs, resourcebundlestate.AddPersistentVolumeClaimController)
 }
@@ -24,7 +24,7 @@ func AddConfigMapController(mgr manager.Manager) error {
 
 func addConfigMapController(mgr manager.Manager, r *configMapReconciler) error {
 	// Create a new controller
-	c, err := controller.New("ConfigMap-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("ConfigMap-controller", mgr, controller.Options{Reconciler: instrument("ConfigMap-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -16,7 +16,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Add the new controller to the controller manager
@@ -26,13 +25,19 @@ func Add(mgr manager.Manager) error {
 
 func add(mgr manager.Manager, r *reconciler) error {
 	// Create a new controller
-	c, err := controller.New("ResourceBundleState-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("ResourceBundleState-controller", mgr, controller.Options{Reconciler: instrument("ResourceBundleState-controller", r)})
 	if err != nil {
 		return err
 	}
 
 	// Watch for changes to primary resource ResourceBundleState
-	err = c.Watch(&source.Kind{Type: &v1alpha1.ResourceBundleState{}}, &EventHandler{})
+	// The CRs are listed again when the watch expires, so that none created
+	// during a long disconnect is missed
+	lw, err := newResourceBundleStateListWatch(mgr)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(newRelistingSource(lw, relistRetryDelay()), &EventHandler{})
 	if err != nil {
 		return err
 	}
@@ -66,6 +71,8 @@ func (r *reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return reconcile.Result{}, err
 	}
 
+	rbstate.Status.ReadinessReasons = []v1alpha1.ResourceReadiness{}
+
 	err = r.updatePods(rbstate, rbstate.Spec.Selector.MatchLabels)
 	if err != nil {
 		log.Printf("Error adding podstatuses: %v\n", err)
@@ -126,8 +133,13 @@ func (r *reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return reconcile.Result{}, err
 	}
 
-	// TODO: Update this based on the statuses of the lower resources
-	rbstate.Status.Ready = false
+	err = r.updatePersistentVolumeClaims(rbstate, rbstate.Spec.Selector.MatchLabels)
+	if err != nil {
+		log.Printf("Error adding persistentVolumeClaimStatuses: %v\n", err)
+		return reconcile.Result{}, err
+	}
+
+	updateReadyCondition(&rbstate.Status)
 	err = r.client.Status().Update(context.TODO(), rbstate)
 	if err != nil {
 		log.Printf("failed to update rbstate: %v\n", err)
@@ -151,6 +163,13 @@ func (r *reconciler) updateServices(rbstate *v1alpha1.ResourceBundleState,
 	rbstate.Status.ServiceStatuses = []corev1.Service{}
 
 	for _, svc := range serviceList.Items {
+		endpoints, err := getEndpoints(r.client, &svc)
+		if err != nil {
+			log.Printf("Failed to get endpoints: %v", err)
+			return err
+		}
+		setReadiness(&rbstate.Status, serviceReadiness(&svc, endpoints))
+
 		resStatus := corev1.Service{
 			TypeMeta:   svc.TypeMeta,
 			ObjectMeta: svc.ObjectMeta,
@@ -176,6 +195,8 @@ func (r *reconciler) updatePods(rbstate *v1alpha1.ResourceBundleState,
 	rbstate.Status.PodStatuses = []corev1.Pod{}
 
 	for _, pod := range podList.Items {
+		setReadiness(&rbstate.Status, podReadiness(&pod))
+
 		resStatus := corev1.Pod{
 			TypeMeta:   pod.TypeMeta,
 			ObjectMeta: pod.ObjectMeta,
@@ -225,6 +246,8 @@ func (r *reconciler) updateDeployments(rbstate *v1alpha1.ResourceBundleState,
 	rbstate.Status.DeploymentStatuses = []appsv1.Deployment{}
 
 	for _, dep := range deploymentList.Items {
+		setReadiness(&rbstate.Status, deploymentReadiness(&dep))
+
 		resStatus := appsv1.Deployment{
 			TypeMeta:   dep.TypeMeta,
 			ObjectMeta: dep.ObjectMeta,
@@ -260,6 +283,33 @@ func (r *reconciler) updateSecrets(rbstate *v1alpha1.ResourceBundleState,
 	return nil
 }
 
+func (r *reconciler) updatePersistentVolumeClaims(rbstate *v1alpha1.ResourceBundleState,
+	selectors map[string]string) error {
+
+	// Update the CR with the PersistentVolumeClaims created as well
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	err := listResources(r.client, rbstate.Namespace, selectors, pvcList)
+	if err != nil {
+		log.Printf("Failed to list PersistentVolumeClaims: %v", err)
+		return err
+	}
+
+	rbstate.Status.PersistentVolumeClaimStatuses = []corev1.PersistentVolumeClaim{}
+
+	for _, pvc := range pvcList.Items {
+		setReadiness(&rbstate.Status, pvcReadiness(&pvc))
+
+		resStatus := corev1.PersistentVolumeClaim{
+			TypeMeta:   pvc.TypeMeta,
+			ObjectMeta: pvc.ObjectMeta,
+			Status:     pvc.Status,
+		}
+		rbstate.Status.PersistentVolumeClaimStatuses = append(rbstate.Status.PersistentVolumeClaimStatuses, resStatus)
+	}
+
+	return nil
+}
+
 func (r *reconciler) updateDaemonSets(rbstate *v1alpha1.ResourceBundleState,
 	selectors map[string]string) error {
 
@@ -274,6 +324,8 @@ func (r *reconciler) updateDaemonSets(rbstate *v1alpha1.ResourceBundleState,
 	rbstate.Status.DaemonSetStatuses = []appsv1.DaemonSet{}
 
 	for _, ds := range daemonSetList.Items {
+		setReadiness(&rbstate.Status, daemonSetReadiness(&ds))
+
 		resStatus := appsv1.DaemonSet{
 			TypeMeta:   ds.TypeMeta,
 			ObjectMeta: ds.ObjectMeta,
@@ -0,0 +1,276 @@
+package resourcebundlestate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis"
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const testNamespace = "test"
+
+// testLabels select the resources of the test bundle
+var testLabels = map[string]string{"emco/deployment-id": "inst1"}
+
+// newTestClient returns a fake client knowing the Kubernetes types and the
+// ResourceBundleState, holding the test bundle and objs
+func newTestClient(t *testing.T, objs ...runtime.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unable to register the Kubernetes types (%s)", err)
+	}
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unable to register the ResourceBundleState (%s)", err)
+	}
+
+	bundle := &v1alpha1.ResourceBundleState{
+		ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: testNamespace, Labels: testLabels},
+		Spec: v1alpha1.ResourceBundleStateSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: testLabels},
+		},
+	}
+	return fake.NewFakeClientWithScheme(scheme, append(objs, bundle)...)
+}
+
+// reconcileBundle reconciles the test bundle and returns it as stored
+func reconcileBundle(t *testing.T, cli client.Client) *v1alpha1.ResourceBundleState {
+	key := types.NamespacedName{Namespace: testNamespace, Name: "bundle"}
+	_, err := (&reconciler{client: cli}).Reconcile(reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile returned an error (%s)", err)
+	}
+
+	bundle := &v1alpha1.ResourceBundleState{}
+	if err := cli.Get(context.TODO(), key, bundle); err != nil {
+		t.Fatalf("Unable to get the reconciled bundle (%s)", err)
+	}
+	return bundle
+}
+
+// testMeta returns the metadata of a resource of the test bundle
+func testMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: testLabels}
+}
+
+// findReadiness returns the readiness entry of a resource, failing the test
+// if there is none
+func findReadiness(t *testing.T, status v1alpha1.ResourceBundleStatus, kind, name string) v1alpha1.ResourceReadiness {
+	for _, rr := range status.ReadinessReasons {
+		if rr.Kind == kind && rr.Name == name {
+			return rr
+		}
+	}
+	t.Fatalf("No readiness reported for %s %s in %+v", kind, name, status.ReadinessReasons)
+	return v1alpha1.ResourceReadiness{}
+}
+
+func TestReconcileReadinessReasons(t *testing.T) {
+	waiting := func(name, reason string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: testMeta(name),
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "app",
+					Image: "registry.example.com/app:1.0",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+				}},
+			},
+		}
+	}
+	replicas := int32(2)
+
+	cli := newTestClient(t,
+		waiting("pull", "ImagePullBackOff"),
+		waiting("crash", "CrashLoopBackOff"),
+		&corev1.Pod{
+			ObjectMeta: testMeta("unschedulable"),
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available",
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: testMeta("running"),
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: testMeta("no-endpoints"),
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		},
+		&corev1.Service{
+			ObjectMeta: testMeta("pending-lb"),
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: testMeta("rolling"),
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{UpdatedReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: testMeta("stuck"),
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:    appsv1.DeploymentProgressing,
+					Status:  corev1.ConditionFalse,
+					Reason:  "ProgressDeadlineExceeded",
+					Message: `ReplicaSet "stuck-5d8f" has timed out progressing.`,
+				}},
+			},
+		},
+	)
+
+	bundle := reconcileBundle(t, cli)
+
+	testCases := []struct {
+		kind  string
+		name  string
+		code  string
+		ready bool
+	}{
+		{kind: "Pod", name: "pull", code: readinessImagePullBackOff},
+		{kind: "Pod", name: "crash", code: readinessCrashLoopBackOff},
+		{kind: "Pod", name: "unschedulable", code: readinessUnschedulable},
+		{kind: "Pod", name: "running", code: readinessReady, ready: true},
+		{kind: "Service", name: "no-endpoints", code: readinessNoEndpoints},
+		{kind: "Service", name: "pending-lb", code: readinessPendingLB},
+		{kind: "Deployment", name: "rolling", code: readinessRolloutInProgress},
+		{kind: "Deployment", name: "stuck", code: readinessProgressDeadline},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.kind+" "+testCase.name, func(t *testing.T) {
+			rr := findReadiness(t, bundle.Status, testCase.kind, testCase.name)
+			if rr.Code != testCase.code || rr.Ready != testCase.ready {
+				t.Fatalf("Expected code %s and ready %t, got %+v", testCase.code, testCase.ready, rr)
+			}
+			if rr.Reason == "" {
+				t.Fatalf("Expected a reason for code %s", rr.Code)
+			}
+		})
+	}
+	if len(bundle.Status.ReadinessReasons) != len(testCases) {
+		t.Fatalf("Expected %d readiness entries, got %+v", len(testCases), bundle.Status.ReadinessReasons)
+	}
+}
+
+func TestReconcileDaemonSetStatuses(t *testing.T) {
+	cli := newTestClient(t, &appsv1.DaemonSet{
+		ObjectMeta: testMeta("node-agent"),
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3,
+			NumberReady:            2,
+		},
+	})
+
+	bundle := reconcileBundle(t, cli)
+
+	if len(bundle.Status.DaemonSetStatuses) != 1 {
+		t.Fatalf("Expected 1 daemonset status, got %+v", bundle.Status.DaemonSetStatuses)
+	}
+	ds := bundle.Status.DaemonSetStatuses[0]
+	if ds.Name != "node-agent" || ds.Status.DesiredNumberScheduled != 3 || ds.Status.NumberReady != 2 {
+		t.Fatalf("Expected node-agent with 2 of 3 nodes ready, got %s %+v", ds.Name, ds.Status)
+	}
+	rr := findReadiness(t, bundle.Status, "DaemonSet", "node-agent")
+	if rr.Ready || rr.Code != readinessReplicasNotReady {
+		t.Fatalf("Expected code %s and not ready, got %+v", readinessReplicasNotReady, rr)
+	}
+}
+
+func TestReconcilePersistentVolumeClaims(t *testing.T) {
+	testCases := []struct {
+		label  string
+		phase  corev1.PersistentVolumeClaimPhase
+		ready  bool
+		reason string
+	}{
+		{
+			label:  "Pending claim keeps the bundle not ready",
+			phase:  corev1.ClaimPending,
+			reason: "PersistentVolumeClaimsNotReady",
+		},
+		{
+			label:  "Bound claim",
+			phase:  corev1.ClaimBound,
+			ready:  true,
+			reason: readyConditionAllReady,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			cli := newTestClient(t,
+				&corev1.Pod{
+					ObjectMeta: testMeta("db-0"),
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				},
+				&corev1.PersistentVolumeClaim{
+					ObjectMeta: testMeta("data-db-0"),
+					Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+					Status:     corev1.PersistentVolumeClaimStatus{Phase: testCase.phase},
+				},
+			)
+
+			bundle := reconcileBundle(t, cli)
+
+			claims := bundle.Status.PersistentVolumeClaimStatuses
+			if len(claims) != 1 || claims[0].Status.Phase != testCase.phase {
+				t.Fatalf("Expected 1 claim %s, got %+v", testCase.phase, claims)
+			}
+			if bundle.Status.Ready != testCase.ready {
+				t.Fatalf("Expected ready %t, got %t", testCase.ready, bundle.Status.Ready)
+			}
+			conds := bundle.Status.Conditions
+			if len(conds) != 1 || conds[0].Reason != testCase.reason {
+				t.Fatalf("Expected a Ready condition with reason %s, got %+v", testCase.reason, conds)
+			}
+		})
+	}
+}
+
+func TestReconcileIngressStatuses(t *testing.T) {
+	cli := newTestClient(t, &v1beta1.Ingress{
+		ObjectMeta: testMeta("web"),
+		Status: v1beta1.IngressStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.5"}},
+			},
+		},
+	})
+
+	bundle := reconcileBundle(t, cli)
+
+	ingresses := bundle.Status.IngressStatuses
+	if len(ingresses) != 1 || ingresses[0].Name != "web" {
+		t.Fatalf("Expected the status of ingress web, got %+v", ingresses)
+	}
+	lb := ingresses[0].Status.LoadBalancer.Ingress
+	if len(lb) != 1 || lb[0].IP != "10.0.0.5" {
+		t.Fatalf("Expected the load balancer IP 10.0.0.5, got %+v", lb)
+	}
+}
@@ -24,7 +24,7 @@ func AddCsrController(mgr manager.Manager) error {
 
 func addCsrController(mgr manager.Manager, r *csrReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Csr-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Csr-controller", mgr, controller.Options{Reconciler: instrument("Csr-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -24,7 +24,7 @@ func AddDaemonSetController(mgr manager.Manager) error {
 
 func addDaemonSetController(mgr manager.Manager, r *daemonSetReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Daemonset-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Daemonset-controller", mgr, controller.Options{Reconciler: instrument("Daemonset-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -131,6 +131,8 @@ func (r *daemonSetReconciler) updateCRs(crl *v1alpha1.ResourceBundleStateList, d
 
 func (r *daemonSetReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState, name string) error {
 	cr.Status.ResourceCount--
+	removeReadiness(&cr.Status, "DaemonSet", name)
+	updateReadyCondition(&cr.Status)
 	length := len(cr.Status.DaemonSetStatuses)
 	for i, rstatus := range cr.Status.DaemonSetStatuses {
 		if rstatus.Name == name {
@@ -148,6 +150,9 @@ func (r *daemonSetReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleStat
 
 func (r *daemonSetReconciler) updateSingleCR(cr *v1alpha1.ResourceBundleState, ds *appsv1.DaemonSet) error {
 
+	setReadiness(&cr.Status, daemonSetReadiness(ds))
+	updateReadyCondition(&cr.Status)
+
 	// Update status after searching for it in the list of resourceStatuses
 	for i, rstatus := range cr.Status.DaemonSetStatuses {
 		// Look for the status if we already have it in the CR
@@ -24,7 +24,7 @@ func AddDeploymentController(mgr manager.Manager) error {
 
 func addDeploymentController(mgr manager.Manager, r *deploymentReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Deployment-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Deployment-controller", mgr, controller.Options{Reconciler: instrument("Deployment-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -131,6 +131,8 @@ func (r *deploymentReconciler) updateCRs(crl *v1alpha1.ResourceBundleStateList,
 
 func (r *deploymentReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState, name string) error {
 	cr.Status.ResourceCount--
+	removeReadiness(&cr.Status, "Deployment", name)
+	updateReadyCondition(&cr.Status)
 	length := len(cr.Status.DeploymentStatuses)
 	for i, rstatus := range cr.Status.DeploymentStatuses {
 		if rstatus.Name == name {
@@ -148,6 +150,9 @@ func (r *deploymentReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleSta
 
 func (r *deploymentReconciler) updateSingleCR(cr *v1alpha1.ResourceBundleState, dep *appsv1.Deployment) error {
 
+	setReadiness(&cr.Status, deploymentReadiness(dep))
+	updateReadyCondition(&cr.Status)
+
 	// Update status after searching for it in the list of resourceStatuses
 	for i, rstatus := range cr.Status.DeploymentStatuses {
 		// Look for the status if we already have it in the CR
@@ -0,0 +1,42 @@
+package resourcebundlestate
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// endpointsPredicate filters the Endpoints of the services we track. The
+// endpoints controller copies the labels of a Service to its Endpoints.
+type endpointsPredicate struct {
+}
+
+func (e *endpointsPredicate) Create(evt event.CreateEvent) bool {
+
+	if evt.Meta == nil {
+		return false
+	}
+
+	labels := evt.Meta.GetLabels()
+	return checkLabel(labels)
+}
+
+// Delete ignores the Endpoints deleted along with their Service, whose
+// own Delete event removes it from the CRs
+func (e *endpointsPredicate) Delete(evt event.DeleteEvent) bool {
+	return false
+}
+
+func (e *endpointsPredicate) Update(evt event.UpdateEvent) bool {
+
+	if evt.MetaNew == nil {
+		return false
+	}
+
+	labels := evt.MetaNew.GetLabels()
+	return checkLabel(labels)
+}
+
+func (e *endpointsPredicate) Generic(evt event.GenericEvent) bool {
+
+	labels := evt.Meta.GetLabels()
+	return checkLabel(labels)
+}
@@ -24,7 +24,7 @@ func AddIngressController(mgr manager.Manager) error {
 
 func addIngressController(mgr manager.Manager, r *ingressReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Ingress-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Ingress-controller", mgr, controller.Options{Reconciler: instrument("Ingress-controller", r)})
 	if err != nil {
 		return err
 	}
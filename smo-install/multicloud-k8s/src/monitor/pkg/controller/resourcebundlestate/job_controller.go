@@ -24,7 +24,7 @@ func AddJobController(mgr manager.Manager) error {
 
 func addJobController(mgr manager.Manager, r *jobReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Job-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Job-controller", mgr, controller.Options{Reconciler: instrument("Job-controller", r)})
 	if err != nil {
 		return err
 	}
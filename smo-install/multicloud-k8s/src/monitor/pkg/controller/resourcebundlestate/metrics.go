@@ -0,0 +1,60 @@
+package resourcebundlestate
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Metrics served on the manager metrics endpoint next to the ones of
+// controller-runtime, which already include the depth of each controller
+// workqueue as workqueue_depth{name="<controller>"}
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "monitor_reconcile_duration_seconds",
+		Help:    "Duration of the reconciles per controller and result",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"controller", "result"})
+
+	reconcileRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_reconcile_retries_total",
+		Help: "Reconciles requeued because of an error or a requeue request",
+	}, []string{"controller"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, reconcileRetries)
+}
+
+// instrumentedReconciler records the metrics of the reconciles of a controller
+type instrumentedReconciler struct {
+	name       string
+	reconciler reconcile.Reconciler
+}
+
+// instrument wraps r so that its reconciles are measured under name
+func instrument(name string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &instrumentedReconciler{name: name, reconciler: r}
+}
+
+// Reconcile implements reconcile.Reconciler
+func (i *instrumentedReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	result, err := i.reconciler.Reconcile(req)
+
+	label := "success"
+	switch {
+	case err != nil:
+		label = "error"
+	case result.Requeue || result.RequeueAfter > 0:
+		label = "requeue"
+	}
+	reconcileDuration.WithLabelValues(i.name, label).Observe(time.Since(start).Seconds())
+	if label != "success" {
+		reconcileRetries.WithLabelValues(i.name).Inc()
+	}
+
+	return result, err
+}
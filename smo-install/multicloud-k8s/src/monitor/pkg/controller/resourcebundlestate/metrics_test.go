@@ -0,0 +1,74 @@
+package resourcebundlestate
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileFunc is a reconcile.Reconciler calling itself
+type reconcileFunc func(reconcile.Request) (reconcile.Result, error)
+
+func (f reconcileFunc) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	return f(req)
+}
+
+func TestInstrumentedReconcilerMetrics(t *testing.T) {
+	results := map[string]struct {
+		result reconcile.Result
+		err    error
+	}{
+		"ok1":     {},
+		"ok2":     {},
+		"failed":  {err: errors.New("transient failure")},
+		"requeue": {result: reconcile.Result{Requeue: true}},
+	}
+	r := instrument("metrics-test", reconcileFunc(func(req reconcile.Request) (reconcile.Result, error) {
+		return results[req.Name].result, results[req.Name].err
+	}))
+
+	for name := range results {
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: name}}
+		if _, err := r.Reconcile(req); (err != nil) != (name == "failed") {
+			t.Fatalf("Unexpected error for %s (%v)", name, err)
+		}
+	}
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "metrics-test")
+	defer queue.ShutDown()
+	for _, name := range []string{"a", "b", "c"} {
+		queue.Add(name)
+	}
+
+	server := httptest.NewServer(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unable to scrape the metrics (%s)", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Unable to read the metrics (%s)", err)
+	}
+
+	for _, line := range []string{
+		`monitor_reconcile_duration_seconds_count{controller="metrics-test",result="success"} 2`,
+		`monitor_reconcile_duration_seconds_count{controller="metrics-test",result="error"} 1`,
+		`monitor_reconcile_duration_seconds_count{controller="metrics-test",result="requeue"} 1`,
+		`monitor_reconcile_retries_total{controller="metrics-test"} 2`,
+		`workqueue_depth{name="metrics-test"} 3`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("Expected the metric %s", line)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package resourcebundlestate
+
+import (
+	"context"
+	"log"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// AddPersistentVolumeClaimController the new controller to the controller manager
+func AddPersistentVolumeClaimController(mgr manager.Manager) error {
+	return addPersistentVolumeClaimController(mgr, newPersistentVolumeClaimReconciler(mgr))
+}
+
+func addPersistentVolumeClaimController(mgr manager.Manager, r *persistentVolumeClaimReconciler) error {
+	// Create a new controller
+	c, err := controller.New("PersistentVolumeClaim-controller", mgr, controller.Options{Reconciler: instrument("PersistentVolumeClaim-controller", r)})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to secondary resource PersistentVolumeClaims
+	// Predicate filters PersistentVolumeClaims which don't have the k8splugin label
+	err = c.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, &handler.EnqueueRequestForObject{}, &persistentVolumeClaimPredicate{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func newPersistentVolumeClaimReconciler(m manager.Manager) *persistentVolumeClaimReconciler {
+	return &persistentVolumeClaimReconciler{client: m.GetClient()}
+}
+
+type persistentVolumeClaimReconciler struct {
+	client client.Client
+}
+
+// Reconcile implements the loop that will update the ResourceBundleState CR
+// whenever we get any updates from all the persistentVolumeClaims we watch.
+func (r *persistentVolumeClaimReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	log.Printf("Updating ResourceBundleState for PersistentVolumeClaim: %+v\n", req)
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.client.Get(context.TODO(), req.NamespacedName, pvc)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Printf("PersistentVolumeClaim not found: %+v. Remove from CR if it is stored there.\n", req.NamespacedName)
+			// Remove the PersistentVolumeClaim's status from StatusList
+			// This can happen if we get the DeletionTimeStamp event
+			// after the PersistentVolumeClaim has been deleted.
+			r.deletePersistentVolumeClaimFromAllCRs(req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Failed to get persistentVolumeClaim: %+v\n", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	// Find the CRs which track this persistentVolumeClaim via the labelselector
+	crSelector := returnLabel(pvc.GetLabels())
+	if crSelector == nil {
+		log.Println("We should not be here. The predicate should have filtered this PersistentVolumeClaim")
+	}
+
+	// Get the CRs which have this label and update them all
+	// Ideally, we will have only one CR, but there is nothing
+	// preventing the creation of multiple.
+	// TODO: Consider using an admission validating webook to prevent multiple
+	rbStatusList := &v1alpha1.ResourceBundleStateList{}
+	err = listResources(r.client, req.Namespace, crSelector, rbStatusList)
+	if err != nil || len(rbStatusList.Items) == 0 {
+		log.Printf("Did not find any CRs tracking this resource\n")
+		return reconcile.Result{}, nil
+	}
+
+	err = r.updateCRs(rbStatusList, pvc)
+	if err != nil {
+		// Requeue the update
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// deletePersistentVolumeClaimFromAllCRs deletes persistentVolumeClaim status from all the CRs when the PersistentVolumeClaim itself has been deleted
+// and we have not handled the updateCRs yet.
+// Since, we don't have the persistentVolumeClaim's labels, we need to look at all the CRs in this namespace
+func (r *persistentVolumeClaimReconciler) deletePersistentVolumeClaimFromAllCRs(namespacedName types.NamespacedName) error {
+
+	rbStatusList := &v1alpha1.ResourceBundleStateList{}
+	err := listResources(r.client, namespacedName.Namespace, nil, rbStatusList)
+	if err != nil || len(rbStatusList.Items) == 0 {
+		log.Printf("Did not find any CRs tracking this resource\n")
+		return nil
+	}
+	for _, cr := range rbStatusList.Items {
+		r.deleteFromSingleCR(&cr, namespacedName.Name)
+	}
+
+	return nil
+}
+
+func (r *persistentVolumeClaimReconciler) updateCRs(crl *v1alpha1.ResourceBundleStateList, pvc *corev1.PersistentVolumeClaim) error {
+
+	for _, cr := range crl.Items {
+		// PersistentVolumeClaim is not scheduled for deletion
+		if pvc.DeletionTimestamp == nil {
+			err := r.updateSingleCR(&cr, pvc)
+			if err != nil {
+				return err
+			}
+		} else {
+			// PersistentVolumeClaim is scheduled for deletion
+			r.deleteFromSingleCR(&cr, pvc.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *persistentVolumeClaimReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState, name string) error {
+	cr.Status.ResourceCount--
+	removeReadiness(&cr.Status, "PersistentVolumeClaim", name)
+	updateReadyCondition(&cr.Status)
+	length := len(cr.Status.PersistentVolumeClaimStatuses)
+	for i, rstatus := range cr.Status.PersistentVolumeClaimStatuses {
+		if rstatus.Name == name {
+			//Delete that status from the array
+			cr.Status.PersistentVolumeClaimStatuses[i] = cr.Status.PersistentVolumeClaimStatuses[length-1]
+			cr.Status.PersistentVolumeClaimStatuses[length-1].Status = corev1.PersistentVolumeClaimStatus{}
+			cr.Status.PersistentVolumeClaimStatuses = cr.Status.PersistentVolumeClaimStatuses[:length-1]
+			return nil
+		}
+	}
+
+	log.Println("Did not find a status for PersistentVolumeClaim in CR")
+	return nil
+}
+
+func (r *persistentVolumeClaimReconciler) updateSingleCR(cr *v1alpha1.ResourceBundleState, pvc *corev1.PersistentVolumeClaim) error {
+
+	setReadiness(&cr.Status, pvcReadiness(pvc))
+	updateReadyCondition(&cr.Status)
+
+	// Update status after searching for it in the list of resourceStatuses
+	for i, rstatus := range cr.Status.PersistentVolumeClaimStatuses {
+		// Look for the status if we already have it in the CR
+		if rstatus.Name == pvc.Name {
+			pvc.Status.DeepCopyInto(&cr.Status.PersistentVolumeClaimStatuses[i].Status)
+			err := r.client.Status().Update(context.TODO(), cr)
+			if err != nil {
+				log.Printf("failed to update rbstate: %v\n", err)
+				return err
+			}
+			return nil
+		}
+	}
+
+	// Exited for loop with no status found
+	// Increment the number of tracked resources
+	cr.Status.ResourceCount++
+
+	// Add it to CR
+	cr.Status.PersistentVolumeClaimStatuses = append(cr.Status.PersistentVolumeClaimStatuses, corev1.PersistentVolumeClaim{
+		TypeMeta:   pvc.TypeMeta,
+		ObjectMeta: pvc.ObjectMeta,
+		Status:     pvc.Status,
+	})
+
+	err := r.client.Status().Update(context.TODO(), cr)
+	if err != nil {
+		log.Printf("failed to update rbstate: %v\n", err)
+		return err
+	}
+
+	return nil
+}
@@ -24,7 +24,7 @@ func AddPodController(mgr manager.Manager) error {
 
 func addPodController(mgr manager.Manager, r *podReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Pod-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Pod-controller", mgr, controller.Options{Reconciler: instrument("Pod-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -131,6 +131,8 @@ func (r *podReconciler) updateCRs(crl *v1alpha1.ResourceBundleStateList, pod *co
 
 func (r *podReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState, name string) error {
 	cr.Status.ResourceCount--
+	removeReadiness(&cr.Status, "Pod", name)
+	updateReadyCondition(&cr.Status)
 	length := len(cr.Status.PodStatuses)
 	for i, rstatus := range cr.Status.PodStatuses {
 		if rstatus.Name == name {
@@ -148,6 +150,9 @@ func (r *podReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState, nam
 
 func (r *podReconciler) updateSingleCR(cr *v1alpha1.ResourceBundleState, pod *corev1.Pod) error {
 
+	setReadiness(&cr.Status, podReadiness(pod))
+	updateReadyCondition(&cr.Status)
+
 	// Update status after searching for it in the list of resourceStatuses
 	for i, rstatus := range cr.Status.PodStatuses {
 		// Look for the status if we already have it in the CR
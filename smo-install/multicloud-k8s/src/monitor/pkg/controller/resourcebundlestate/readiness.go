@@ -0,0 +1,301 @@
+package resourcebundlestate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the Ready condition when all the tracked resources are ready.
+// Otherwise the reason is the kind of the first resource not ready followed
+// by "sNotReady", e.g. PodsNotReady.
+const readyConditionAllReady = "AllResourcesReady"
+
+// Machine readable codes reported in ResourceReadiness
+const (
+	readinessReady              = "Ready"
+	readinessImagePullBackOff   = "ImagePullBackOff"
+	readinessCrashLoopBackOff   = "CrashLoopBackOff"
+	readinessUnschedulable      = "Unschedulable"
+	readinessPodFailed          = "PodFailed"
+	readinessContainersNotReady = "ContainersNotReady"
+	readinessPending            = "Pending"
+	readinessNoEndpoints        = "NoEndpoints"
+	readinessPendingLB          = "PendingLB"
+	readinessProgressDeadline   = "ProgressDeadlineExceeded"
+	readinessRolloutInProgress  = "RolloutInProgress"
+	readinessReplicasNotReady   = "ReplicasUnavailable"
+	readinessNotScheduled       = "NotScheduled"
+	readinessClaimNotBound      = "ClaimNotBound"
+)
+
+// podReadiness explains the readiness of a pod from its status
+func podReadiness(pod *corev1.Pod) v1alpha1.ResourceReadiness {
+	rr := v1alpha1.ResourceReadiness{Kind: "Pod", Name: pod.Name}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		switch cs.State.Waiting.Reason {
+		case "ImagePullBackOff", "ErrImagePull":
+			rr.Code = readinessImagePullBackOff
+			rr.Reason = fmt.Sprintf("Container %s can't pull image %s", cs.Name, cs.Image)
+			return rr
+		case "CrashLoopBackOff":
+			rr.Code = readinessCrashLoopBackOff
+			rr.Reason = fmt.Sprintf("Container %s keeps crashing", cs.Name)
+			return rr
+		}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		rr.Code = readinessPodFailed
+		rr.Reason = "Pod failed"
+		if pod.Status.Reason != "" {
+			rr.Reason = "Pod failed: " + pod.Status.Reason
+		}
+		return rr
+	case corev1.PodSucceeded:
+		rr.Ready = true
+		rr.Code = readinessReady
+		rr.Reason = "Pod completed"
+		return rr
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+			cond.Reason == corev1.PodReasonUnschedulable {
+			rr.Code = readinessUnschedulable
+			rr.Reason = cond.Message
+			return rr
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			if cond.Status == corev1.ConditionTrue {
+				rr.Ready = true
+				rr.Code = readinessReady
+				rr.Reason = "All containers are ready"
+				return rr
+			}
+			rr.Code = readinessContainersNotReady
+			rr.Reason = cond.Message
+			return rr
+		}
+	}
+
+	rr.Code = readinessPending
+	rr.Reason = "Pod is pending"
+	return rr
+}
+
+// serviceReadiness explains the readiness of a service from its status and
+// its Endpoints, which may be nil if they don't exist
+func serviceReadiness(svc *corev1.Service, endpoints *corev1.Endpoints) v1alpha1.ResourceReadiness {
+	rr := v1alpha1.ResourceReadiness{Kind: "Service", Name: svc.Name}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0 {
+		rr.Code = readinessPendingLB
+		rr.Reason = "Load balancer is not provisioned yet"
+		return rr
+	}
+
+	// Services without a selector have their endpoints managed externally
+	if svc.Spec.Type != corev1.ServiceTypeExternalName && len(svc.Spec.Selector) > 0 {
+		ready := 0
+		if endpoints != nil {
+			for _, subset := range endpoints.Subsets {
+				ready += len(subset.Addresses)
+			}
+		}
+		if ready == 0 {
+			rr.Code = readinessNoEndpoints
+			rr.Reason = "No ready pods match the service selector"
+			return rr
+		}
+	}
+
+	rr.Ready = true
+	rr.Code = readinessReady
+	rr.Reason = "Service is ready"
+	return rr
+}
+
+// deploymentReadiness explains the readiness of a deployment from its status
+func deploymentReadiness(dep *appsv1.Deployment) v1alpha1.ResourceReadiness {
+	rr := v1alpha1.ResourceReadiness{Kind: "Deployment", Name: dep.Name}
+
+	for _, cond := range dep.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			rr.Code = readinessProgressDeadline
+			rr.Reason = cond.Message
+			return rr
+		}
+	}
+
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	if dep.Status.UpdatedReplicas < replicas || dep.Status.ObservedGeneration < dep.Generation {
+		rr.Code = readinessRolloutInProgress
+		rr.Reason = fmt.Sprintf("%d of %d replicas updated", dep.Status.UpdatedReplicas, replicas)
+		return rr
+	}
+	if dep.Status.AvailableReplicas < replicas {
+		rr.Code = readinessReplicasNotReady
+		rr.Reason = fmt.Sprintf("%d of %d replicas available", dep.Status.AvailableReplicas, replicas)
+		return rr
+	}
+
+	rr.Ready = true
+	rr.Code = readinessReady
+	rr.Reason = fmt.Sprintf("%d of %d replicas available", dep.Status.AvailableReplicas, replicas)
+	return rr
+}
+
+// daemonSetReadiness explains the readiness of a daemonset from the number
+// of nodes running a ready daemon pod
+func daemonSetReadiness(ds *appsv1.DaemonSet) v1alpha1.ResourceReadiness {
+	rr := v1alpha1.ResourceReadiness{Kind: "DaemonSet", Name: ds.Name}
+
+	desired := ds.Status.DesiredNumberScheduled
+	if ds.Status.CurrentNumberScheduled < desired || ds.Status.ObservedGeneration < ds.Generation {
+		rr.Code = readinessNotScheduled
+		rr.Reason = fmt.Sprintf("%d of %d nodes scheduled", ds.Status.CurrentNumberScheduled, desired)
+		return rr
+	}
+	if ds.Status.NumberReady < desired {
+		rr.Code = readinessReplicasNotReady
+		rr.Reason = fmt.Sprintf("%d of %d nodes ready", ds.Status.NumberReady, desired)
+		return rr
+	}
+
+	rr.Ready = true
+	rr.Code = readinessReady
+	rr.Reason = fmt.Sprintf("%d of %d nodes ready", ds.Status.NumberReady, desired)
+	return rr
+}
+
+// pvcReadiness explains the readiness of a persistent volume claim, it is
+// ready once bound to a volume
+func pvcReadiness(pvc *corev1.PersistentVolumeClaim) v1alpha1.ResourceReadiness {
+	rr := v1alpha1.ResourceReadiness{Kind: "PersistentVolumeClaim", Name: pvc.Name}
+
+	if pvc.Status.Phase != corev1.ClaimBound {
+		rr.Code = readinessClaimNotBound
+		rr.Reason = fmt.Sprintf("Claim is %s", pvc.Status.Phase)
+		return rr
+	}
+
+	rr.Ready = true
+	rr.Code = readinessReady
+	rr.Reason = "Claim is bound to " + pvc.Spec.VolumeName
+	return rr
+}
+
+// getEndpoints returns the Endpoints of a service, or nil if there are none
+func getEndpoints(cli client.Client, svc *corev1.Service) (*corev1.Endpoints, error) {
+	endpoints := &corev1.Endpoints{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}, endpoints)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// setReadiness adds or replaces the readiness entry of a resource
+func setReadiness(status *v1alpha1.ResourceBundleStatus, rr v1alpha1.ResourceReadiness) {
+	for i, r := range status.ReadinessReasons {
+		if r.Kind == rr.Kind && r.Name == rr.Name {
+			status.ReadinessReasons[i] = rr
+			return
+		}
+	}
+	status.ReadinessReasons = append(status.ReadinessReasons, rr)
+}
+
+// removeReadiness removes the readiness entry of a resource
+func removeReadiness(status *v1alpha1.ResourceBundleStatus, kind, name string) {
+	for i, r := range status.ReadinessReasons {
+		if r.Kind == kind && r.Name == name {
+			status.ReadinessReasons = append(status.ReadinessReasons[:i], status.ReadinessReasons[i+1:]...)
+			return
+		}
+	}
+}
+
+// countReadyPods sets the number of tracked pods and of the ready ones
+func countReadyPods(status *v1alpha1.ResourceBundleStatus) {
+	status.ReadyPods, status.TotalPods = 0, 0
+	for _, rr := range status.ReadinessReasons {
+		if rr.Kind != "Pod" {
+			continue
+		}
+		status.TotalPods++
+		if rr.Ready {
+			status.ReadyPods++
+		}
+	}
+}
+
+// updateReadyCondition sets the Ready condition of the bundle from the
+// readiness of its resources and the pod counts from the readiness of its
+// pods. The ready flag needs at least one pod, all of them ready, and the
+// condition true so that e.g. a pending claim keeps the bundle not ready.
+// The transition time only changes with the status of the condition.
+func updateReadyCondition(status *v1alpha1.ResourceBundleStatus) {
+	cond := v1alpha1.ResourceBundleCondition{
+		Type:   v1alpha1.ResourceBundleConditionReady,
+		Status: corev1.ConditionTrue,
+		Reason: readyConditionAllReady,
+	}
+
+	var notReady []string
+	for _, rr := range status.ReadinessReasons {
+		if rr.Ready {
+			continue
+		}
+		if cond.Status == corev1.ConditionTrue {
+			cond.Status = corev1.ConditionFalse
+			cond.Reason = rr.Kind + "sNotReady"
+		}
+		notReady = append(notReady, fmt.Sprintf("%s %s: %s", rr.Kind, rr.Name, rr.Reason))
+	}
+	if len(notReady) > 0 {
+		cond.Message = strings.Join(notReady, "; ")
+	} else {
+		cond.Message = fmt.Sprintf("%d resources ready", len(status.ReadinessReasons))
+	}
+	countReadyPods(status)
+	status.Ready = cond.Status == corev1.ConditionTrue &&
+		status.TotalPods > 0 && status.ReadyPods == status.TotalPods
+
+	for i, c := range status.Conditions {
+		if c.Type != cond.Type {
+			continue
+		}
+		cond.LastTransitionTime = c.LastTransitionTime
+		if c.Status != cond.Status {
+			cond.LastTransitionTime = metav1.Now()
+		}
+		status.Conditions[i] = cond
+		return
+	}
+	cond.LastTransitionTime = metav1.Now()
+	status.Conditions = append(status.Conditions, cond)
+}
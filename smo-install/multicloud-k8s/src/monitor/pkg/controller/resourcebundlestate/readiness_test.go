@@ -0,0 +1,98 @@
+package resourcebundlestate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateReadyCondition(t *testing.T) {
+	pod := func(name string, ready bool) v1alpha1.ResourceReadiness {
+		return v1alpha1.ResourceReadiness{Kind: "Pod", Name: name, Ready: ready, Reason: "test"}
+	}
+
+	testCases := []struct {
+		label     string
+		reasons   []v1alpha1.ResourceReadiness
+		readyPods int32
+		totalPods int32
+		ready     bool
+		condition corev1.ConditionStatus
+	}{
+		{
+			label:     "All pods ready",
+			reasons:   []v1alpha1.ResourceReadiness{pod("a", true), pod("b", true)},
+			readyPods: 2,
+			totalPods: 2,
+			ready:     true,
+			condition: corev1.ConditionTrue,
+		},
+		{
+			label:     "Mix of ready and not ready pods",
+			reasons:   []v1alpha1.ResourceReadiness{pod("a", true), pod("b", false), pod("c", true)},
+			readyPods: 2,
+			totalPods: 3,
+			condition: corev1.ConditionFalse,
+		},
+		{
+			label:     "No pods",
+			reasons:   []v1alpha1.ResourceReadiness{{Kind: "Service", Name: "web", Ready: true}},
+			condition: corev1.ConditionTrue,
+		},
+		{
+			label: "Ready pods but another resource not ready",
+			reasons: []v1alpha1.ResourceReadiness{
+				pod("a", true),
+				{Kind: "PersistentVolumeClaim", Name: "data", Reason: "Claim is Pending"},
+			},
+			readyPods: 1,
+			totalPods: 1,
+			condition: corev1.ConditionFalse,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			status := &v1alpha1.ResourceBundleStatus{ReadinessReasons: testCase.reasons}
+			updateReadyCondition(status)
+
+			if status.ReadyPods != testCase.readyPods || status.TotalPods != testCase.totalPods {
+				t.Fatalf("Expected %d of %d pods ready, got %d of %d",
+					testCase.readyPods, testCase.totalPods, status.ReadyPods, status.TotalPods)
+			}
+			if status.Ready != testCase.ready {
+				t.Fatalf("Expected ready %t, got %t", testCase.ready, status.Ready)
+			}
+			if len(status.Conditions) != 1 || status.Conditions[0].Status != testCase.condition {
+				t.Fatalf("Expected a Ready condition %s, got %+v", testCase.condition, status.Conditions)
+			}
+		})
+	}
+}
+
+func TestUpdateReadyConditionKeepsTransitionTime(t *testing.T) {
+	since := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	status := &v1alpha1.ResourceBundleStatus{
+		ReadinessReasons: []v1alpha1.ResourceReadiness{{Kind: "Pod", Name: "a", Ready: true}},
+		Conditions: []v1alpha1.ResourceBundleCondition{{
+			Type:               v1alpha1.ResourceBundleConditionReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: since,
+		}},
+	}
+
+	updateReadyCondition(status)
+	if !status.Conditions[0].LastTransitionTime.Equal(&since) {
+		t.Fatalf("Expected the transition time to be kept, got %v", status.Conditions[0].LastTransitionTime)
+	}
+
+	status.ReadinessReasons[0].Ready = false
+	updateReadyCondition(status)
+	if status.Conditions[0].Status != corev1.ConditionFalse || status.Conditions[0].LastTransitionTime.Equal(&since) {
+		t.Fatalf("Expected a new transition to False, got %+v", status.Conditions[0])
+	}
+}
@@ -0,0 +1,265 @@
+package resourcebundlestate
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/generated/clientset/versioned"
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// relistingSource feeds a controller from a list and watch of a resource.
+// When the watch fails because its resourceVersion expired, e.g. after a
+// long disconnect, the resources are listed again to get a fresh
+// resourceVersion and the ones created in the meantime are enqueued before
+// watching resumes, so that the reconciler doesn't miss them.
+type relistingSource struct {
+	lw cache.ListerWatcher
+	// retryDelay is the wait before retrying a failed list or watch
+	retryDelay time.Duration
+	stop       <-chan struct{}
+	// seen holds the resources already passed to the handler as created
+	seen map[types.UID]bool
+}
+
+// relistRetryDelayEnv overrides the default wait before retrying a failed
+// list or watch, e.g. "30s"
+const relistRetryDelayEnv = "RELIST_RETRY_DELAY"
+
+func relistRetryDelay() time.Duration {
+	if value, ok := os.LookupEnv(relistRetryDelayEnv); ok {
+		delay, err := time.ParseDuration(value)
+		if err == nil && delay > 0 {
+			return delay
+		}
+		log.Printf("Ignoring invalid %s %q\n", relistRetryDelayEnv, value)
+	}
+	return 5 * time.Second
+}
+
+// newResourceBundleStateListWatch lists and watches the ResourceBundleState
+// CRs of the namespace watched by the operator
+func newResourceBundleStateListWatch(mgr manager.Manager) (cache.ListerWatcher, error) {
+	clientset, err := versioned.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	// An unset namespace watches all namespaces
+	namespace, _ := k8sutil.GetWatchNamespace()
+
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return clientset.K8spluginV1alpha1().ResourceBundleStates(namespace).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return clientset.K8spluginV1alpha1().ResourceBundleStates(namespace).Watch(opts)
+		},
+	}, nil
+}
+
+func newRelistingSource(lw cache.ListerWatcher, retryDelay time.Duration) *relistingSource {
+	return &relistingSource{
+		lw:         lw,
+		retryDelay: retryDelay,
+		seen:       map[types.UID]bool{},
+	}
+}
+
+// InjectStopChannel is called by the controller before Start
+func (s *relistingSource) InjectStopChannel(stop <-chan struct{}) error {
+	s.stop = stop
+	return nil
+}
+
+// Start implements source.Source
+func (s *relistingSource) Start(h handler.EventHandler, q workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	go s.run(h, q, prct)
+	return nil
+}
+
+func (s *relistingSource) run(h handler.EventHandler, q workqueue.RateLimitingInterface,
+	prct []predicate.Predicate) {
+	resourceVersion := ""
+	for !s.stopped() {
+		if resourceVersion == "" {
+			rv, err := s.list(h, q, prct)
+			if err != nil {
+				log.Printf("Failed to list resources, retrying in %v: %v\n", s.retryDelay, err)
+				s.wait()
+				continue
+			}
+			resourceVersion = rv
+		}
+
+		w, err := s.lw.Watch(metav1.ListOptions{ResourceVersion: resourceVersion})
+		if err != nil {
+			if isExpired(err) {
+				log.Printf("Watch resourceVersion %s expired, listing again\n", resourceVersion)
+				resourceVersion = ""
+				continue
+			}
+			log.Printf("Failed to watch resources, retrying in %v: %v\n", s.retryDelay, err)
+			s.wait()
+			continue
+		}
+
+		resourceVersion = s.consume(w, resourceVersion, h, q, prct)
+		if resourceVersion == "" {
+			log.Printf("Watch resourceVersion expired, listing again\n")
+		}
+	}
+}
+
+// list enqueues the resources not seen yet and returns the resourceVersion
+// to watch from
+func (s *relistingSource) list(h handler.EventHandler, q workqueue.RateLimitingInterface,
+	prct []predicate.Predicate) (string, error) {
+	list, err := s.lw.List(metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return "", err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return "", err
+	}
+
+	// Forget the resources deleted while the watch was down
+	seen := make(map[types.UID]bool, len(items))
+	for _, item := range items {
+		objMeta, err := meta.Accessor(item)
+		if err != nil {
+			return "", err
+		}
+		seen[objMeta.GetUID()] = true
+		if !s.seen[objMeta.GetUID()] {
+			s.created(objMeta, item, h, q, prct)
+		}
+	}
+	s.seen = seen
+
+	return listMeta.GetResourceVersion(), nil
+}
+
+// consume passes the watch events to the handler until the watch ends and
+// returns the resourceVersion to resume from, or an empty string if it
+// expired
+func (s *relistingSource) consume(w watch.Interface, resourceVersion string,
+	h handler.EventHandler, q workqueue.RateLimitingInterface, prct []predicate.Predicate) string {
+	defer w.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return resourceVersion
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				// The apiserver closed the watch, resume from the last event
+				return resourceVersion
+			}
+			if e.Type == watch.Error {
+				err := k8serrors.FromObject(e.Object)
+				if isExpired(err) {
+					return ""
+				}
+				log.Printf("Watch error, resuming in %v: %v\n", s.retryDelay, err)
+				s.wait()
+				return resourceVersion
+			}
+
+			objMeta, err := meta.Accessor(e.Object)
+			if err != nil {
+				log.Printf("Ignoring watch event of unexpected object %T\n", e.Object)
+				continue
+			}
+			resourceVersion = objMeta.GetResourceVersion()
+
+			switch e.Type {
+			case watch.Added:
+				if !s.seen[objMeta.GetUID()] {
+					s.seen[objMeta.GetUID()] = true
+					s.created(objMeta, e.Object, h, q, prct)
+				}
+			case watch.Modified:
+				s.updated(objMeta, e.Object, h, q, prct)
+			case watch.Deleted:
+				delete(s.seen, objMeta.GetUID())
+				s.deleted(objMeta, e.Object, h, q, prct)
+			}
+		}
+	}
+}
+
+func (s *relistingSource) created(objMeta metav1.Object, obj runtime.Object,
+	h handler.EventHandler, q workqueue.RateLimitingInterface, prct []predicate.Predicate) {
+	evt := event.CreateEvent{Meta: objMeta, Object: obj}
+	for _, p := range prct {
+		if !p.Create(evt) {
+			return
+		}
+	}
+	h.Create(evt, q)
+}
+
+// updated only has the new state of the object, a watch doesn't carry the
+// previous one
+func (s *relistingSource) updated(objMeta metav1.Object, obj runtime.Object,
+	h handler.EventHandler, q workqueue.RateLimitingInterface, prct []predicate.Predicate) {
+	evt := event.UpdateEvent{MetaOld: objMeta, ObjectOld: obj, MetaNew: objMeta, ObjectNew: obj}
+	for _, p := range prct {
+		if !p.Update(evt) {
+			return
+		}
+	}
+	h.Update(evt, q)
+}
+
+func (s *relistingSource) deleted(objMeta metav1.Object, obj runtime.Object,
+	h handler.EventHandler, q workqueue.RateLimitingInterface, prct []predicate.Predicate) {
+	evt := event.DeleteEvent{Meta: objMeta, Object: obj}
+	for _, p := range prct {
+		if !p.Delete(evt) {
+			return
+		}
+	}
+	h.Delete(evt, q)
+}
+
+func (s *relistingSource) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *relistingSource) wait() {
+	select {
+	case <-s.stop:
+	case <-time.After(s.retryDelay):
+	}
+}
+
+// isExpired tells if err reports a resourceVersion too old to watch from
+func isExpired(err error) bool {
+	return k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err)
+}
@@ -0,0 +1,134 @@
+package resourcebundlestate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeBundleAPI serves the lists and watches of the ResourceBundleStates
+// to a relistingSource
+type fakeBundleAPI struct {
+	mu      sync.Mutex
+	bundles []v1alpha1.ResourceBundleState
+	lists   int
+	watches int
+	// firstWatch answers the first watch, the next ones never send events
+	firstWatch func() (watch.Interface, error)
+}
+
+func (f *fakeBundleAPI) listWatch() cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.lists++
+			list := &v1alpha1.ResourceBundleStateList{Items: append([]v1alpha1.ResourceBundleState{}, f.bundles...)}
+			list.ResourceVersion = "10"
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			f.mu.Lock()
+			f.watches++
+			first := f.watches == 1
+			f.mu.Unlock()
+			if first {
+				return f.firstWatch()
+			}
+			return watch.NewFake(), nil
+		},
+	}
+}
+
+func (f *fakeBundleAPI) add(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bundles = append(f.bundles, v1alpha1.ResourceBundleState{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(name)},
+	})
+}
+
+func (f *fakeBundleAPI) listCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lists
+}
+
+func TestRelistingSourceExpiredWatch(t *testing.T) {
+	testCases := []struct {
+		label      string
+		firstWatch func(api *fakeBundleAPI) (watch.Interface, error)
+	}{
+		{
+			label: "Watch call fails with an expired resourceVersion",
+			firstWatch: func(api *fakeBundleAPI) (watch.Interface, error) {
+				api.add("created-while-down")
+				return nil, k8serrors.NewResourceExpired("too old resource version: 10 (20)")
+			},
+		},
+		{
+			label: "Watch ends with a Gone error event",
+			firstWatch: func(api *fakeBundleAPI) (watch.Interface, error) {
+				api.add("created-while-down")
+				w := watch.NewFakeWithChanSize(1, false)
+				status := k8serrors.NewGone("too old resource version: 10 (20)").ErrStatus
+				w.Error(&status)
+				return w, nil
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			api := &fakeBundleAPI{}
+			api.add("existing")
+			api.firstWatch = func() (watch.Interface, error) { return testCase.firstWatch(api) }
+
+			stop := make(chan struct{})
+			defer close(stop)
+			queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer queue.ShutDown()
+
+			src := newRelistingSource(api.listWatch(), time.Millisecond)
+			if err := src.InjectStopChannel(stop); err != nil {
+				t.Fatalf("InjectStopChannel returned an error (%s)", err)
+			}
+			if err := src.Start(&handler.EnqueueRequestForObject{}, queue); err != nil {
+				t.Fatalf("Start returned an error (%s)", err)
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for queue.Len() < 2 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if lists := api.listCount(); lists != 2 {
+				t.Fatalf("Expected the resources to be listed again once, got %d lists", lists)
+			}
+			if queue.Len() != 2 {
+				t.Fatalf("Expected 2 enqueued bundles, got %d", queue.Len())
+			}
+			enqueued := map[string]bool{}
+			for queue.Len() > 0 {
+				item, _ := queue.Get()
+				enqueued[item.(reconcile.Request).Name] = true
+				queue.Done(item)
+			}
+			if !enqueued["existing"] || !enqueued["created-while-down"] {
+				t.Fatalf("Expected both bundles to be enqueued, got %v", enqueued)
+			}
+		})
+	}
+}
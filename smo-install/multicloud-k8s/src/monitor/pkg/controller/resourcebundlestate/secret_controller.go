@@ -24,7 +24,7 @@ func AddSecretController(mgr manager.Manager) error {
 
 func addSecretController(mgr manager.Manager, r *secretReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Secret-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Secret-controller", mgr, controller.Options{Reconciler: instrument("Secret-controller", r)})
 	if err != nil {
 		return err
 	}
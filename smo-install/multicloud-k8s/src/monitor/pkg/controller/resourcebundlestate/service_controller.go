@@ -24,7 +24,7 @@ func AddServiceController(mgr manager.Manager) error {
 
 func addServiceController(mgr manager.Manager, r *serviceReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Service-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Service-controller", mgr, controller.Options{Reconciler: instrument("Service-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -36,6 +36,14 @@ func addServiceController(mgr manager.Manager, r *serviceReconciler) error {
 		return err
 	}
 
+	// Watch for changes to the Endpoints of the Services, whose readiness
+	// depends on them. Endpoints are named after their Service, so the
+	// request of the Endpoints reconciles the Service.
+	err = c.Watch(&source.Kind{Type: &corev1.Endpoints{}}, &handler.EnqueueRequestForObject{}, &endpointsPredicate{})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -131,6 +139,8 @@ func (r *serviceReconciler) updateCRs(crl *v1alpha1.ResourceBundleStateList, svc
 
 func (r *serviceReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState, name string) error {
 	cr.Status.ResourceCount--
+	removeReadiness(&cr.Status, "Service", name)
+	updateReadyCondition(&cr.Status)
 	length := len(cr.Status.ServiceStatuses)
 	for i, rstatus := range cr.Status.ServiceStatuses {
 		if rstatus.Name == name {
@@ -148,6 +158,14 @@ func (r *serviceReconciler) deleteFromSingleCR(cr *v1alpha1.ResourceBundleState,
 
 func (r *serviceReconciler) updateSingleCR(cr *v1alpha1.ResourceBundleState, svc *corev1.Service) error {
 
+	endpoints, err := getEndpoints(r.client, svc)
+	if err != nil {
+		log.Printf("Failed to get endpoints: %v", err)
+		return err
+	}
+	setReadiness(&cr.Status, serviceReadiness(svc, endpoints))
+	updateReadyCondition(&cr.Status)
+
 	// Update status after searching for it in the list of resourceStatuses
 	for i, rstatus := range cr.Status.ServiceStatuses {
 		// Look for the status if we already have it in the CR
@@ -173,7 +191,7 @@ func (r *serviceReconciler) updateSingleCR(cr *v1alpha1.ResourceBundleState, svc
 		Status:     svc.Status,
 	})
 
-	err := r.client.Status().Update(context.TODO(), cr)
+	err = r.client.Status().Update(context.TODO(), cr)
 	if err != nil {
 		log.Printf("failed to update rbstate: %v\n", err)
 		return err
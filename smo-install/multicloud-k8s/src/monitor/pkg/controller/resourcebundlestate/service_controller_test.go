@@ -0,0 +1,69 @@
+package resourcebundlestate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestServiceReadyOnceEndpointsAreReady(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: testMeta("web"),
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+	}
+	cli := newTestClient(t, svc)
+	r := &serviceReconciler{client: cli}
+
+	readinessOf := func(req reconcile.Request) v1alpha1.ResourceReadiness {
+		if _, err := r.Reconcile(req); err != nil {
+			t.Fatalf("Reconcile returned an error (%s)", err)
+		}
+		bundle := &v1alpha1.ResourceBundleState{}
+		key := types.NamespacedName{Namespace: testNamespace, Name: "bundle"}
+		if err := cli.Get(context.TODO(), key, bundle); err != nil {
+			t.Fatalf("Unable to get the reconciled bundle (%s)", err)
+		}
+		return findReadiness(t, bundle.Status, "Service", "web")
+	}
+
+	key := types.NamespacedName{Namespace: testNamespace, Name: "web"}
+	if rr := readinessOf(reconcile.Request{NamespacedName: key}); rr.Code != readinessNoEndpoints {
+		t.Fatalf("Expected code %s before the endpoints are ready, got %+v", readinessNoEndpoints, rr)
+	}
+
+	// The endpoints controller fills the Endpoints once the pods are ready
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: testMeta("web"),
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.7"}},
+		}},
+	}
+	if err := cli.Create(context.TODO(), endpoints); err != nil {
+		t.Fatalf("Unable to create the endpoints (%s)", err)
+	}
+
+	evt := event.CreateEvent{Meta: endpoints, Object: endpoints}
+	if !(&endpointsPredicate{}).Create(evt) {
+		t.Fatalf("Expected the Endpoints of a tracked Service to be watched")
+	}
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	(&handler.EnqueueRequestForObject{}).Create(evt, queue)
+	if queue.Len() != 1 {
+		t.Fatalf("Expected one request for the Endpoints, got %d", queue.Len())
+	}
+	item, _ := queue.Get()
+
+	rr := readinessOf(item.(reconcile.Request))
+	if rr.Code != readinessReady || !rr.Ready {
+		t.Fatalf("Expected the service to be ready once its endpoints are, got %+v", rr)
+	}
+}
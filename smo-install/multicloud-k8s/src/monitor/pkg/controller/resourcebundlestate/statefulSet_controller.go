@@ -24,7 +24,7 @@ func AddStatefulSetController(mgr manager.Manager) error {
 
 func addStatefulSetController(mgr manager.Manager, r *statefulSetReconciler) error {
 	// Create a new controller
-	c, err := controller.New("Statefulset-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("Statefulset-controller", mgr, controller.Options{Reconciler: instrument("Statefulset-controller", r)})
 	if err != nil {
 		return err
 	}
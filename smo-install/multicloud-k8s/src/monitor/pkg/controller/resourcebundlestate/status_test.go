@@ -0,0 +1,127 @@
+package resourcebundlestate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onap/multicloud-k8s/src/monitor/pkg/apis/k8splugin/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// statusSubresourceClient behaves like the apiserver for a CRD with the
+// status subresource: a status update only writes the status of the stored
+// bundle and keeps its spec, while a plain update of a bundle is counted
+type statusSubresourceClient struct {
+	client.Client
+	bundleUpdates int
+	statusUpdates int
+	// beforeStatusUpdate edits the stored bundle before each status update,
+	// like a concurrent writer of the spec
+	beforeStatusUpdate func(stored *v1alpha1.ResourceBundleState)
+}
+
+func (c *statusSubresourceClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*v1alpha1.ResourceBundleState); ok {
+		c.bundleUpdates++
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *statusSubresourceClient) Status() client.StatusWriter {
+	return statusWriter{c}
+}
+
+type statusWriter struct {
+	c *statusSubresourceClient
+}
+
+func (w statusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	bundle, ok := obj.(*v1alpha1.ResourceBundleState)
+	if !ok {
+		return errors.New("status update of an unexpected object")
+	}
+	w.c.statusUpdates++
+
+	stored := &v1alpha1.ResourceBundleState{}
+	key := types.NamespacedName{Namespace: bundle.Namespace, Name: bundle.Name}
+	if err := w.c.Client.Get(ctx, key, stored); err != nil {
+		return err
+	}
+	if w.c.beforeStatusUpdate != nil {
+		w.c.beforeStatusUpdate(stored)
+	}
+	bundle.Status.DeepCopyInto(&stored.Status)
+	return w.c.Client.Update(ctx, stored, opts...)
+}
+
+func (w statusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return errors.New("status patch not supported by the test client")
+}
+
+func TestReconcileWritesStatusSubresource(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: testMeta("web-0"),
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	cli := &statusSubresourceClient{
+		Client: newTestClient(t, pod),
+		beforeStatusUpdate: func(stored *v1alpha1.ResourceBundleState) {
+			stored.Spec.Selector.MatchLabels["tier"] = "edited"
+		},
+	}
+	key := types.NamespacedName{Namespace: testNamespace, Name: "bundle"}
+
+	testCases := []struct {
+		label     string
+		reconcile func() (reconcile.Result, error)
+	}{
+		{
+			label: "Bundle reconciler",
+			reconcile: func() (reconcile.Result, error) {
+				return (&reconciler{client: cli}).Reconcile(reconcile.Request{NamespacedName: key})
+			},
+		},
+		{
+			label: "Pod reconciler",
+			reconcile: func() (reconcile.Result, error) {
+				podKey := types.NamespacedName{Namespace: testNamespace, Name: pod.Name}
+				return (&podReconciler{client: cli}).Reconcile(reconcile.Request{NamespacedName: podKey})
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			cli.bundleUpdates, cli.statusUpdates = 0, 0
+			if _, err := testCase.reconcile(); err != nil {
+				t.Fatalf("Reconcile returned an error (%s)", err)
+			}
+			if cli.bundleUpdates != 0 {
+				t.Fatalf("Expected no update of the whole bundle, got %d", cli.bundleUpdates)
+			}
+			if cli.statusUpdates == 0 {
+				t.Fatalf("Expected the status to be written through the status subresource")
+			}
+
+			bundle := &v1alpha1.ResourceBundleState{}
+			if err := cli.Get(context.TODO(), key, bundle); err != nil {
+				t.Fatalf("Unable to get the reconciled bundle (%s)", err)
+			}
+			if bundle.Spec.Selector.MatchLabels["tier"] != "edited" {
+				t.Fatalf("Expected the concurrent spec edit to be kept, got %v", bundle.Spec.Selector.MatchLabels)
+			}
+			if len(bundle.Status.PodStatuses) != 1 || bundle.Status.PodStatuses[0].Name != pod.Name {
+				t.Fatalf("Expected the status of %s to be written, got %+v", pod.Name, bundle.Status.PodStatuses)
+			}
+		})
+	}
+}